@@ -0,0 +1,251 @@
+package aggsig
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+Adaptor signatures (scriptless scripts) lock a signature to the discrete
+logarithm t of an adaptor point T = t*G.
+
+A pre-signature is made with the nonce point R, but commits to R + T:
+r = x(R + T) mod q and s' = r*d + k*e mod q. It cannot be verified as a
+signature, yet anybody knowing R and T can check that s'*G = r*Q + e*R. Adapt
+completes it to the signature (s' + t*e, r), whose nonce point is R + T, and
+Extract recovers t = (s - s')/e from the pre-signature and the signature.
+
+With WithKeyAggregation or WithSigners the co-signers pre-sign with their nonces
+and the sum of the nonce points, and AggregatePreSignatures adds up the partial
+pre-signatures, just like SignPartial and AggregatePartialSignatures.
+*/
+
+/*
+AdaptorNonce returns R + T, the nonce point of the adapted signature for the sum
+of the nonce points sumNonces and the adaptor point.
+*/
+func AdaptorNonce(context *gost3410.Context, sumNonces *PublicKey, adaptor *PublicKey) (nonce *PublicKey, err error) {
+	if adaptor == nil || adaptor.IsZero() || !adaptor.IsOnCurve(context.Curve) {
+		err = errors.New("invalid adaptor point")
+		return
+	}
+	point := new(curve.Point).Add(context.Curve, sumNonces.Point, adaptor.Point)
+	if point.IsZero() {
+		err = errors.New("adaptor point cancels the nonce")
+		return
+	}
+	return &PublicKey{point}, nil
+}
+
+/*
+PreSign creates the (partial) pre-signature of msg encrypted under adaptor. nonce
+is the nonce of the signer and sumNonces the sum of the nonce points of all
+co-signers, or the nonce point itself for a single signer. The options are those
+of SignPartial, except WithEvenNonce.
+*/
+func PreSign(
+	context *gost3410.Context,
+	rawPrivateKey []byte,
+	nonce []byte,
+	sumNonces *PublicKey,
+	adaptor *PublicKey,
+	msg []byte,
+	opts ...Option,
+) (
+	preSignature []byte,
+	err error,
+) {
+	if applyOptions(opts).evenNonce {
+		err = errors.New("WithEvenNonce cannot be used with adaptor signatures")
+		return
+	}
+	adaptorNonce, err := AdaptorNonce(context, sumNonces, adaptor)
+	if err != nil {
+		err = errors.Wrap(err, "cannot AdaptorNonce")
+		return
+	}
+	return SignPartial(context, rawPrivateKey, nonce, adaptorNonce, msg, opts...)
+}
+
+/*
+AggregatePreSignatures adds up the partial pre-signatures of all co-signers.
+*/
+func AggregatePreSignatures(context *gost3410.Context, rawPartialPreSignatures [][]byte, sumNonces *PublicKey, adaptor *PublicKey) (preSignature []byte, err error) {
+	adaptorNonce, err := AdaptorNonce(context, sumNonces, adaptor)
+	if err != nil {
+		err = errors.Wrap(err, "cannot AdaptorNonce")
+		return
+	}
+	return AggregatePartialSignatures(context, rawPartialPreSignatures, adaptorNonce)
+}
+
+/*
+VerifyPreSignature verifies a pre-signature of msg under publicKey, made with the
+nonce point sumNonces and encrypted under adaptor. Once it holds, adapting it
+with the discrete logarithm of adaptor gives a valid signature. With
+WithKeyAggregation the pre-signature is checked against the aggregated public
+key, as in Verify.
+*/
+func VerifyPreSignature(
+	context *gost3410.Context,
+	preSignature []byte,
+	publicKey *PublicKey,
+	sumNonces *PublicKey,
+	adaptor *PublicKey,
+	msg []byte,
+	opts ...Option,
+) (
+	correct bool,
+	err error,
+) {
+	if o := applyOptions(opts); o.keyAggregation != nil {
+		aggregated := o.keyAggregation.PublicKey
+		if publicKey != nil && !bytes.Equal(publicKey.Bytes(context.Curve), aggregated.Bytes(context.Curve)) {
+			err = errors.New("public key does not match the key aggregation")
+			return
+		}
+		publicKey = aggregated
+	}
+	return verifyPreSignature(context, preSignature, publicKey, sumNonces, sumNonces, adaptor, msg)
+}
+
+/*
+VerifyPartialPreSignature verifies the partial pre-signature of the co-signer
+with publicKey and publicNonce. With WithKeyAggregation or WithSigners publicKey
+is weighted as in VerifyPartial.
+*/
+func VerifyPartialPreSignature(
+	context *gost3410.Context,
+	preSignature []byte,
+	publicKey *PublicKey,
+	publicNonce *PublicKey,
+	sumNonces *PublicKey,
+	adaptor *PublicKey,
+	msg []byte,
+	opts ...Option,
+) (
+	correct bool,
+	err error,
+) {
+	if o := applyOptions(opts); o.weighted() {
+		a, err := o.coefficient(context, publicKey)
+		if err != nil {
+			return false, errors.Wrap(err, "cannot coefficient")
+		}
+		publicKey = &PublicKey{new(curve.Point).ScalarMult(context.Curve, publicKey.Point, a)}
+	}
+	return verifyPreSignature(context, preSignature, publicKey, publicNonce, sumNonces, adaptor, msg)
+}
+
+func verifyPreSignature(
+	context *gost3410.Context,
+	preSignature []byte,
+	publicKey *PublicKey,
+	publicNonce *PublicKey,
+	sumNonces *PublicKey,
+	adaptor *PublicKey,
+	msg []byte,
+) (
+	correct bool,
+	err error,
+) {
+	mode := context.Curve.Params().BitSize / 8
+	q := context.Curve.Params().N
+
+	if len(preSignature) != 2*mode {
+		err = errors.New("wrong pre-signature length")
+		return
+	}
+	adaptorNonce, err := AdaptorNonce(context, sumNonces, adaptor)
+	if err != nil {
+		err = errors.Wrap(err, "cannot AdaptorNonce")
+		return
+	}
+
+	// r = x(R + T) mod q, s > 0, s < q
+	s := utils.BytesToBigInt(preSignature[:mode])
+	r := utils.BytesToBigInt(preSignature[mode:])
+	if r.Cmp(new(big.Int).Mod(adaptorNonce.X, q)) != 0 || r.Sign() == 0 || s.Sign() <= 0 || s.Cmp(q) >= 0 {
+		return false, nil
+	}
+
+	// s*G - r*Q must be e*R, the point itself and not only its x coordinate, as
+	// -R + T would give another r
+	e := hash.HashToInt(msg, context.HashAlgorithm, context.Curve)
+	left := new(curve.Point).Sub(context.Curve,
+		new(curve.Point).ScalarBaseMult(context.Curve, s),
+		new(curve.Point).ScalarMult(context.Curve, publicKey.Point, r),
+	)
+	right := new(curve.Point).ScalarMult(context.Curve, publicNonce.Point, e)
+	return left.Equal(right), nil
+}
+
+/*
+Adapt completes a pre-signature of msg with the secret adaptor, the discrete
+logarithm of the adaptor point, into a signature.
+*/
+func Adapt(context *gost3410.Context, preSignature []byte, adaptor []byte, msg []byte) (signature []byte, err error) {
+	mode := context.Curve.Params().BitSize / 8
+	q := context.Curve.Params().N
+	if len(preSignature) != 2*mode {
+		err = errors.New("wrong pre-signature length")
+		return
+	}
+	t, err := NewPrivateKey(context, adaptor)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPrivateKey")
+		return
+	}
+
+	// s = s' + t*e mod q
+	e := hash.HashToInt(msg, context.HashAlgorithm, context.Curve)
+	s := new(big.Int).Mul(t.Int, e)
+	s.Add(s, utils.BytesToBigInt(preSignature[:mode]))
+	s.Mod(s, q)
+	if s.Sign() == 0 {
+		err = errors.New("failed to adapt pre-signature: s is zero")
+		return
+	}
+
+	return append(
+		utils.Pad(s.Bytes(), mode),
+		preSignature[mode:]...,
+	), nil
+}
+
+/*
+Extract recovers the secret adaptor from a pre-signature of msg and the signature
+it was adapted to. It fails unless the result is the discrete logarithm of the
+adaptor point.
+*/
+func Extract(context *gost3410.Context, preSignature []byte, signature []byte, adaptor *PublicKey, msg []byte) (secret []byte, err error) {
+	mode := context.Curve.Params().BitSize / 8
+	q := context.Curve.Params().N
+	if len(preSignature) != 2*mode || len(signature) != 2*mode {
+		err = errors.New("wrong signature length")
+		return
+	}
+	if !bytes.Equal(preSignature[mode:], signature[mode:]) {
+		err = errors.New("signature is not adapted from the pre-signature")
+		return
+	}
+
+	// t = (s - s') / e mod q
+	e := hash.HashToInt(msg, context.HashAlgorithm, context.Curve)
+	t := new(big.Int).Sub(utils.BytesToBigInt(signature[:mode]), utils.BytesToBigInt(preSignature[:mode]))
+	t.Mul(t, new(big.Int).ModInverse(e, q))
+	t.Mod(t, q)
+
+	point := new(curve.Point).ScalarBaseMult(context.Curve, t)
+	if t.Sign() == 0 || !point.Equal(adaptor.Point) {
+		err = errors.New("signature does not reveal the adaptor")
+		return
+	}
+	return utils.Pad(t.Bytes(), mode), nil
+}
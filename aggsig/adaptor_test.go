@@ -0,0 +1,168 @@
+package aggsig
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptor(t *testing.T) {
+	for _, c := range []*curve.CurveParams{curve.GOST34102012256A, curve.GOST34102012512A} {
+		ha := gost3410.HashAlgorithm(hash.GOST34112012256)
+		if c.BitSize == 512 {
+			ha = hash.GOST34112012512
+		}
+		t.Run(c.Name, func(t *testing.T) {
+			context := gost3410.NewContext(c, ha)
+			mode := c.BitSize / 8
+			msg := []byte("Hello world!")
+
+			privateKey := utils.RandomBytes(mode)
+			publicKey, err := NewPublicKey(context, privateKey)
+			require.NoError(t, err)
+			nonce := utils.RandomBytes(mode)
+			publicNonce, err := NewPublicKey(context, nonce)
+			require.NoError(t, err)
+			secret := utils.RandomBytes(mode)
+			adaptor, err := NewPublicKey(context, secret)
+			require.NoError(t, err)
+
+			preSignature, err := PreSign(context, privateKey, nonce, publicNonce, adaptor, msg)
+			require.NoError(t, err)
+			correct, err := VerifyPreSignature(context, preSignature, publicKey, publicNonce, adaptor, msg)
+			require.NoError(t, err)
+			assert.True(t, correct)
+
+			// a pre-signature is not a signature
+			correct, err = Verify(context, preSignature, publicKey, msg)
+			require.NoError(t, err)
+			assert.False(t, correct)
+
+			signature, err := Adapt(context, preSignature, secret, msg)
+			require.NoError(t, err)
+			correct, err = Verify(context, signature, publicKey, msg)
+			require.NoError(t, err)
+			assert.True(t, correct)
+
+			extracted, err := Extract(context, preSignature, signature, adaptor, msg)
+			require.NoError(t, err)
+			assert.Equal(t, new(big.Int).Mod(utils.BytesToBigInt(secret), context.Curve.Params().N), utils.BytesToBigInt(extracted))
+		})
+	}
+}
+
+func TestAdaptorAggregated(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	msg := []byte("Hello world!")
+	n := 3
+
+	privateKeys := make([][]byte, n)
+	publicKeys := make([]*PublicKey, n)
+	nonces := make([][]byte, n)
+	publicNonces := make([]*PublicKey, n)
+	for i := 0; i < n; i++ {
+		var err error
+		privateKeys[i] = utils.RandomBytes(mode)
+		publicKeys[i], err = NewPublicKey(context, privateKeys[i])
+		require.NoError(t, err)
+		nonces[i] = utils.RandomBytes(mode)
+		publicNonces[i], err = NewPublicKey(context, nonces[i])
+		require.NoError(t, err)
+	}
+	keyAggregation, err := AggregatePublicKeys(context, publicKeys)
+	require.NoError(t, err)
+	sumNonces, err := SumPublicKeys(context, publicNonces)
+	require.NoError(t, err)
+	secret := utils.RandomBytes(mode)
+	adaptor, err := NewPublicKey(context, secret)
+	require.NoError(t, err)
+
+	partials := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		partials[i], err = PreSign(context, privateKeys[i], nonces[i], sumNonces, adaptor, msg, WithKeyAggregation(keyAggregation))
+		require.NoError(t, err)
+		correct, err := VerifyPartialPreSignature(context, partials[i], publicKeys[i], publicNonces[i], sumNonces, adaptor, msg, WithKeyAggregation(keyAggregation))
+		require.NoError(t, err)
+		assert.True(t, correct)
+
+		// the partial pre-signature is bound to the co-signer's nonce point
+		correct, err = VerifyPartialPreSignature(context, partials[i], publicKeys[i], publicNonces[(i+1)%n], sumNonces, adaptor, msg, WithKeyAggregation(keyAggregation))
+		require.NoError(t, err)
+		assert.False(t, correct)
+	}
+
+	preSignature, err := AggregatePreSignatures(context, partials, sumNonces, adaptor)
+	require.NoError(t, err)
+	correct, err := VerifyPreSignature(context, preSignature, nil, sumNonces, adaptor, msg, WithKeyAggregation(keyAggregation))
+	require.NoError(t, err)
+	assert.True(t, correct)
+
+	signature, err := Adapt(context, preSignature, secret, msg)
+	require.NoError(t, err)
+	correct, err = Verify(context, signature, nil, msg, WithKeyAggregation(keyAggregation))
+	require.NoError(t, err)
+	assert.True(t, correct)
+
+	extracted, err := Extract(context, preSignature, signature, adaptor, msg)
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Mod(utils.BytesToBigInt(secret), context.Curve.Params().N), utils.BytesToBigInt(extracted))
+}
+
+func TestAdaptorErrors(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	msg := []byte("Hello world!")
+
+	privateKey := utils.RandomBytes(mode)
+	publicKey, err := NewPublicKey(context, privateKey)
+	require.NoError(t, err)
+	nonce := utils.RandomBytes(mode)
+	publicNonce, err := NewPublicKey(context, nonce)
+	require.NoError(t, err)
+	secret := utils.RandomBytes(mode)
+	adaptor, err := NewPublicKey(context, secret)
+	require.NoError(t, err)
+	other, err := NewPublicKey(context, utils.RandomBytes(mode))
+	require.NoError(t, err)
+
+	_, err = PreSign(context, privateKey, nonce, publicNonce, adaptor, msg, WithEvenNonce())
+	assert.Error(t, err)
+	_, err = PreSign(context, privateKey, nonce, publicNonce, &PublicKey{new(curve.Point).SetInfinity()}, msg)
+	assert.Error(t, err)
+
+	preSignature, err := PreSign(context, privateKey, nonce, publicNonce, adaptor, msg)
+	require.NoError(t, err)
+
+	// wrong adaptor point, message or key
+	correct, err := VerifyPreSignature(context, preSignature, publicKey, publicNonce, other, msg)
+	require.NoError(t, err)
+	assert.False(t, correct)
+	correct, err = VerifyPreSignature(context, preSignature, publicKey, publicNonce, adaptor, []byte("Hello world?"))
+	require.NoError(t, err)
+	assert.False(t, correct)
+	correct, err = VerifyPreSignature(context, preSignature, other, publicNonce, adaptor, msg)
+	require.NoError(t, err)
+	assert.False(t, correct)
+
+	// adapting with the wrong secret gives an invalid signature that reveals nothing
+	signature, err := Adapt(context, preSignature, utils.RandomBytes(mode), msg)
+	require.NoError(t, err)
+	correct, err = Verify(context, signature, publicKey, msg)
+	require.NoError(t, err)
+	assert.False(t, correct)
+	_, err = Extract(context, preSignature, signature, adaptor, msg)
+	assert.Error(t, err)
+
+	// a signature of another pre-signature
+	unrelated, err := PreSign(context, privateKey, utils.RandomBytes(mode), publicNonce, adaptor, msg)
+	require.NoError(t, err)
+	_, err = Extract(context, unrelated[:mode], unrelated, adaptor, msg)
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package aggsig
 
 import (
+	"bytes"
 	"math/big"
 
 	"github.com/AllFi/go-gost3410"
@@ -14,12 +15,19 @@ var (
 	zero = big.NewInt(0)
 )
 
+/*
+SignPartial creates the partial signature of one co-signer. With
+WithKeyAggregation or WithSigners the private key is weighted by its key
+aggregation or Lagrange coefficient, and the partial signatures add up to a
+signature under the aggregated or group public key.
+*/
 func SignPartial(
 	context *gost3410.Context,
 	rawPrivateKey []byte,
 	nonce []byte,
 	sumNonces *PublicKey,
 	msg []byte,
+	opts ...Option,
 ) (
 	signature []byte,
 	err error,
@@ -30,11 +38,29 @@ func SignPartial(
 		return
 	}
 
+	q := context.Curve.Params().N
 	d := privateKey.Int
+	o := applyOptions(opts)
+	if o.weighted() {
+		publicKey, err := privateKey.PublicKey(context)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot PublicKey")
+		}
+		a, err := o.coefficient(context, publicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot coefficient")
+		}
+		// d = a*d mod q
+		d = a.Mul(a, d)
+		d.Mod(d, q)
+	}
 	k := big.NewInt(0).SetBytes(nonce)
-	r := sumNonces.X
+	if o.evenNonce && sumNonces.Y.Bit(0) == 1 {
+		// -k*P = -R has the same x coordinate and an even y coordinate
+		k.Sub(q, k.Mod(k, q))
+	}
+	r := big.NewInt(0).Mod(sumNonces.X, q)
 	e := hash.HashToInt(msg, context.HashAlgorithm, context.Curve)
-	q := context.Curve.Params().N
 	mode := context.Curve.Params().BitSize / 8
 
 	// s = d*r + k*e mod q, where d - privateKey, k - nonce, r - x coordinate of sumNonces, e - message digest
@@ -53,9 +79,8 @@ func SignPartial(
 
 func AggregatePartialSignatures(context *gost3410.Context, rawPartialSignatures [][]byte, sumNonces *PublicKey) (signature []byte, err error) {
 	mode := context.Curve.Params().BitSize / 8
-	r := sumNonces.X
 	q := context.Curve.Params().N
-	r.Mod(r, q)
+	r := big.NewInt(0).Mod(sumNonces.X, q)
 
 	s := new(big.Int)
 	for i := 0; i < len(rawPartialSignatures); i++ {
@@ -70,17 +95,56 @@ func AggregatePartialSignatures(context *gost3410.Context, rawPartialSignatures
 	), nil
 }
 
-func Verify(context *gost3410.Context, signature []byte, publicKey *PublicKey, msg []byte) (correct bool, err error) {
-	return verify(context, signature, publicKey, msg, nil)
+/*
+Verify verifies a signature of msg. With WithKeyAggregation the signature is
+checked against the aggregated public key, and publicKey, if not nil, must be
+equal to it.
+*/
+func Verify(context *gost3410.Context, signature []byte, publicKey *PublicKey, msg []byte, opts ...Option) (correct bool, err error) {
+	if o := applyOptions(opts); o.keyAggregation != nil {
+		aggregated := o.keyAggregation.PublicKey
+		if publicKey != nil && !bytes.Equal(publicKey.Bytes(context.Curve), aggregated.Bytes(context.Curve)) {
+			err = errors.New("public key does not match the key aggregation")
+			return
+		}
+		publicKey = aggregated
+	}
+	e := hash.HashToInt(msg, context.HashAlgorithm, context.Curve)
+	return verify(context, signature, publicKey, e, nil)
 }
 
-func VerifyPartial(context *gost3410.Context, signature []byte, publicKey *PublicKey, publicNonce *PublicKey, msg []byte) (correct bool, err error) {
-	return verify(context, signature, publicKey, msg, publicNonce.X)
+/*
+VerifyDigest verifies a signature over an already computed message digest, which
+is interpreted as a big-endian integer as in GOST R 34.10-2012.
+*/
+func VerifyDigest(context *gost3410.Context, signature []byte, publicKey *PublicKey, digest []byte) (correct bool, err error) {
+	e := utils.BytesToBigInt(digest)
+	e.Mod(e, context.Curve.Params().N)
+	if e.Cmp(zero) == 0 {
+		e = big.NewInt(1)
+	}
+	return verify(context, signature, publicKey, e, nil)
 }
 
-func verify(context *gost3410.Context, signature []byte, publicKey *PublicKey, msg []byte, partialR *big.Int) (correct bool, err error) {
+/*
+VerifyPartial verifies the partial signature of the co-signer with publicKey and
+publicNonce. With WithKeyAggregation or WithSigners publicKey is weighted like
+the private key in SignPartial.
+*/
+func VerifyPartial(context *gost3410.Context, signature []byte, publicKey *PublicKey, publicNonce *PublicKey, msg []byte, opts ...Option) (correct bool, err error) {
+	if o := applyOptions(opts); o.weighted() {
+		a, err := o.coefficient(context, publicKey)
+		if err != nil {
+			return false, errors.Wrap(err, "cannot coefficient")
+		}
+		publicKey = &PublicKey{new(curve.Point).ScalarMult(context.Curve, publicKey.Point, a)}
+	}
+	e := hash.HashToInt(msg, context.HashAlgorithm, context.Curve)
+	return verify(context, signature, publicKey, e, publicNonce.X)
+}
+
+func verify(context *gost3410.Context, signature []byte, publicKey *PublicKey, e *big.Int, partialR *big.Int) (correct bool, err error) {
 	mode := context.Curve.Params().BitSize / 8
-	curve := context.Curve
 	q := context.Curve.Params().N
 
 	if len(signature) != 2*int(mode) {
@@ -95,41 +159,42 @@ func verify(context *gost3410.Context, signature []byte, publicKey *PublicKey, m
 		return false, nil
 	}
 
-	e := hash.HashToInt(msg, context.HashAlgorithm, curve)
 	v := big.NewInt(0).ModInverse(e, q)
 
 	// z1 = s * v mod q
 	z1 := big.NewInt(0).Mul(s, v)
 	z1.Mod(z1, q)
 
-	// z2 = -( r * v ) mod q
+	// z2 = r * v mod q
 	z2 := big.NewInt(0).Mul(r, v)
-	z2.Sub(q, z2.Mod(z2, q))
-
-	// z1 * P
-	p1x, p1y := curve.ScalarBaseMult(z1.Bytes())
+	z2.Mod(z2, q)
 
-	// z2 * Q
-	q1x, q1y := curve.ScalarMult(publicKey.X, publicKey.Y, z2.Bytes())
-
-	// C = z1 * P + z2 * Q
-	p1x, p1y = curve.Add(p1x, p1y, q1x, q1y)
+	// C = z1 * P - z2 * Q
+	C := new(curve.Point).ScalarBaseMult(context.Curve, z1)
+	C.Sub(context.Curve, C, new(curve.Point).ScalarMult(context.Curve, publicKey.Point, z2))
+	if C.IsZero() {
+		return false, nil
+	}
 
-	R := big.NewInt(0).Mod(p1x, q)
+	R := big.NewInt(0).Mod(C.X, q)
 	if partialR != nil {
 		// R must be equal to partialR
-		return R.Cmp(partialR) == 0, nil
+		return R.Cmp(big.NewInt(0).Mod(partialR, q)) == 0, nil
 	}
 
 	// R must be equal to r
 	return R.Cmp(r) == 0, nil
 }
 
+/*
+SumPublicKeys adds up the public keys. The sum is vulnerable to rogue-key attacks
+unless every key comes with a proof of possession; prefer AggregatePublicKeys.
+*/
 func SumPublicKeys(context *gost3410.Context, publicKeys []*PublicKey) (sum *PublicKey, err error) {
-	x, y := new(big.Int), new(big.Int)
+	point := new(curve.Point).SetInfinity()
 	for i := 0; i < len(publicKeys); i++ {
-		x, y = context.Curve.Add(x, y, publicKeys[i].X, publicKeys[i].Y)
+		point.Add(context.Curve, point, publicKeys[i].Point)
 	}
 
-	return &PublicKey{&curve.Point{x, y}}, nil
+	return &PublicKey{point}, nil
 }
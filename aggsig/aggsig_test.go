@@ -1,6 +1,8 @@
 package aggsig
 
 import (
+	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/AllFi/go-gost3410/hash"
@@ -13,8 +15,24 @@ import (
 )
 
 func TestAggsig(t *testing.T) {
-	n := 4
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	testAggsig(t, context)
+}
+
+func TestAggsigCurves(t *testing.T) {
+	for _, c := range curve.Curves() {
+		ha := gost3410.HashAlgorithm(hash.GOST34112012256)
+		if c.BitSize == 512 {
+			ha = hash.GOST34112012512
+		}
+		t.Run(c.Name, func(t *testing.T) {
+			testAggsig(t, gost3410.NewContext(c, ha))
+		})
+	}
+}
+
+func testAggsig(t *testing.T, context *gost3410.Context) {
+	n := 4
 	mode := context.Curve.Params().BitSize / 8
 
 	privateKeys := make([][]byte, n)
@@ -62,3 +80,138 @@ func TestAggsig(t *testing.T) {
 	assert.True(t, correct)
 	assert.NoError(t, err)
 }
+
+func TestKeyAggregation(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	n := 3
+	mode := context.Curve.Params().BitSize / 8
+
+	privateKeys := make([][]byte, n)
+	publicKeys := make([]*PublicKey, n)
+	nonces := make([][]byte, n)
+	publicNonces := make([]*PublicKey, n)
+	for i := 0; i < n; i++ {
+		privateKeys[i] = utils.RandomBytes(mode)
+		publicKey, err := NewPublicKey(context, privateKeys[i])
+		assert.NoError(t, err)
+		publicKeys[i] = publicKey
+
+		nonces[i] = utils.RandomBytes(mode)
+		publicNonce, err := NewPublicKey(context, nonces[i])
+		assert.NoError(t, err)
+		publicNonces[i] = publicNonce
+	}
+
+	keyAggregation, err := AggregatePublicKeys(context, publicKeys)
+	assert.NoError(t, err)
+
+	// the aggregated key does not depend on the order of the keys
+	reversed, err := AggregatePublicKeys(context, []*PublicKey{publicKeys[2], publicKeys[1], publicKeys[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, keyAggregation.PublicKey.Hex(context.Curve), reversed.PublicKey.Hex(context.Curve))
+
+	sum, err := SumPublicKeys(context, publicKeys)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum.Hex(context.Curve), keyAggregation.PublicKey.Hex(context.Curve))
+
+	sumPublicNonces, err := SumPublicKeys(context, publicNonces)
+	assert.NoError(t, err)
+
+	msg := []byte("Hello world!")
+	partialSignatures := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		partialSignatures[i], err = SignPartial(context, privateKeys[i], nonces[i], sumPublicNonces, msg, WithKeyAggregation(keyAggregation))
+		assert.NoError(t, err)
+
+		correct, err := VerifyPartial(context, partialSignatures[i], publicKeys[i], publicNonces[i], msg, WithKeyAggregation(keyAggregation))
+		assert.NoError(t, err)
+		assert.True(t, correct)
+
+		// without the coefficient the partial signature does not verify
+		correct, err = VerifyPartial(context, partialSignatures[i], publicKeys[i], publicNonces[i], msg)
+		assert.NoError(t, err)
+		assert.False(t, correct)
+	}
+
+	signature, err := AggregatePartialSignatures(context, partialSignatures, sumPublicNonces)
+	assert.NoError(t, err)
+
+	correct, err := Verify(context, signature, nil, msg, WithKeyAggregation(keyAggregation))
+	assert.NoError(t, err)
+	assert.True(t, correct)
+
+	correct, err = Verify(context, signature, keyAggregation.PublicKey, msg)
+	assert.NoError(t, err)
+	assert.True(t, correct)
+
+	_, err = Verify(context, signature, sum, msg, WithKeyAggregation(keyAggregation))
+	assert.Error(t, err)
+
+	// a signer outside of the key set cannot produce a partial signature
+	_, err = SignPartial(context, utils.RandomBytes(mode), nonces[0], sumPublicNonces, msg, WithKeyAggregation(keyAggregation))
+	assert.Error(t, err)
+}
+
+func TestKeyAggregationRogueKey(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+
+	honest, err := NewPublicKey(context, utils.RandomBytes(mode))
+	assert.NoError(t, err)
+
+	// the attacker knows the private key of target and publishes
+	// target - honest, so that the plain sum of the keys is target
+	targetPrivateKey := utils.RandomBytes(mode)
+	target, err := NewPublicKey(context, targetPrivateKey)
+	assert.NoError(t, err)
+	negHonest := &curve.Point{X: honest.X, Y: new(big.Int).Sub(context.Curve.Params().P, honest.Y)}
+	rogue := &PublicKey{new(curve.Point).Add(context.Curve, target.Point, negHonest)}
+
+	sum, err := SumPublicKeys(context, []*PublicKey{honest, rogue})
+	assert.NoError(t, err)
+	assert.Equal(t, target.Hex(context.Curve), sum.Hex(context.Curve))
+
+	keyAggregation, err := AggregatePublicKeys(context, []*PublicKey{honest, rogue})
+	assert.NoError(t, err)
+	assert.NotEqual(t, target.Hex(context.Curve), keyAggregation.PublicKey.Hex(context.Curve))
+
+	// a signature made by the attacker alone with the target key does not
+	// verify under the aggregated key
+	nonce := utils.RandomBytes(mode)
+	publicNonce, err := NewPublicKey(context, nonce)
+	assert.NoError(t, err)
+	msg := []byte("Hello world!")
+	signature, err := SignPartial(context, targetPrivateKey, nonce, publicNonce, msg)
+	assert.NoError(t, err)
+
+	correct, err := Verify(context, signature, sum, msg)
+	assert.NoError(t, err)
+	assert.True(t, correct)
+
+	correct, err = Verify(context, signature, nil, msg, WithKeyAggregation(keyAggregation))
+	assert.NoError(t, err)
+	assert.False(t, correct)
+}
+
+func TestPublicKeyFromBytes(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	publicKey, err := NewPublicKey(context, utils.RandomBytes(32))
+	assert.NoError(t, err)
+
+	for _, raw := range [][]byte{publicKey.Bytes(context.Curve), publicKey.BytesCompressed(context.Curve)} {
+		decoded, err := PublicKeyFromBytes(context, raw)
+		assert.NoError(t, err)
+		assert.Equal(t, publicKey.Bytes(context.Curve), decoded.Bytes(context.Curve))
+	}
+	decoded, err := PublicKeyFromHex(context, publicKey.Hex(context.Curve))
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey.Bytes(context.Curve), decoded.Bytes(context.Curve))
+
+	// a point off the curve is rejected before it reaches any arithmetic
+	raw := publicKey.Bytes(context.Curve)
+	raw[len(raw)-1] ^= 1
+	_, err = PublicKeyFromBytes(context, raw)
+	assert.True(t, errors.Is(err, curve.ErrNotOnCurve), "%v", err)
+	_, err = AggregatePublicKeys(context, []*PublicKey{publicKey, {&curve.Point{X: publicKey.X, Y: new(big.Int).Xor(publicKey.Y, big.NewInt(1))}}})
+	assert.True(t, errors.Is(err, curve.ErrNotOnCurve), "%v", err)
+}
@@ -0,0 +1,266 @@
+package aggsig
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+// batchLeafSize is the size below which VerifyBatch verifies one by one.
+const batchLeafSize = 4
+
+/*
+WithEvenNonce makes SignPartial negate the nonce when the y coordinate of
+sumNonces is odd, so that the nonce point of the aggregated signature has an even
+y coordinate. The signature stays a standard GOST R 34.10-2012 signature, as
+x(-R) = x(R), but it can take the fast path of VerifyBatch. All co-signers must
+use it. Passed to VerifyBatch, it tells it to expect such signatures.
+*/
+func WithEvenNonce() Option {
+	return func(o *options) {
+		o.evenNonce = true
+	}
+}
+
+/*
+VerifyBatch verifies signatures[i] of msgs[i] under publicKeys[i] for all i and
+returns the indices of the invalid signatures. It accepts exactly the
+signatures that Verify accepts.
+
+Without WithEvenNonce every signature is checked with Verify, as a standard
+signature does not determine its nonce point and gains nothing from batching.
+
+With WithEvenNonce every signature satisfies s*P - r*Q - e*R = 0, where R is
+the point with x = r and an even y, so VerifyBatch checks a random linear
+combination of these equations with a single multi-scalar multiplication. On
+curves with a cofactor this only holds if x(R) < q, and every R and Q is
+checked to be in the subgroup of order q first, which costs a scalar
+multiplication each. Signatures whose R cannot be lifted this way are checked
+with Verify. If the combined check fails, the batch is split in halves until
+the invalid signatures are found; once both halves fail too, the rest is
+checked with Verify, so that a batch of mostly unsuitable signatures costs
+little more than verifying one by one.
+*/
+func VerifyBatch(context *gost3410.Context, signatures [][]byte, publicKeys []*PublicKey, msgs [][]byte, opts ...Option) (invalid []int, err error) {
+	if len(signatures) != len(publicKeys) || len(signatures) != len(msgs) {
+		err = errors.New("signatures, public keys and messages must be of the same length")
+		return
+	}
+	evenNonce := applyOptions(opts).evenNonce
+
+	items := make([]*batchItem, 0, len(signatures))
+	var single []*batchItem
+	for i := range signatures {
+		item, ok := newBatchItem(context, i, signatures[i], publicKeys[i], msgs[i], evenNonce)
+		if !ok {
+			invalid = append(invalid, i)
+			continue
+		}
+		if item.R == nil {
+			single = append(single, item)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	bad, err := verifyBatch(context, items)
+	if err != nil {
+		return nil, err
+	}
+	invalid = mergeIndices(invalid, verifyEach(context, single))
+	return mergeIndices(invalid, bad), nil
+}
+
+type batchItem struct {
+	index     int
+	s, r, e   *big.Int
+	publicKey *PublicKey
+	R         *curve.Point
+	signature []byte
+	msg       []byte
+}
+
+/*
+newBatchItem parses a signature; ok is false if it is malformed. If lift is set,
+R is the even lift of r, or nil if it does not exist or R or Q are not in the
+subgroup of order q, where the combined check would be unsound.
+*/
+func newBatchItem(context *gost3410.Context, index int, signature []byte, publicKey *PublicKey, msg []byte, lift bool) (item *batchItem, ok bool) {
+	mode := context.Curve.Params().BitSize / 8
+	q := context.Curve.Params().N
+	if len(signature) != 2*mode || publicKey == nil || publicKey.IsZero() ||
+		!publicKey.IsOnCurve(context.Curve) {
+		return nil, false
+	}
+
+	// r > 0, r < q, s > 0, s < q
+	s := utils.BytesToBigInt(signature[:mode])
+	r := utils.BytesToBigInt(signature[mode:])
+	if r.Cmp(zero) <= 0 || r.Cmp(q) >= 0 || s.Cmp(zero) <= 0 || s.Cmp(q) >= 0 {
+		return nil, false
+	}
+
+	item = &batchItem{
+		index:     index,
+		s:         s,
+		r:         r,
+		e:         hash.HashToInt(msg, context.HashAlgorithm, context.Curve),
+		publicKey: publicKey,
+		signature: signature,
+		msg:       msg,
+	}
+	if lift {
+		R := liftX(context, r)
+		if R != nil && R.IsInSubgroup(context.Curve) && publicKey.IsInSubgroup(context.Curve) {
+			item.R = R
+		}
+	}
+	return item, true
+}
+
+// liftX returns the point with x coordinate x and an even y coordinate, or nil.
+func liftX(context *gost3410.Context, x *big.Int) *curve.Point {
+	p := context.Curve.Params().P
+	y2, err := curve.F(context.Curve, x)
+	if err != nil {
+		return nil
+	}
+	y := new(big.Int).ModSqrt(y2, p)
+	if y == nil {
+		return nil
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(p, y)
+	}
+	return &curve.Point{X: new(big.Int).Set(x), Y: y}
+}
+
+// verifyBatch returns the indices of the invalid items.
+func verifyBatch(context *gost3410.Context, items []*batchItem) (invalid []int, err error) {
+	if len(items) <= batchLeafSize {
+		return verifyEach(context, items), nil
+	}
+
+	correct, err := checkBatch(context, items)
+	if err != nil || correct {
+		return nil, err
+	}
+	return bisectBatch(context, items)
+}
+
+// bisectBatch returns the indices of the invalid items of a batch that failed
+// the combined check.
+func bisectBatch(context *gost3410.Context, items []*batchItem) (invalid []int, err error) {
+	if len(items) <= batchLeafSize {
+		return verifyEach(context, items), nil
+	}
+
+	half := len(items) / 2
+	parts := [][]*batchItem{items[:half], items[half:]}
+	var failed [][]*batchItem
+	for _, part := range parts {
+		if len(part) <= batchLeafSize {
+			failed = append(failed, part)
+			continue
+		}
+		correct, err := checkBatch(context, part)
+		if err != nil {
+			return nil, err
+		}
+		if !correct {
+			failed = append(failed, part)
+		}
+	}
+
+	// the failures are spread over the batch, stop spending checks on it
+	if len(failed) == 2 && len(failed[0]) > batchLeafSize && len(failed[1]) > batchLeafSize {
+		return verifyEach(context, items), nil
+	}
+
+	for _, part := range failed {
+		bad, err := bisectBatch(context, part)
+		if err != nil {
+			return nil, err
+		}
+		invalid = append(invalid, bad...)
+	}
+	return invalid, nil
+}
+
+// verifyEach returns the indices of the items that Verify rejects.
+func verifyEach(context *gost3410.Context, items []*batchItem) (invalid []int) {
+	for _, item := range items {
+		correct, err := Verify(context, item.signature, item.publicKey, item.msg)
+		if err != nil || !correct {
+			invalid = append(invalid, item.index)
+		}
+	}
+	return invalid
+}
+
+// checkBatch checks sum(c_i*(s_i*P - r_i*Q_i - e_i*R_i)) = 0 for random c_i. The
+// scalars are reduced mod q, so every R_i and Q_i must be in the subgroup.
+func checkBatch(context *gost3410.Context, items []*batchItem) (correct bool, err error) {
+	q := context.Curve.Params().N
+	g := &curve.Point{X: context.Curve.Params().Gx, Y: context.Curve.Params().Gy}
+
+	points := []*curve.Point{g}
+	scalars := []*big.Int{new(big.Int)}
+	for _, item := range items {
+		if item.R == nil {
+			return false, nil
+		}
+		c, err := randomCoefficient()
+		if err != nil {
+			return false, errors.Wrap(err, "cannot randomCoefficient")
+		}
+
+		// c*s for P
+		scalars[0].Add(scalars[0], new(big.Int).Mul(c, item.s))
+		scalars[0].Mod(scalars[0], q)
+
+		// -c*r for Q
+		cr := new(big.Int).Mul(c, item.r)
+		points = append(points, item.publicKey.Point)
+		scalars = append(scalars, cr.Sub(q, cr.Mod(cr, q)))
+
+		// -c*e for R
+		ce := new(big.Int).Mul(c, item.e)
+		points = append(points, item.R)
+		scalars = append(scalars, ce.Sub(q, ce.Mod(ce, q)))
+	}
+
+	return curve.MultiScalarMult(context.Curve, points, scalars).IsZero(), nil
+}
+
+// randomCoefficient returns a random non-zero 128-bit coefficient.
+func randomCoefficient() (*big.Int, error) {
+	raw := make([]byte, 16)
+	for {
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		c := utils.BytesToBigInt(raw)
+		if c.Sign() != 0 {
+			return c, nil
+		}
+	}
+}
+
+// mergeIndices merges two sorted lists of indices.
+func mergeIndices(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0] < b[0] {
+			merged, a = append(merged, a[0]), a[1:]
+		} else {
+			merged, b = append(merged, b[0]), b[1:]
+		}
+	}
+	return append(append(merged, a...), b...)
+}
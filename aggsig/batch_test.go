@@ -0,0 +1,196 @@
+package aggsig
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signBatch(t *testing.T, context *gost3410.Context, n int, opts ...Option) ([][]byte, []*PublicKey, [][]byte) {
+	mode := context.Curve.Params().BitSize / 8
+	signatures := make([][]byte, n)
+	publicKeys := make([]*PublicKey, n)
+	msgs := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		privateKey := utils.RandomBytes(mode)
+		publicKey, err := NewPublicKey(context, privateKey)
+		require.NoError(t, err)
+		nonce := utils.RandomBytes(mode)
+		publicNonce, err := NewPublicKey(context, nonce)
+		require.NoError(t, err)
+
+		msgs[i] = utils.RandomBytes(32)
+		signatures[i], err = SignPartial(context, privateKey, nonce, publicNonce, msgs[i], opts...)
+		require.NoError(t, err)
+		publicKeys[i] = publicKey
+	}
+	return signatures, publicKeys, msgs
+}
+
+func TestVerifyBatch(t *testing.T) {
+	for _, c := range []*curve.CurveParams{curve.GOST34102001, curve.GOST34102012256A} {
+		t.Run(c.Name, func(t *testing.T) {
+			context := gost3410.NewContext(c, hash.GOST34112012256)
+			signatures, publicKeys, msgs := signBatch(t, context, 20, WithEvenNonce())
+			for i := range signatures {
+				correct, err := Verify(context, signatures[i], publicKeys[i], msgs[i])
+				require.NoError(t, err)
+				require.True(t, correct)
+			}
+
+			invalid, err := VerifyBatch(context, signatures, publicKeys, msgs, WithEvenNonce())
+			require.NoError(t, err)
+			assert.Empty(t, invalid)
+
+			// tampered signatures are found exactly
+			msgs[3] = []byte("Hello world?")
+			signatures[11] = append([]byte{}, signatures[11]...)
+			signatures[11][0] ^= 0x01
+			publicKeys[17] = publicKeys[16]
+			invalid, err = VerifyBatch(context, signatures, publicKeys, msgs, WithEvenNonce())
+			require.NoError(t, err)
+			assert.Equal(t, []int{3, 11, 17}, invalid)
+
+			invalid, err = VerifyBatch(context, signatures, publicKeys, msgs)
+			require.NoError(t, err)
+			assert.Equal(t, []int{3, 11, 17}, invalid)
+		})
+	}
+}
+
+func TestCheckBatch(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	signatures, publicKeys, msgs := signBatch(t, context, 8, WithEvenNonce())
+
+	// even nonces take the fast path
+	items := make([]*batchItem, len(signatures))
+	for i := range signatures {
+		item, ok := newBatchItem(context, i, signatures[i], publicKeys[i], msgs[i], true)
+		require.True(t, ok)
+		items[i] = item
+	}
+	correct, err := checkBatch(context, items)
+	require.NoError(t, err)
+	assert.True(t, correct)
+
+	items[6].e.Add(items[6].e, items[6].e)
+	correct, err = checkBatch(context, items)
+	require.NoError(t, err)
+	assert.False(t, correct)
+}
+
+func TestVerifyBatchOddNonces(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+
+	// without WithEvenNonce at signing about half of the nonce points have an
+	// odd y, and such signatures are still accepted by the fallback
+	signatures, publicKeys, msgs := signBatch(t, context, 12)
+	for _, opts := range [][]Option{nil, {WithEvenNonce()}} {
+		invalid, err := VerifyBatch(context, signatures, publicKeys, msgs, opts...)
+		require.NoError(t, err)
+		assert.Empty(t, invalid)
+	}
+
+	msgs[5] = []byte("Hello world?")
+	for _, opts := range [][]Option{nil, {WithEvenNonce()}} {
+		invalid, err := VerifyBatch(context, signatures, publicKeys, msgs, opts...)
+		require.NoError(t, err)
+		assert.Equal(t, []int{5}, invalid)
+	}
+}
+
+func TestVerifyBatchTorsion(t *testing.T) {
+	c := curve.GOST34102012256A
+	context := gost3410.NewContext(c, hash.GOST34112012256)
+	mode := c.BitSize / 8
+	q := c.N
+
+	// only keep the signatures with x(R) < q, which take the fast path
+	var signatures, msgs [][]byte
+	var publicKeys []*PublicKey
+	for len(signatures) < 8 {
+		signature, publicKey, msg := signBatch(t, context, 1, WithEvenNonce())
+		item, ok := newBatchItem(context, 0, signature[0], publicKey[0], msg[0], true)
+		require.True(t, ok)
+		if item.R == nil {
+			continue
+		}
+		if correct, err := checkBatch(context, []*batchItem{item}); err != nil || !correct {
+			continue
+		}
+		signatures = append(signatures, signature[0])
+		publicKeys = append(publicKeys, publicKey[0])
+		msgs = append(msgs, msg[0])
+	}
+
+	// R = k*G + T with a 2-torsion point T, x(R) < q and an even y, so R is
+	// the even lift of r while C = k*G in Verify
+	x, y := c.FromEdwards(new(big.Int), new(big.Int).Sub(c.P, big.NewInt(1)))
+	T := &curve.Point{X: x, Y: y}
+	var k *big.Int
+	var R *curve.Point
+	for {
+		k = utils.BytesToBigInt(utils.RandomBytes(mode))
+		k.Mod(k, q)
+		R = new(curve.Point).ScalarBaseMult(c, k)
+		R.Add(c, R, T)
+		if R.X.Cmp(q) < 0 && R.Y.Bit(0) == 0 {
+			break
+		}
+	}
+
+	// an unchecked combined equation holds whenever c*e mod q is even and the
+	// torsion part of R vanishes
+	privateKey := utils.RandomBytes(mode)
+	publicKey, err := NewPublicKey(context, privateKey)
+	require.NoError(t, err)
+	msg := utils.RandomBytes(32)
+	e := hash.HashToInt(msg, context.HashAlgorithm, c)
+	d := new(big.Int).Mod(utils.BytesToBigInt(privateKey), q)
+	r := new(big.Int).Set(R.X)
+	s := new(big.Int).Mul(r, d)
+	s.Add(s, new(big.Int).Mul(k, e))
+	s.Mod(s, q)
+	signature := append(utils.Pad(s.Bytes(), mode), utils.Pad(r.Bytes(), mode)...)
+
+	correct, err := Verify(context, signature, publicKey, msg)
+	require.NoError(t, err)
+	require.False(t, correct)
+
+	signatures = append(signatures, signature)
+	publicKeys = append(publicKeys, publicKey)
+	msgs = append(msgs, msg)
+	invalid, err := VerifyBatch(context, signatures, publicKeys, msgs, WithEvenNonce())
+	require.NoError(t, err)
+	assert.Equal(t, []int{8}, invalid)
+
+	item, ok := newBatchItem(context, 8, signature, publicKey, msg, true)
+	require.True(t, ok)
+	assert.Nil(t, item.R)
+}
+
+func TestVerifyBatchErrors(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	signatures, publicKeys, msgs := signBatch(t, context, 6, WithEvenNonce())
+
+	_, err := VerifyBatch(context, signatures, publicKeys[1:], msgs)
+	assert.Error(t, err)
+
+	// malformed signatures and keys are reported as invalid
+	signatures[0] = signatures[0][1:]
+	signatures[2] = make([]byte, len(signatures[2]))
+	publicKeys[4] = nil
+	invalid, err := VerifyBatch(context, signatures, publicKeys, msgs)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 4}, invalid)
+
+	invalid, err = VerifyBatch(context, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+}
@@ -0,0 +1,158 @@
+package aggsig
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/pkg/errors"
+)
+
+var (
+	keyAggListTag        = []byte("GOST3410/aggsig/keyagg/list")
+	keyAggCoefficientTag = []byte("GOST3410/aggsig/keyagg/coefficient")
+)
+
+/*
+KeyAggregation is a MuSig-style aggregate of a set of public keys. Every key P_i
+is weighted by a_i = H(L, P_i), hashed to a scalar by hash.HashToScalar, where
+L = H(tag || sorted keys), so the aggregated key is sum(a_i * P_i). Unlike
+SumPublicKeys it is safe against rogue-key attacks: a co-signer cannot choose
+its key as a function of the others without changing all the coefficients.
+*/
+type KeyAggregation struct {
+	// PublicKey is the aggregated public key that signatures verify against.
+	PublicKey *PublicKey
+
+	publicKeys   [][]byte
+	coefficients []*big.Int
+}
+
+/*
+AggregatePublicKeys computes the key aggregation of publicKeys. The result does
+not depend on the order of publicKeys.
+*/
+func AggregatePublicKeys(context *gost3410.Context, publicKeys []*PublicKey) (keyAggregation *KeyAggregation, err error) {
+	if len(publicKeys) == 0 {
+		err = errors.New("no public keys")
+		return
+	}
+
+	encoded := make([][]byte, len(publicKeys))
+	for i := 0; i < len(publicKeys); i++ {
+		if publicKeys[i] == nil {
+			err = errors.Errorf("invalid public key %d", i)
+			return
+		}
+		if err = publicKeys[i].Validate(context.Curve); err != nil {
+			err = errors.Wrapf(err, "invalid public key %d", i)
+			return
+		}
+		encoded[i] = publicKeys[i].Bytes(context.Curve)
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+
+	// L = H(tag || P_1 || ... || P_n)
+	list := append([]byte{}, keyAggListTag...)
+	for i := 0; i < len(encoded); i++ {
+		list = append(list, encoded[i]...)
+	}
+	h := context.HashAlgorithm.New()
+	h.Write(list)
+	l := h.Sum(nil)
+
+	keyAggregation = &KeyAggregation{
+		publicKeys:   encoded,
+		coefficients: make([]*big.Int, len(encoded)),
+	}
+	sum := new(curve.Point).SetInfinity()
+	for i := 0; i < len(encoded); i++ {
+		// a_i = H(L, P_i) under the tag
+		a, err := hash.HashToScalar(context, keyAggCoefficientTag, l, encoded[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot HashToScalar")
+		}
+		if a.Sign() == 0 {
+			return nil, errors.New("key aggregation coefficient is zero")
+		}
+		keyAggregation.coefficients[i] = a
+
+		p, err := curve.PointFromBytes(context.Curve, encoded[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot PointFromBytes")
+		}
+		sum.Add(context.Curve, sum, new(curve.Point).ScalarMult(context.Curve, p, a))
+	}
+	if sum.IsZero() {
+		err = errors.New("aggregated public key is the point at infinity")
+		return
+	}
+
+	keyAggregation.PublicKey = &PublicKey{sum}
+	return
+}
+
+/*
+Coefficient returns the coefficient a_i of publicKey, or an error if publicKey is
+not a part of the aggregation.
+*/
+func (ka *KeyAggregation) Coefficient(context *gost3410.Context, publicKey *PublicKey) (*big.Int, error) {
+	encoded := publicKey.Bytes(context.Curve)
+	i := sort.Search(len(ka.publicKeys), func(i int) bool {
+		return bytes.Compare(ka.publicKeys[i], encoded) >= 0
+	})
+	if i == len(ka.publicKeys) || !bytes.Equal(ka.publicKeys[i], encoded) {
+		return nil, errors.New("public key is not a part of the key aggregation")
+	}
+	return new(big.Int).Set(ka.coefficients[i]), nil
+}
+
+/*
+Option modifies the behaviour of SignPartial, VerifyPartial and Verify.
+*/
+type Option func(*options)
+
+type options struct {
+	keyAggregation *KeyAggregation
+	threshold      *thresholdOptions
+	evenNonce      bool
+}
+
+/*
+WithKeyAggregation makes partial signatures and their verification use the
+coefficients of keyAggregation, and makes Verify check the signature against the
+aggregated public key.
+*/
+func WithKeyAggregation(keyAggregation *KeyAggregation) Option {
+	return func(o *options) {
+		o.keyAggregation = keyAggregation
+	}
+}
+
+func applyOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) weighted() bool {
+	return o.keyAggregation != nil || o.threshold != nil
+}
+
+// coefficient returns the weight of the signer with publicKey.
+func (o *options) coefficient(context *gost3410.Context, publicKey *PublicKey) (*big.Int, error) {
+	if o.keyAggregation != nil && o.threshold != nil {
+		return nil, errors.New("key aggregation and threshold signing cannot be combined")
+	}
+	if o.threshold != nil {
+		return o.threshold.lagrangeCoefficient(context)
+	}
+	return o.keyAggregation.Coefficient(context, publicKey)
+}
@@ -0,0 +1,244 @@
+package aggsig
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+This file implements a MuSig2-style two-round variant of the protocol. Every
+signer publishes NonceCount2 nonce points R_i1, R_i2 ahead of time, possibly long
+before the message is known. Once it is, the effective nonce is
+
+	R = R_1 + b*R_2, b = H(R_1, R_2, P, msg)
+
+where R_j = sum(R_ij), P is the aggregated public key and H is hash.HashToScalar
+under a tag of its own, so signing takes a single online round. As b depends on
+all the nonces, no signer can steer R by choosing its nonces after seeing the
+others.
+*/
+
+// NonceCount2 is the number of nonces each signer publishes for SignPartial2.
+const NonceCount2 = 2
+
+var (
+	nonceCoefficientTag = []byte("GOST3410/aggsig/musig2/nonce")
+	nonceIndexTag       = []byte("GOST3410/aggsig/musig2/nonce-index")
+)
+
+/*
+GenerateNonces2 draws the secret nonces of one signer for a single SignPartial2
+call and returns them together with the public nonces to publish. The secret
+nonces must never be used twice.
+
+The nonces are SyntheticNonces of rawPrivateKey, sessionData and randomness read
+from rand. As the message is not known yet, a broken rand only keeps them unique
+if sessionData is, so with an untrusted rand put a counter or the identifier of
+the signing session into it.
+*/
+func GenerateNonces2(context *gost3410.Context, rawPrivateKey []byte, sessionData [][]byte, rand io.Reader) (nonces [][]byte, publicNonces []*PublicKey, err error) {
+	nonces = make([][]byte, NonceCount2)
+	publicNonces = make([]*PublicKey, NonceCount2)
+	for j := 0; j < NonceCount2; j++ {
+		data := append([][]byte{nonceIndexTag, {byte(j)}}, sessionData...)
+		nonces[j], err = SyntheticNonce(context, rawPrivateKey, nil, data, rand)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "cannot SyntheticNonce")
+		}
+		publicNonces[j], err = NewPublicKey(context, nonces[j])
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "cannot NewPublicKey")
+		}
+	}
+	return
+}
+
+/*
+AggregateNonces2 adds up the public nonces of all signers position by position,
+R_j = sum(R_ij). publicNonces[i] holds the public nonces of signer i.
+*/
+func AggregateNonces2(context *gost3410.Context, publicNonces [][]*PublicKey) (aggregatedNonces []*PublicKey, err error) {
+	aggregatedNonces = make([]*PublicKey, NonceCount2)
+	for j := 0; j < NonceCount2; j++ {
+		sum := new(curve.Point).SetInfinity()
+		for i := 0; i < len(publicNonces); i++ {
+			if err = checkNonces2(context, publicNonces[i]); err != nil {
+				return nil, errors.Wrapf(err, "invalid public nonces of signer %d", i)
+			}
+			sum.Add(context.Curve, sum, publicNonces[i][j].Point)
+		}
+		aggregatedNonces[j] = &PublicKey{sum}
+	}
+	return
+}
+
+/*
+SignPartial2 creates the partial signature of one signer in the two-round
+protocol. nonces are the secret nonces from GenerateNonces2 and
+aggregatedNonces are the output of AggregateNonces2.
+*/
+func SignPartial2(
+	context *gost3410.Context,
+	rawPrivateKey []byte,
+	nonces [][]byte,
+	aggregatedNonces []*PublicKey,
+	keyAggregation *KeyAggregation,
+	msg []byte,
+) (
+	signature []byte,
+	err error,
+) {
+	if len(nonces) != NonceCount2 {
+		err = errors.New("wrong number of nonces")
+		return
+	}
+
+	b, R, err := effectiveNonce2(context, aggregatedNonces, keyAggregation, msg)
+	if err != nil {
+		err = errors.Wrap(err, "cannot effectiveNonce2")
+		return
+	}
+
+	// k = k_1 + b*k_2 mod q
+	q := context.Curve.Params().N
+	mode := context.Curve.Params().BitSize / 8
+	k := new(big.Int).SetBytes(nonces[1])
+	k.Mul(k, b)
+	k.Add(k, new(big.Int).SetBytes(nonces[0]))
+	k.Mod(k, q)
+	if k.Cmp(zero) == 0 {
+		err = errors.New("effective nonce is zero")
+		return
+	}
+
+	return SignPartial(context, rawPrivateKey, utils.Pad(k.Bytes(), mode), R, msg, WithKeyAggregation(keyAggregation), WithEvenNonce())
+}
+
+/*
+VerifyPartial2 verifies the partial signature of the signer with publicKey and
+publicNonces in the two-round protocol.
+*/
+func VerifyPartial2(
+	context *gost3410.Context,
+	signature []byte,
+	publicKey *PublicKey,
+	publicNonces []*PublicKey,
+	aggregatedNonces []*PublicKey,
+	keyAggregation *KeyAggregation,
+	msg []byte,
+) (
+	correct bool,
+	err error,
+) {
+	if err = checkNonces2(context, publicNonces); err != nil {
+		err = errors.Wrap(err, "invalid public nonces")
+		return
+	}
+
+	b, _, err := effectiveNonce2(context, aggregatedNonces, keyAggregation, msg)
+	if err != nil {
+		err = errors.Wrap(err, "cannot effectiveNonce2")
+		return
+	}
+
+	// R_i = R_i1 + b*R_i2
+	publicNonce := new(curve.Point).ScalarMult(context.Curve, publicNonces[1].Point, b)
+	publicNonce.Add(context.Curve, publicNonce, publicNonces[0].Point)
+	if publicNonce.IsZero() {
+		return false, nil
+	}
+	return VerifyPartial(context, signature, publicKey, &PublicKey{publicNonce}, msg, WithKeyAggregation(keyAggregation))
+}
+
+/*
+Aggregate2 combines the partial signatures of the two-round protocol into a
+signature that Verify accepts under keyAggregation.PublicKey.
+*/
+func Aggregate2(
+	context *gost3410.Context,
+	rawPartialSignatures [][]byte,
+	aggregatedNonces []*PublicKey,
+	keyAggregation *KeyAggregation,
+	msg []byte,
+) (
+	signature []byte,
+	err error,
+) {
+	_, R, err := effectiveNonce2(context, aggregatedNonces, keyAggregation, msg)
+	if err != nil {
+		err = errors.Wrap(err, "cannot effectiveNonce2")
+		return
+	}
+	return AggregatePartialSignatures(context, rawPartialSignatures, R)
+}
+
+// effectiveNonce2 returns the nonce coefficient b and R = R_1 + b*R_2.
+func effectiveNonce2(
+	context *gost3410.Context,
+	aggregatedNonces []*PublicKey,
+	keyAggregation *KeyAggregation,
+	msg []byte,
+) (
+	b *big.Int,
+	R *PublicKey,
+	err error,
+) {
+	if keyAggregation == nil {
+		err = errors.New("key aggregation is required")
+		return
+	}
+	if len(aggregatedNonces) != NonceCount2 {
+		err = errors.New("wrong number of aggregated nonces")
+		return
+	}
+
+	// b = H(R_1, R_2, P, msg) under the tag
+	var parts [][]byte
+	for j := 0; j < NonceCount2; j++ {
+		if aggregatedNonces[j] == nil || aggregatedNonces[j].Point == nil {
+			err = errors.New("invalid aggregated nonce")
+			return
+		}
+		parts = append(parts, encodePoint(context, aggregatedNonces[j].Point))
+	}
+	parts = append(parts, keyAggregation.PublicKey.Bytes(context.Curve), msg)
+	if b, err = hash.HashToScalar(context, nonceCoefficientTag, parts...); err != nil {
+		err = errors.Wrap(err, "cannot HashToScalar")
+		return
+	}
+
+	p := new(curve.Point).ScalarMult(context.Curve, aggregatedNonces[1].Point, b)
+	p.Add(context.Curve, p, aggregatedNonces[0].Point)
+	if p.IsZero() {
+		err = errors.New("effective nonce is the point at infinity")
+		return
+	}
+	return b, &PublicKey{p}, nil
+}
+
+// encodePoint is Point.Bytes with the point at infinity encoded as zeros.
+func encodePoint(context *gost3410.Context, p *curve.Point) []byte {
+	if p.IsZero() {
+		return make([]byte, 2*(context.Curve.Params().BitSize/8))
+	}
+	return p.Bytes(context.Curve)
+}
+
+func checkNonces2(context *gost3410.Context, publicNonces []*PublicKey) error {
+	if len(publicNonces) != NonceCount2 {
+		return errors.New("wrong number of public nonces")
+	}
+	for j := 0; j < NonceCount2; j++ {
+		if publicNonces[j] == nil || publicNonces[j].IsZero() ||
+			!publicNonces[j].IsOnCurve(context.Curve) {
+			return errors.Errorf("invalid public nonce %d", j)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package aggsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignPartial2(t *testing.T) {
+	for _, c := range []*curve.CurveParams{curve.GOST34102001CryptoProA, curve.GOST34102012256A, curve.GOST34102012512C} {
+		ha := gost3410.HashAlgorithm(hash.GOST34112012256)
+		if c.BitSize == 512 {
+			ha = hash.GOST34112012512
+		}
+		context := gost3410.NewContext(c, ha)
+		n := 3
+		mode := context.Curve.Params().BitSize / 8
+
+		privateKeys := make([][]byte, n)
+		publicKeys := make([]*PublicKey, n)
+		nonces := make([][][]byte, n)
+		publicNonces := make([][]*PublicKey, n)
+		for i := 0; i < n; i++ {
+			privateKeys[i] = utils.RandomBytes(mode)
+			publicKey, err := NewPublicKey(context, privateKeys[i])
+			require.NoError(t, err)
+			publicKeys[i] = publicKey
+
+			// the nonces are published before the message is known
+			nonces[i], publicNonces[i], err = GenerateNonces2(context, privateKeys[i], nil, rand.Reader)
+			require.NoError(t, err)
+		}
+
+		keyAggregation, err := AggregatePublicKeys(context, publicKeys)
+		require.NoError(t, err)
+		aggregatedNonces, err := AggregateNonces2(context, publicNonces)
+		require.NoError(t, err)
+
+		msg := []byte("Hello world!")
+		partialSignatures := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			partialSignatures[i], err = SignPartial2(context, privateKeys[i], nonces[i], aggregatedNonces, keyAggregation, msg)
+			require.NoError(t, err)
+
+			correct, err := VerifyPartial2(context, partialSignatures[i], publicKeys[i], publicNonces[i], aggregatedNonces, keyAggregation, msg)
+			assert.NoError(t, err)
+			assert.True(t, correct, c.Name)
+
+			// the partial signature is bound to the nonces of its signer
+			correct, err = VerifyPartial2(context, partialSignatures[i], publicKeys[i], publicNonces[(i+1)%n], aggregatedNonces, keyAggregation, msg)
+			assert.NoError(t, err)
+			assert.False(t, correct, c.Name)
+		}
+
+		signature, err := Aggregate2(context, partialSignatures, aggregatedNonces, keyAggregation, msg)
+		require.NoError(t, err)
+
+		correct, err := Verify(context, signature, keyAggregation.PublicKey, msg)
+		assert.NoError(t, err)
+		assert.True(t, correct, c.Name)
+
+		correct, err = Verify(context, signature, keyAggregation.PublicKey, []byte("Hello world?"))
+		assert.NoError(t, err)
+		assert.False(t, correct, c.Name)
+	}
+}
+
+func TestSignPartial2Errors(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+
+	privateKey := utils.RandomBytes(mode)
+	publicKey, err := NewPublicKey(context, privateKey)
+	require.NoError(t, err)
+	keyAggregation, err := AggregatePublicKeys(context, []*PublicKey{publicKey})
+	require.NoError(t, err)
+
+	nonces, publicNonces, err := GenerateNonces2(context, privateKey, nil, rand.Reader)
+	require.NoError(t, err)
+	aggregatedNonces, err := AggregateNonces2(context, [][]*PublicKey{publicNonces})
+	require.NoError(t, err)
+
+	msg := []byte("Hello world!")
+	_, err = SignPartial2(context, privateKey, nonces, aggregatedNonces, nil, msg)
+	assert.Error(t, err)
+	_, err = SignPartial2(context, privateKey, nonces[:1], aggregatedNonces, keyAggregation, msg)
+	assert.Error(t, err)
+	_, err = SignPartial2(context, privateKey, nonces, aggregatedNonces[:1], keyAggregation, msg)
+	assert.Error(t, err)
+	_, err = AggregateNonces2(context, [][]*PublicKey{publicNonces[:1]})
+	assert.Error(t, err)
+}
+
+func TestGenerateNonces2(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	privateKey := utils.RandomBytes(mode)
+
+	nonces, _, err := GenerateNonces2(context, privateKey, nil, rand.Reader)
+	require.NoError(t, err)
+	assert.NotEqual(t, nonces[0], nonces[1])
+	again, _, err := GenerateNonces2(context, privateKey, nil, rand.Reader)
+	require.NoError(t, err)
+	assert.NotEqual(t, nonces, again)
+
+	// with a stuck RNG the nonces still depend on the key and the session data
+	stuck := make([]byte, 2*mode)
+	nonces, _, err = GenerateNonces2(context, privateKey, [][]byte{[]byte("session 1")}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	again, _, err = GenerateNonces2(context, privateKey, [][]byte{[]byte("session 2")}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	assert.NotEqual(t, nonces[0], again[0])
+	assert.NotEqual(t, nonces[1], again[1])
+	other, _, err := GenerateNonces2(context, utils.RandomBytes(mode), [][]byte{[]byte("session 1")}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	assert.NotEqual(t, nonces[0], other[0])
+}
@@ -0,0 +1,118 @@
+package aggsig
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+var (
+	syntheticNonceTag = []byte("GOST3410/aggsig/nonce/synthetic")
+)
+
+/*
+DeterministicNonce derives the nonce for signing digest with rawPrivateKey as in
+RFC 6979, section 3.2, with HMAC over the hash algorithm of the context, i.e.
+HMAC-Streebog for GOST contexts. digest is the hash of the message as a
+big-endian integer, the same that sign.Sign takes. extra is optional additional
+entropy (section 3.6); with nil extra the same key and digest always give the
+same nonce.
+
+A deterministic nonce is only safe for a single signer. In a multi-party
+signature the other participants can make the same nonce sign under two
+different challenges, so use SyntheticNonce there.
+*/
+func DeterministicNonce(context *gost3410.Context, rawPrivateKey []byte, digest []byte, extra []byte) (nonce []byte, err error) {
+	privateKey, err := NewPrivateKey(context, rawPrivateKey)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPrivateKey")
+		return
+	}
+
+	k := rfc6979(context, privateKey.Int, digest, extra)
+	return utils.Pad(k.Bytes(), context.Curve.Params().BitSize/8), nil
+}
+
+/*
+SyntheticNonce derives a nonce for multi-party signing from the private key,
+msg, the data of the session (e.g. the public keys of all participants and the
+index of this one) and fresh randomness read from rand. A good rand makes the
+nonce unpredictable even when everything else repeats. With a broken rand the
+nonce only depends on the key, msg and sessionData, and a co-signer who makes
+the same signer run twice on them with a different nonce of its own gets two
+signatures under one nonce and learns the private key. sessionData must
+therefore contain something that is never repeated, such as a session counter
+or identifier, for the nonce to be safe without good randomness. msg is hashed
+with the hash algorithm of the context and may be nil when it is not known yet.
+*/
+func SyntheticNonce(context *gost3410.Context, rawPrivateKey []byte, msg []byte, sessionData [][]byte, rand io.Reader) (nonce []byte, err error) {
+	randomness := make([]byte, context.Curve.Params().BitSize/8)
+	if _, err = io.ReadFull(rand, randomness); err != nil {
+		err = errors.Wrap(err, "cannot read randomness")
+		return
+	}
+
+	// extra = tag || randomness || len(data_1) || data_1 || ...
+	extra := append(append([]byte{}, syntheticNonceTag...), randomness...)
+	for _, data := range sessionData {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+		extra = append(append(extra, length[:]...), data...)
+	}
+	h := context.HashAlgorithm.New()
+	h.Write(msg)
+	return DeterministicNonce(context, rawPrivateKey, h.Sum(nil), extra)
+}
+
+// rfc6979 generates k from the private key x and the message digest h1.
+func rfc6979(context *gost3410.Context, x *big.Int, h1 []byte, extra []byte) *big.Int {
+	mac := hash.NewHMAC(context.HashAlgorithm)
+	q := context.Curve.Params().N
+	qlen := q.BitLen()
+	rlen := (qlen + 7) / 8
+
+	// int2octets(x) || bits2octets(h1)
+	seed := utils.Pad(new(big.Int).Mod(x, q).Bytes(), rlen)
+	seed = append(seed, utils.Pad(new(big.Int).Mod(bits2int(h1, qlen), q).Bytes(), rlen)...)
+	seed = append(seed, extra...)
+
+	size := context.HashAlgorithm.New().Size()
+	v := make([]byte, size)
+	for i := range v {
+		v[i] = 0x01
+	}
+	key := make([]byte, size)
+
+	key = hash.MAC(mac, key, append(append(append([]byte{}, v...), 0x00), seed...))
+	v = hash.MAC(mac, key, v)
+	key = hash.MAC(mac, key, append(append(append([]byte{}, v...), 0x01), seed...))
+	v = hash.MAC(mac, key, v)
+
+	for {
+		var t []byte
+		for len(t) < rlen {
+			v = hash.MAC(mac, key, v)
+			t = append(t, v...)
+		}
+		k := bits2int(t, qlen)
+		if k.Sign() > 0 && k.Cmp(q) < 0 {
+			return k
+		}
+		key = hash.MAC(mac, key, append(append([]byte{}, v...), 0x00))
+		v = hash.MAC(mac, key, v)
+	}
+}
+
+// bits2int takes the qlen leftmost bits of b as a big-endian integer.
+func bits2int(b []byte, qlen int) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if excess := len(b)*8 - qlen; excess > 0 {
+		v.Rsh(v, uint(excess))
+	}
+	return v
+}
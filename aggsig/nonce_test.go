@@ -0,0 +1,120 @@
+package aggsig
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	gohash "hash"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sha512Alg struct{}
+
+func (h *sha512Alg) New() gohash.Hash {
+	return sha512.New()
+}
+
+// Appendix A.2.5 of RFC 6979: ECDSA with P-256.
+func TestDeterministicNonceRFC6979(t *testing.T) {
+	privateKey, _ := hex.DecodeString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	vectors := []struct {
+		ha  gost3410.HashAlgorithm
+		msg string
+		k   string
+	}{
+		{hash.SHA256, "sample", "a6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60"},
+		{hash.SHA256, "test", "d16b6ae827f17175e040871a1c7ec3500192c4c92677336ec2537acaee0008e0"},
+		{&sha512Alg{}, "sample", "5fa81c63109badb88c1f367b47da606da28cad69aa22c4fe6ad7df73a7173aa5"},
+	}
+	for _, v := range vectors {
+		context := gost3410.NewContext(elliptic.P256(), v.ha)
+		h := v.ha.New()
+		h.Write([]byte(v.msg))
+		nonce, err := DeterministicNonce(context, privateKey, h.Sum(nil), nil)
+		require.NoError(t, err)
+		assert.Equal(t, v.k, hex.EncodeToString(nonce), v.msg)
+	}
+}
+
+func TestDeterministicNonce(t *testing.T) {
+	for _, c := range []*curve.CurveParams{curve.GOST34102012256A, curve.GOST34102012512A} {
+		ha := gost3410.HashAlgorithm(hash.GOST34112012256)
+		if c.BitSize == 512 {
+			ha = hash.GOST34112012512
+		}
+		context := gost3410.NewContext(c, ha)
+		mode := c.BitSize / 8
+		privateKey := utils.RandomBytes(mode)
+		msg := []byte("Hello world!")
+		h := ha.New()
+		h.Write(msg)
+		digest := h.Sum(nil)
+
+		nonce, err := DeterministicNonce(context, privateKey, digest, nil)
+		require.NoError(t, err)
+		assert.Len(t, nonce, mode)
+
+		again, err := DeterministicNonce(context, privateKey, digest, nil)
+		require.NoError(t, err)
+		assert.Equal(t, nonce, again)
+
+		other, err := DeterministicNonce(context, privateKey, append(digest[1:], 0), nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, nonce, other)
+
+		other, err = DeterministicNonce(context, privateKey, digest, []byte{0x01})
+		require.NoError(t, err)
+		assert.NotEqual(t, nonce, other)
+
+		// a signature with the nonce verifies
+		publicKey, err := NewPublicKey(context, privateKey)
+		require.NoError(t, err)
+		publicNonce, err := NewPublicKey(context, nonce)
+		require.NoError(t, err)
+		signature, err := SignPartial(context, privateKey, nonce, publicNonce, msg)
+		require.NoError(t, err)
+		correct, err := Verify(context, signature, publicKey, msg)
+		assert.NoError(t, err)
+		assert.True(t, correct, c.Name)
+	}
+}
+
+func TestSyntheticNonce(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	privateKey := utils.RandomBytes(mode)
+	msg := []byte("Hello world!")
+	sessionData := [][]byte{[]byte("alice"), []byte("bob")}
+
+	// fresh randomness gives fresh nonces
+	nonce, err := SyntheticNonce(context, privateKey, msg, sessionData, rand.Reader)
+	require.NoError(t, err)
+	other, err := SyntheticNonce(context, privateKey, msg, sessionData, rand.Reader)
+	require.NoError(t, err)
+	assert.NotEqual(t, nonce, other)
+
+	// with a stuck RNG the nonce still depends on the session data
+	stuck := make([]byte, 4*mode)
+	nonce, err = SyntheticNonce(context, privateKey, msg, sessionData, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	other, err = SyntheticNonce(context, privateKey, msg, [][]byte{[]byte("alice"), []byte("carol")}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	assert.NotEqual(t, nonce, other)
+
+	// the data is length-prefixed, so moving a boundary changes the nonce
+	other, err = SyntheticNonce(context, privateKey, msg, [][]byte{[]byte("alicebob")}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	assert.NotEqual(t, nonce, other)
+
+	_, err = SyntheticNonce(context, privateKey, msg, sessionData, bytes.NewReader(nil))
+	assert.Error(t, err)
+}
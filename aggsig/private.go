@@ -2,6 +2,7 @@ package aggsig
 
 import (
 	"errors"
+	"io"
 	"math/big"
 
 	"github.com/AllFi/go-gost3410"
@@ -29,6 +30,22 @@ func NewPrivateKey(context *gost3410.Context, raw []byte) (privateKey *PrivateKe
 }
 
 func (prv *PrivateKey) PublicKey(context *gost3410.Context) (*PublicKey, error) {
-	x, y := context.Curve.ScalarBaseMult(prv.Bytes())
-	return &PublicKey{&curve.Point{x, y}}, nil
+	return &PublicKey{new(curve.Point).ScalarBaseMultSecret(context.Curve, prv.Int)}, nil
+}
+
+// randomScalar reads a uniform scalar of [1, q) from rand, drawing again rather
+// than reducing mod q, which would bias it on curves where q is much smaller than
+// 2^BitSize.
+func randomScalar(context *gost3410.Context, rand io.Reader) (*big.Int, error) {
+	q := context.Curve.Params().N
+	raw := make([]byte, context.Curve.Params().BitSize/8)
+	for {
+		if _, err := io.ReadFull(rand, raw); err != nil {
+			return nil, err
+		}
+		k := utils.BytesToBigInt(raw)
+		if k.Sign() != 0 && k.Cmp(q) < 0 {
+			return k, nil
+		}
+	}
 }
@@ -19,3 +19,29 @@ func NewPublicKey(context *gost3410.Context, rawPrivateKey []byte) (publicKey *P
 
 	return privateKey.PublicKey(context)
 }
+
+/*
+PublicKeyFromBytes decodes a public key in any of the encodings of
+curve.PointFromBytes. Points off the curve, outside the subgroup of order q or at
+infinity are rejected with the curve.PointError of the failed check.
+*/
+func PublicKeyFromBytes(context *gost3410.Context, raw []byte) (publicKey *PublicKey, err error) {
+	point, err := curve.PointFromBytes(context.Curve, raw)
+	if err != nil {
+		err = errors.Wrap(err, "cannot PointFromBytes")
+		return
+	}
+	return &PublicKey{point}, nil
+}
+
+/*
+PublicKeyFromHex decodes a hex encoded public key like PublicKeyFromBytes.
+*/
+func PublicKeyFromHex(context *gost3410.Context, s string) (publicKey *PublicKey, err error) {
+	point, err := curve.PointFromHex(context.Curve, s)
+	if err != nil {
+		err = errors.Wrap(err, "cannot PointFromHex")
+		return
+	}
+	return &PublicKey{point}, nil
+}
@@ -0,0 +1,376 @@
+package aggsig
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+var (
+	nonceCommitmentTag = []byte("GOST3410/aggsig/session/commitment")
+)
+
+type sessionState int
+
+const (
+	stateCommit sessionState = iota
+	stateCollectCommitments
+	stateReveal
+	stateCollectNonces
+	stateSign
+	stateCollectSignatures
+	stateAggregate
+	stateDone
+)
+
+/*
+Session runs one multi-party signing of msg by the owners of publicKeys. Every
+participant walks through the same steps:
+
+ 1. Commitment, then AddCommitment for every other participant;
+ 2. PublicNonce, then AddPublicNonce for every other participant;
+ 3. SignPartial, then AddPartialSignature for every other participant;
+ 4. Aggregate.
+
+A nonce is only revealed after all the commitments are known, and every revealed
+nonce is checked against its commitment, so no participant can choose its nonce
+as a function of the others. Calls made out of this order fail. The public keys
+are aggregated with AggregatePublicKeys.
+
+A Session is single use and is not safe for concurrent use.
+*/
+type Session struct {
+	context        *gost3410.Context
+	rawPrivateKey  []byte
+	publicKeys     []*PublicKey
+	keyAggregation *KeyAggregation
+	index          int
+	msg            []byte
+	state          sessionState
+
+	nonce             []byte
+	commitments       [][]byte
+	publicNonces      []*PublicKey
+	sumNonces         *PublicKey
+	partialSignatures [][]byte
+}
+
+/*
+NewSession starts a signing session for the participant at index in publicKeys.
+The nonce is a SyntheticNonce with randomness read from rand.
+
+sessionID must be unique for every session started with rawPrivateKey, e.g. a
+counter kept in stable storage or an identifier drawn by the participants. It
+is required: if rand fails and a session is restarted with the same key,
+message and key set, the other participants could otherwise make the same
+nonce sign twice and recover the private key.
+*/
+func NewSession(
+	context *gost3410.Context,
+	rawPrivateKey []byte,
+	publicKeys []*PublicKey,
+	index int,
+	msg []byte,
+	sessionID []byte,
+	rand io.Reader,
+) (
+	session *Session,
+	err error,
+) {
+	if index < 0 || index >= len(publicKeys) {
+		err = errors.New("index out of range")
+		return
+	}
+	if len(sessionID) == 0 {
+		err = errors.New("empty session ID")
+		return
+	}
+
+	publicKey, err := NewPublicKey(context, rawPrivateKey)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPublicKey")
+		return
+	}
+	if !bytes.Equal(publicKey.Bytes(context.Curve), publicKeys[index].Bytes(context.Curve)) {
+		err = errors.New("private key does not match the public key at index")
+		return
+	}
+
+	keyAggregation, err := AggregatePublicKeys(context, publicKeys)
+	if err != nil {
+		err = errors.Wrap(err, "cannot AggregatePublicKeys")
+		return
+	}
+
+	// the nonce is bound to the session, the key, the message and the whole key
+	// set
+	var rawIndex [4]byte
+	binary.BigEndian.PutUint32(rawIndex[:], uint32(index))
+	sessionData := [][]byte{sessionID, rawIndex[:], keyAggregation.PublicKey.Bytes(context.Curve)}
+	for _, publicKey := range publicKeys {
+		sessionData = append(sessionData, publicKey.Bytes(context.Curve))
+	}
+	nonce, err := SyntheticNonce(context, rawPrivateKey, msg, sessionData, rand)
+	if err != nil {
+		err = errors.Wrap(err, "cannot SyntheticNonce")
+		return
+	}
+
+	n := len(publicKeys)
+	return &Session{
+		context:           context,
+		rawPrivateKey:     rawPrivateKey,
+		publicKeys:        publicKeys,
+		keyAggregation:    keyAggregation,
+		index:             index,
+		msg:               msg,
+		nonce:             nonce,
+		commitments:       make([][]byte, n),
+		publicNonces:      make([]*PublicKey, n),
+		partialSignatures: make([][]byte, n),
+	}, nil
+}
+
+/*
+PublicKey returns the aggregated public key the session signs for.
+*/
+func (s *Session) PublicKey() *PublicKey {
+	return s.keyAggregation.PublicKey
+}
+
+/*
+Commitment returns the commitment to the nonce of this participant, to be sent to
+every other participant.
+*/
+func (s *Session) Commitment() (commitment []byte, err error) {
+	if s.state != stateCommit {
+		err = errors.New("Commitment called out of order")
+		return
+	}
+
+	publicNonce, err := NewPublicKey(s.context, s.nonce)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPublicKey")
+		return
+	}
+	s.publicNonces[s.index] = publicNonce
+	s.commitments[s.index] = s.commit(s.index, publicNonce)
+	s.state = stateCollectCommitments
+	s.advance()
+	return s.commitments[s.index], nil
+}
+
+/*
+AddCommitment records the nonce commitment of the participant at index.
+*/
+func (s *Session) AddCommitment(index int, commitment []byte) error {
+	if s.state != stateCollectCommitments {
+		return errors.New("AddCommitment called out of order")
+	}
+	if err := s.checkIndex(index); err != nil {
+		return err
+	}
+	if s.commitments[index] != nil {
+		return errors.Errorf("commitment of participant %d is already known", index)
+	}
+
+	s.commitments[index] = append([]byte{}, commitment...)
+	s.advance()
+	return nil
+}
+
+/*
+PublicNonce reveals the public nonce of this participant. It may only be called
+once the commitments of all participants are known.
+*/
+func (s *Session) PublicNonce() (publicNonce *PublicKey, err error) {
+	if s.state != stateReveal {
+		err = errors.New("PublicNonce called out of order")
+		return
+	}
+
+	s.state = stateCollectNonces
+	s.advance()
+	return s.publicNonces[s.index], nil
+}
+
+/*
+AddPublicNonce records the public nonce of the participant at index after
+checking it against the commitment of that participant.
+*/
+func (s *Session) AddPublicNonce(index int, publicNonce *PublicKey) error {
+	if s.state != stateCollectNonces {
+		return errors.New("AddPublicNonce called out of order")
+	}
+	if err := s.checkIndex(index); err != nil {
+		return err
+	}
+	if s.publicNonces[index] != nil {
+		return errors.Errorf("public nonce of participant %d is already known", index)
+	}
+	if publicNonce == nil || publicNonce.IsZero() ||
+		!publicNonce.IsOnCurve(s.context.Curve) {
+		return errors.Errorf("invalid public nonce of participant %d", index)
+	}
+	if subtle.ConstantTimeCompare(s.commit(index, publicNonce), s.commitments[index]) != 1 {
+		return errors.Errorf("public nonce of participant %d does not match its commitment", index)
+	}
+
+	s.publicNonces[index] = publicNonce
+	s.advance()
+	return nil
+}
+
+/*
+SignPartial returns the partial signature of this participant. It may only be
+called once the public nonces of all participants are known, and only once per
+session: the nonce is erased afterwards.
+*/
+func (s *Session) SignPartial() (partialSignature []byte, err error) {
+	if s.state != stateSign {
+		err = errors.New("SignPartial called out of order")
+		return
+	}
+
+	sumNonces, err := SumPublicKeys(s.context, s.publicNonces)
+	if err != nil {
+		err = errors.Wrap(err, "cannot SumPublicKeys")
+		return
+	}
+	if sumNonces.IsZero() {
+		err = errors.New("sum of public nonces is the point at infinity")
+		return
+	}
+
+	partialSignature, err = SignPartial(s.context, s.rawPrivateKey, s.nonce, sumNonces, s.msg, WithKeyAggregation(s.keyAggregation), WithEvenNonce())
+	for i := range s.nonce {
+		s.nonce[i] = 0
+	}
+	if err != nil {
+		err = errors.Wrap(err, "cannot SignPartial")
+		s.state = stateDone
+		return
+	}
+
+	s.sumNonces = sumNonces
+	s.partialSignatures[s.index] = partialSignature
+	s.state = stateCollectSignatures
+	s.advance()
+	return
+}
+
+/*
+AddPartialSignature verifies and records the partial signature of the
+participant at index.
+*/
+func (s *Session) AddPartialSignature(index int, partialSignature []byte) error {
+	if s.state != stateCollectSignatures {
+		return errors.New("AddPartialSignature called out of order")
+	}
+	if err := s.checkIndex(index); err != nil {
+		return err
+	}
+	if s.partialSignatures[index] != nil {
+		return errors.Errorf("partial signature of participant %d is already known", index)
+	}
+
+	correct, err := s.verifyPartial(index, partialSignature)
+	if err != nil {
+		return errors.Wrapf(err, "cannot verify partial signature of participant %d", index)
+	}
+	if !correct {
+		return errors.Errorf("invalid partial signature of participant %d", index)
+	}
+
+	s.partialSignatures[index] = append([]byte{}, partialSignature...)
+	s.advance()
+	return nil
+}
+
+func (s *Session) verifyPartial(index int, partialSignature []byte) (bool, error) {
+	mode := s.context.Curve.Params().BitSize / 8
+	if len(partialSignature) != 2*mode {
+		return false, errors.New("wrong signature length")
+	}
+
+	// the partial signature must carry the r of the session
+	r := new(big.Int).Mod(s.sumNonces.X, s.context.Curve.Params().N)
+	if utils.BytesToBigInt(partialSignature[mode:]).Cmp(r) != 0 {
+		return false, nil
+	}
+	return VerifyPartial(s.context, partialSignature, s.publicKeys[index], s.publicNonces[index], s.msg, WithKeyAggregation(s.keyAggregation))
+}
+
+/*
+Aggregate returns the signature under PublicKey. It may only be called once the
+partial signatures of all participants are known.
+*/
+func (s *Session) Aggregate() (signature []byte, err error) {
+	if s.state != stateAggregate {
+		err = errors.New("Aggregate called out of order")
+		return
+	}
+
+	signature, err = AggregatePartialSignatures(s.context, s.partialSignatures, s.sumNonces)
+	if err != nil {
+		err = errors.Wrap(err, "cannot AggregatePartialSignatures")
+		return
+	}
+	s.state = stateDone
+	return
+}
+
+// commit returns H(tag || index || publicNonce || msg).
+func (s *Session) commit(index int, publicNonce *PublicKey) []byte {
+	h := s.context.HashAlgorithm.New()
+	h.Write(nonceCommitmentTag)
+	var rawIndex [4]byte
+	binary.BigEndian.PutUint32(rawIndex[:], uint32(index))
+	h.Write(rawIndex[:])
+	h.Write(publicNonce.Bytes(s.context.Curve))
+	h.Write(s.msg)
+	return h.Sum(nil)
+}
+
+func (s *Session) checkIndex(index int) error {
+	if index < 0 || index >= len(s.publicKeys) {
+		return errors.New("index out of range")
+	}
+	if index == s.index {
+		return errors.New("index of this participant")
+	}
+	return nil
+}
+
+// advance moves a collecting state forward once everything has been collected.
+func (s *Session) advance() {
+	var collected bool
+	switch s.state {
+	case stateCollectCommitments:
+		collected = all(len(s.commitments), func(i int) bool { return s.commitments[i] != nil })
+	case stateCollectNonces:
+		collected = all(len(s.publicNonces), func(i int) bool { return s.publicNonces[i] != nil })
+	case stateCollectSignatures:
+		collected = all(len(s.partialSignatures), func(i int) bool { return s.partialSignatures[i] != nil })
+	default:
+		return
+	}
+	if collected {
+		s.state++
+	}
+}
+
+func all(n int, f func(i int) bool) bool {
+	for i := 0; i < n; i++ {
+		if !f(i) {
+			return false
+		}
+	}
+	return true
+}
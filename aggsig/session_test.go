@@ -0,0 +1,197 @@
+package aggsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessions(t *testing.T, context *gost3410.Context, n int, msg []byte) []*Session {
+	mode := context.Curve.Params().BitSize / 8
+
+	privateKeys := make([][]byte, n)
+	publicKeys := make([]*PublicKey, n)
+	for i := 0; i < n; i++ {
+		privateKeys[i] = utils.RandomBytes(mode)
+		publicKey, err := NewPublicKey(context, privateKeys[i])
+		require.NoError(t, err)
+		publicKeys[i] = publicKey
+	}
+
+	sessionID := utils.RandomBytes(16)
+	sessions := make([]*Session, n)
+	for i := 0; i < n; i++ {
+		session, err := NewSession(context, privateKeys[i], publicKeys, i, msg, sessionID, rand.Reader)
+		require.NoError(t, err)
+		sessions[i] = session
+	}
+	return sessions
+}
+
+func TestSession(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	n := 3
+	msg := []byte("Hello world!")
+	sessions := newSessions(t, context, n, msg)
+
+	commitments := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		commitment, err := sessions[i].Commitment()
+		require.NoError(t, err)
+		commitments[i] = commitment
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				require.NoError(t, sessions[i].AddCommitment(j, commitments[j]))
+			}
+		}
+	}
+
+	publicNonces := make([]*PublicKey, n)
+	for i := 0; i < n; i++ {
+		publicNonce, err := sessions[i].PublicNonce()
+		require.NoError(t, err)
+		publicNonces[i] = publicNonce
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				require.NoError(t, sessions[i].AddPublicNonce(j, publicNonces[j]))
+			}
+		}
+	}
+
+	partialSignatures := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		partialSignature, err := sessions[i].SignPartial()
+		require.NoError(t, err)
+		partialSignatures[i] = partialSignature
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				require.NoError(t, sessions[i].AddPartialSignature(j, partialSignatures[j]))
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		signature, err := sessions[i].Aggregate()
+		require.NoError(t, err)
+
+		correct, err := Verify(context, signature, sessions[i].PublicKey(), msg)
+		assert.NoError(t, err)
+		assert.True(t, correct)
+	}
+
+	// a session cannot be reused
+	_, err := sessions[0].SignPartial()
+	assert.Error(t, err)
+	_, err = sessions[0].Aggregate()
+	assert.Error(t, err)
+}
+
+func TestSessionOutOfOrder(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	sessions := newSessions(t, context, 2, []byte("Hello world!"))
+
+	// nothing but the commitment can come first
+	_, err := sessions[0].PublicNonce()
+	assert.Error(t, err)
+	_, err = sessions[0].SignPartial()
+	assert.Error(t, err)
+	_, err = sessions[0].Aggregate()
+	assert.Error(t, err)
+	assert.Error(t, sessions[0].AddCommitment(1, make([]byte, 32)))
+
+	commitment0, err := sessions[0].Commitment()
+	require.NoError(t, err)
+	commitment1, err := sessions[1].Commitment()
+	require.NoError(t, err)
+
+	// the nonce is not revealed before all the commitments are known
+	_, err = sessions[0].PublicNonce()
+	assert.Error(t, err)
+
+	assert.Error(t, sessions[0].AddCommitment(0, commitment0))
+	assert.Error(t, sessions[0].AddCommitment(2, commitment1))
+	require.NoError(t, sessions[0].AddCommitment(1, commitment1))
+	require.NoError(t, sessions[1].AddCommitment(0, commitment0))
+	assert.Error(t, sessions[0].AddCommitment(1, commitment1))
+
+	_, err = sessions[0].PublicNonce()
+	require.NoError(t, err)
+	_, err = sessions[0].SignPartial()
+	assert.Error(t, err)
+
+	// a nonce that does not match its commitment is rejected
+	mode := context.Curve.Params().BitSize / 8
+	other, err := NewPublicKey(context, utils.RandomBytes(mode))
+	require.NoError(t, err)
+	assert.Error(t, sessions[1].AddPublicNonce(0, other))
+}
+
+func TestSessionInvalidPartialSignature(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	sessions := newSessions(t, context, 2, []byte("Hello world!"))
+
+	commitment0, err := sessions[0].Commitment()
+	require.NoError(t, err)
+	commitment1, err := sessions[1].Commitment()
+	require.NoError(t, err)
+	require.NoError(t, sessions[0].AddCommitment(1, commitment1))
+	require.NoError(t, sessions[1].AddCommitment(0, commitment0))
+
+	publicNonce0, err := sessions[0].PublicNonce()
+	require.NoError(t, err)
+	publicNonce1, err := sessions[1].PublicNonce()
+	require.NoError(t, err)
+	require.NoError(t, sessions[0].AddPublicNonce(1, publicNonce1))
+	require.NoError(t, sessions[1].AddPublicNonce(0, publicNonce0))
+
+	partialSignature0, err := sessions[0].SignPartial()
+	require.NoError(t, err)
+	_, err = sessions[1].SignPartial()
+	require.NoError(t, err)
+
+	tampered := append([]byte{}, partialSignature0...)
+	tampered[0] ^= 0x01
+	assert.Error(t, sessions[1].AddPartialSignature(0, tampered))
+	_, err = sessions[1].Aggregate()
+	assert.Error(t, err)
+
+	require.NoError(t, sessions[1].AddPartialSignature(0, partialSignature0))
+	_, err = sessions[1].Aggregate()
+	assert.NoError(t, err)
+}
+
+func TestSessionID(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	privateKey := utils.RandomBytes(mode)
+	publicKey, err := NewPublicKey(context, privateKey)
+	require.NoError(t, err)
+	other, err := NewPublicKey(context, utils.RandomBytes(mode))
+	require.NoError(t, err)
+	publicKeys := []*PublicKey{publicKey, other}
+	msg := []byte("Hello world!")
+
+	_, err = NewSession(context, privateKey, publicKeys, 0, msg, nil, rand.Reader)
+	assert.Error(t, err)
+
+	// with a stuck rand only the session ID keeps restarted sessions apart
+	stuck := make([]byte, mode)
+	first, err := NewSession(context, privateKey, publicKeys, 0, msg, []byte{1}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	second, err := NewSession(context, privateKey, publicKeys, 0, msg, []byte{2}, bytes.NewReader(stuck))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.nonce, second.nonce)
+}
@@ -0,0 +1,184 @@
+package aggsig
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+Share is a Shamir share f(Index) of a private key d = f(0), where f is a random
+polynomial of degree t - 1 over Z_q. Any t shares determine d.
+*/
+type Share struct {
+	// Index is the evaluation point of the share, starting from 1.
+	Index int
+	// PrivateKey is f(Index), encoded like a private key.
+	PrivateKey []byte
+}
+
+/*
+PublicKey returns f(Index)*P, the public key of the share.
+*/
+func (share *Share) PublicKey(context *gost3410.Context) (*PublicKey, error) {
+	return NewPublicKey(context, share.PrivateKey)
+}
+
+/*
+SplitPrivateKey splits a private key into n shares so that any t of them can sign
+on its behalf. The coefficients of the polynomial are read from rand.
+*/
+func SplitPrivateKey(context *gost3410.Context, rawPrivateKey []byte, t, n int, rand io.Reader) (shares []*Share, err error) {
+	privateKey, err := NewPrivateKey(context, rawPrivateKey)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPrivateKey")
+		return
+	}
+	if t < 1 || t > n {
+		err = errors.New("threshold must be between 1 and n")
+		return
+	}
+
+	q := context.Curve.Params().N
+	coefficients := make([]*big.Int, t)
+	coefficients[0] = new(big.Int).Mod(privateKey.Int, q)
+	for j := 1; j < t; j++ {
+		if coefficients[j], err = randomScalar(context, rand); err != nil {
+			return nil, errors.Wrap(err, "cannot randomScalar")
+		}
+	}
+
+	return EvaluateShares(context, coefficients, n)
+}
+
+/*
+EvaluateShares evaluates the polynomial with the given coefficients, constant
+term first, at 1, ..., n.
+*/
+func EvaluateShares(context *gost3410.Context, coefficients []*big.Int, n int) (shares []*Share, err error) {
+	mode := context.Curve.Params().BitSize / 8
+	shares = make([]*Share, n)
+	for i := 1; i <= n; i++ {
+		value := EvaluatePolynomial(context, coefficients, big.NewInt(int64(i)))
+		if value.Cmp(zero) == 0 {
+			err = errors.Errorf("share %d is zero", i)
+			return
+		}
+		shares[i-1] = &Share{Index: i, PrivateKey: utils.Pad(value.Bytes(), mode)}
+	}
+	return
+}
+
+/*
+EvaluatePolynomial returns f(x) mod q, where coefficients are the coefficients of
+f with the constant term first.
+*/
+func EvaluatePolynomial(context *gost3410.Context, coefficients []*big.Int, x *big.Int) *big.Int {
+	q := context.Curve.Params().N
+	// Horner's rule
+	y := new(big.Int)
+	for j := len(coefficients) - 1; j >= 0; j-- {
+		y.Mul(y, x)
+		y.Add(y, coefficients[j])
+		y.Mod(y, q)
+	}
+	return y
+}
+
+/*
+LagrangeCoefficient returns the Lagrange coefficient of index at zero with
+respect to indices, lambda = prod(j / (j - index)) mod q over j in indices,
+j != index.
+*/
+func LagrangeCoefficient(context *gost3410.Context, index int, indices []int) (*big.Int, error) {
+	q := context.Curve.Params().N
+	if err := checkIndices(context, indices); err != nil {
+		return nil, err
+	}
+
+	found := false
+	num, den := big.NewInt(1), big.NewInt(1)
+	for _, j := range indices {
+		if j == index {
+			found = true
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j)))
+		num.Mod(num, q)
+		den.Mul(den, big.NewInt(int64(j-index)))
+		den.Mod(den, q)
+	}
+	if !found {
+		return nil, errors.Errorf("index %d is not one of the indices", index)
+	}
+
+	lambda := new(big.Int).ModInverse(den, q)
+	lambda.Mul(lambda, num)
+	return lambda.Mod(lambda, q), nil
+}
+
+func checkIndices(context *gost3410.Context, indices []int) error {
+	q := context.Curve.Params().N
+	seen := make(map[int]bool, len(indices))
+	for _, j := range indices {
+		if j <= 0 || big.NewInt(int64(j)).Cmp(q) >= 0 {
+			return errors.Errorf("invalid index %d", j)
+		}
+		if seen[j] {
+			return errors.Errorf("duplicate index %d", j)
+		}
+		seen[j] = true
+	}
+	return nil
+}
+
+/*
+WithSigners makes SignPartial and VerifyPartial weight the share with index by
+its Lagrange coefficient with respect to signers, the indices of all the shares
+taking part in the signature. The partial signatures of signers then add up to a
+signature under the group public key.
+*/
+func WithSigners(index int, signers []int) Option {
+	return func(o *options) {
+		o.threshold = &thresholdOptions{index: index, signers: signers}
+	}
+}
+
+type thresholdOptions struct {
+	index   int
+	signers []int
+}
+
+func (o *thresholdOptions) lagrangeCoefficient(context *gost3410.Context) (*big.Int, error) {
+	return LagrangeCoefficient(context, o.index, o.signers)
+}
+
+/*
+InterpolatePublicKey returns the group public key f(0)*P from the public keys of
+the shares with the given indices. At least t shares are required for the result
+to be the group public key.
+*/
+func InterpolatePublicKey(context *gost3410.Context, indices []int, publicKeys []*PublicKey) (publicKey *PublicKey, err error) {
+	if len(indices) != len(publicKeys) || len(indices) == 0 {
+		err = errors.New("indices and public keys must be non-empty and of the same length")
+		return
+	}
+
+	sum := new(curve.Point).SetInfinity()
+	for i, index := range indices {
+		lambda, err := LagrangeCoefficient(context, index, indices)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot LagrangeCoefficient")
+		}
+		sum.Add(context.Curve, sum, new(curve.Point).ScalarMult(context.Curve, publicKeys[i].Point, lambda))
+	}
+	if sum.IsZero() {
+		err = errors.New("group public key is the point at infinity")
+		return
+	}
+	return &PublicKey{sum}, nil
+}
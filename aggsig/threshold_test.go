@@ -0,0 +1,121 @@
+package aggsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreshold(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	threshold, n := 3, 5
+
+	privateKey := utils.RandomBytes(mode)
+	groupPublicKey, err := NewPublicKey(context, privateKey)
+	require.NoError(t, err)
+
+	shares, err := SplitPrivateKey(context, privateKey, threshold, n, rand.Reader)
+	require.NoError(t, err)
+	require.Len(t, shares, n)
+
+	sharePublicKeys := make([]*PublicKey, n)
+	for i := 0; i < n; i++ {
+		sharePublicKeys[i], err = shares[i].PublicKey(context)
+		require.NoError(t, err)
+	}
+
+	msg := []byte("Hello world!")
+	for _, signers := range [][]int{{1, 2, 3}, {2, 4, 5}, {5, 1, 3, 4}} {
+		nonces := make([][]byte, len(signers))
+		publicNonces := make([]*PublicKey, len(signers))
+		publicKeys := make([]*PublicKey, len(signers))
+		for i, index := range signers {
+			nonces[i] = utils.RandomBytes(mode)
+			publicNonces[i], err = NewPublicKey(context, nonces[i])
+			require.NoError(t, err)
+			publicKeys[i] = sharePublicKeys[index-1]
+		}
+
+		interpolated, err := InterpolatePublicKey(context, signers, publicKeys)
+		require.NoError(t, err)
+		assert.Equal(t, groupPublicKey.Hex(context.Curve), interpolated.Hex(context.Curve))
+
+		sumPublicNonces, err := SumPublicKeys(context, publicNonces)
+		require.NoError(t, err)
+
+		partialSignatures := make([][]byte, len(signers))
+		for i, index := range signers {
+			partialSignatures[i], err = SignPartial(context, shares[index-1].PrivateKey, nonces[i], sumPublicNonces, msg, WithSigners(index, signers))
+			require.NoError(t, err)
+
+			correct, err := VerifyPartial(context, partialSignatures[i], publicKeys[i], publicNonces[i], msg, WithSigners(index, signers))
+			assert.NoError(t, err)
+			assert.True(t, correct)
+		}
+
+		signature, err := AggregatePartialSignatures(context, partialSignatures, sumPublicNonces)
+		require.NoError(t, err)
+
+		correct, err := Verify(context, signature, groupPublicKey, msg)
+		assert.NoError(t, err)
+		assert.True(t, correct, "signers %v", signers)
+	}
+
+	// fewer than t shares do not give the group public key
+	interpolated, err := InterpolatePublicKey(context, []int{1, 2}, sharePublicKeys[:2])
+	require.NoError(t, err)
+	assert.NotEqual(t, groupPublicKey.Hex(context.Curve), interpolated.Hex(context.Curve))
+}
+
+func TestThresholdErrors(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	mode := context.Curve.Params().BitSize / 8
+	privateKey := utils.RandomBytes(mode)
+
+	_, err := SplitPrivateKey(context, privateKey, 0, 3, rand.Reader)
+	assert.Error(t, err)
+	_, err = SplitPrivateKey(context, privateKey, 4, 3, rand.Reader)
+	assert.Error(t, err)
+
+	_, err = SplitPrivateKey(context, privateKey, 2, 3, bytes.NewReader(nil))
+	assert.Error(t, err)
+
+	_, err = LagrangeCoefficient(context, 1, []int{1, 1, 2})
+	assert.Error(t, err)
+	_, err = LagrangeCoefficient(context, 3, []int{1, 2})
+	assert.Error(t, err)
+	_, err = LagrangeCoefficient(context, 0, []int{0, 1})
+	assert.Error(t, err)
+
+	publicNonce, err := NewPublicKey(context, utils.RandomBytes(mode))
+	require.NoError(t, err)
+	_, err = SignPartial(context, privateKey, utils.RandomBytes(mode), publicNonce, []byte("Hello world!"), WithSigners(3, []int{1, 2}))
+	assert.Error(t, err)
+}
+
+func TestSplitPrivateKeyUniform(t *testing.T) {
+	// 2^256/q is about 1.6 on CryptoPro-C, so reducing mod q would bias the
+	// coefficients
+	c := curve.GOST34102001CryptoProC
+	context := gost3410.NewContext(c, hash.GOST34112012256)
+	mode := c.BitSize / 8
+	privateKey := utils.Pad(big.NewInt(11).Bytes(), mode)
+
+	// q + 5 is drawn again instead of being taken as 5: f(x) = 11 + 7x
+	rejected := utils.Pad(new(big.Int).Add(c.N, big.NewInt(5)).Bytes(), mode)
+	coefficient := utils.Pad(big.NewInt(7).Bytes(), mode)
+	shares, err := SplitPrivateKey(context, privateKey, 2, 3, bytes.NewReader(append(rejected, coefficient...)))
+	require.NoError(t, err)
+	for i, share := range shares {
+		assert.Equal(t, int64(11+7*(i+1)), utils.BytesToBigInt(share.PrivateKey).Int64())
+	}
+}
@@ -19,21 +19,21 @@ package bulletproofs
 
 import (
 	"crypto/elliptic"
-	"errors"
 	"math/big"
-	"strconv"
 
 	"github.com/AllFi/go-gost3410"
 	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/transcript"
 	"github.com/ing-bank/zkrp/util/bn"
-	"github.com/ing-bank/zkrp/util/byteconversion"
+	"github.com/pkg/errors"
 )
 
 var SEEDU = "BulletproofsDoesNotNeedTrustedSetupU"
 
 /*
 InnerProductParams contains elliptic curve generators used to compute Pedersen
-commitments.
+commitments, together with the statement of the proof: the commitment P to a
+and b and their inner product Cc.
 */
 type InnerProductParams struct {
 	N  int64
@@ -66,9 +66,6 @@ SetupInnerProduct is responsible for computing the inner product basic parameter
 ProveInnerProduct and Verify algorithms.
 */
 func setupInnerProduct(context *gost3410.Context, H *curve.Point, g, h []*curve.Point, c *big.Int, N int64) (InnerProductParams, error) {
-	ec := context.Curve
-	ha := context.HashAlgorithm
-
 	var params InnerProductParams
 
 	if N <= 0 {
@@ -76,29 +73,24 @@ func setupInnerProduct(context *gost3410.Context, H *curve.Point, g, h []*curve.
 	} else {
 		params.N = N
 	}
+	gens := loadGenerators(context, params.N)
 	if H == nil {
-		params.H, _ = curve.MapToGroup(ec, ha, SEEDH)
+		params.H = gens.H.Point()
 	} else {
 		params.H = H
 	}
 	if g == nil {
-		params.Gg = make([]*curve.Point, params.N)
-		for i := int64(0); i < params.N; i++ {
-			params.Gg[i], _ = curve.MapToGroup(ec, ha, SEEDH+"g"+strconv.Itoa(int(i)))
-		}
+		params.Gg = gens.Gg.Points()[:params.N]
 	} else {
 		params.Gg = g
 	}
 	if h == nil {
-		params.Hh = make([]*curve.Point, params.N)
-		for i := int64(0); i < params.N; i++ {
-			params.Hh[i], _ = curve.MapToGroup(ec, ha, SEEDH+"h"+strconv.Itoa(int(i)))
-		}
+		params.Hh = gens.Hh.Points()[:params.N]
 	} else {
 		params.Hh = h
 	}
 	params.Cc = c
-	params.Uu, _ = curve.MapToGroup(ec, ha, SEEDU)
+	params.Uu = gens.U.Point()
 	params.P = new(curve.Point).SetInfinity()
 
 	return params, nil
@@ -106,8 +98,10 @@ func setupInnerProduct(context *gost3410.Context, H *curve.Point, g, h []*curve.
 
 /*
 proveInnerProduct calculates the Zero Knowledge Proof for the Inner Product argument.
+The challenges are drawn from t after appending P, c and every L and R, so t
+must already hold the generators unless they are implied by its history.
 */
-func proveInnerProduct(context *gost3410.Context, a, b []*big.Int, P *curve.Point, params InnerProductParams) (InnerProductProof, error) {
+func proveInnerProduct(context *gost3410.Context, t *transcript.Transcript, a, b []*big.Int, P *curve.Point, params InnerProductParams) (InnerProductProof, error) {
 	ec := context.Curve
 
 	var (
@@ -125,25 +119,24 @@ func proveInnerProduct(context *gost3410.Context, a, b []*big.Int, P *curve.Poin
 	}
 
 	// Fiat-Shamir:
-	// x = Hash(g,h,P,c)
-	x, _ := hashIP(context, params.Gg, params.Hh, P, params.Cc, params.N)
+	// x = Hash(transcript,n,P,c)
+	x := appendInnerProductStatement(t, n, P, params.Cc)
 	// Pprime = P.u^(x.c)
 	ux := new(curve.Point).ScalarMult(ec, params.Uu, x)
 	uxc := new(curve.Point).ScalarMult(ec, ux, params.Cc)
 	PP := new(curve.Point).Add(ec, P, uxc)
 	// Execute Protocol 2 recursively
-	proof = computeBipRecursive(context, a, b, params.Gg, params.Hh, ux, PP, n, Ls, Rs)
+	proof = computeBipRecursive(context, t, a, b, params.Gg, params.Hh, ux, PP, n, Ls, Rs)
 	proof.Params = params
-	proof.Params.P = PP
+	proof.Params.P = P
 	return proof, nil
 }
 
 /*
 computeBipRecursive is the main recursive function that will be used to compute the inner product argument.
 */
-func computeBipRecursive(context *gost3410.Context, a, b []*big.Int, g, h []*curve.Point, u, P *curve.Point, n int64, Ls, Rs []*curve.Point) InnerProductProof {
+func computeBipRecursive(context *gost3410.Context, t *transcript.Transcript, a, b []*big.Int, g, h []*curve.Point, u, P *curve.Point, n int64, Ls, Rs []*curve.Point) InnerProductProof {
 	ec := context.Curve
-	ha := context.HashAlgorithm
 
 	var (
 		proof                            InnerProductProof
@@ -188,7 +181,9 @@ func computeBipRecursive(context *gost3410.Context, a, b []*big.Int, g, h []*cur
 		R.Add(ec, R, new(curve.Point).ScalarMult(ec, u, cR))
 
 		// Fiat-Shamir:                                                       // (26)
-		x, _, _ = HashBP(ha, L, R)
+		t.AppendPoint("L", L)
+		t.AppendPoint("R", R)
+		x = t.ChallengeScalar("x")
 		xinv = bn.ModInverse(x, order)
 
 		// Compute g' = g[:n']^(x^-1) * g[n':]^(x)                            // (29)
@@ -219,87 +214,92 @@ func computeBipRecursive(context *gost3410.Context, a, b []*big.Int, g, h []*cur
 		Ls = append(Ls, L)
 		Rs = append(Rs, R)
 		// recursion computeBipRecursive(g',h',u,P'; a', b')                  // (35)
-		proof = computeBipRecursive(context, aprime, bprime, gprime, hprime, u, Pprime, nprime, Ls, Rs)
+		proof = computeBipRecursive(context, t, aprime, bprime, gprime, hprime, u, Pprime, nprime, Ls, Rs)
 	}
 	proof.N = n
 	return proof
 }
 
 /*
-Verify is responsible for the verification of the Inner Product Proof.
+Verify is responsible for the verification of the Inner Product Proof. t must be
+in the state the prover's transcript was in when proveInnerProduct was called;
+the statement is taken from proof.Params.
 */
-func (proof InnerProductProof) Verify(context *gost3410.Context) (bool, error) {
+func (proof InnerProductProof) Verify(context *gost3410.Context, t *transcript.Transcript) (bool, error) {
+	if err := proof.Validate(context); err != nil {
+		return false, errors.Wrap(err, "invalid inner product proof")
+	}
+	if proof.Params.Cc == nil {
+		return false, errors.New("invalid inner product proof: missing inner product")
+	}
 	ec := context.Curve
-	ha := context.HashAlgorithm
 	order := ec.Params().N
 
+	// P' = P.u^(x.c)
+	x := appendInnerProductStatement(t, proof.N, proof.Params.P, proof.Params.Cc)
+	u := loadGenerators(context, proof.N).U.ScalarMult(x)
+
+	// Instead of folding the generators round by round, check
+	// g^(a.s).h^(b.s^-1).u^(a.b) = P'.L^(x^2).R^(x^-2) with a single multi-scalar
+	// multiplication, where s_i is the product of the x^(+-1) that round j
+	// applies to g_i: x_j for the upper half, x_j^-1 for the lower one.
 	logn := len(proof.Ls)
-	var (
-		x, xinv, x2, x2inv                   *big.Int
-		ngprime, nhprime, ngprime2, nhprime2 []*curve.Point
-	)
+	n := proof.N
+	xs := make([]*big.Int, logn)
+	xinvs := make([]*big.Int, logn)
+	points := make([]*curve.Point, 0, 2*n+int64(2*logn)+2)
+	scalars := make([]*big.Int, 0, cap(points))
+	for j := 0; j < logn; j++ {
+		t.AppendPoint("L", proof.Ls[j])
+		t.AppendPoint("R", proof.Rs[j])
+		x := t.ChallengeScalar("x") // (26)
+		xs[j] = x
+		xinvs[j] = bn.ModInverse(x, order)
+
+		// P' = L^(x^2).P.R^(x^-2)                                             // (31)
+		x2 := bn.Mod(bn.Multiply(x, x), order)
+		points = append(points, proof.Ls[j], proof.Rs[j])
+		scalars = append(scalars, bn.Sub(order, x2), bn.Sub(order, bn.ModInverse(x2, order)))
+	}
 
-	gprime := proof.Params.Gg
-	hprime := proof.Params.Hh
-	Pprime := proof.Params.P
-	nprime := proof.N
-	for i := int64(0); i < int64(logn); i++ {
-		nprime = nprime / 2                            // (20)
-		x, _, _ = HashBP(ha, proof.Ls[i], proof.Rs[i]) // (26)
-		xinv = bn.ModInverse(x, order)
-		// Compute g' = g[:n']^(x^-1) * g[n':]^(x)                            // (29)
-		ngprime = vectorScalarExp(ec, gprime[:nprime], xinv)
-		ngprime2 = vectorScalarExp(ec, gprime[nprime:], x)
-		gprime, _ = VectorECAdd(ec, ngprime, ngprime2)
-		// Compute h' = h[:n']^(x)    * h[n':]^(x^-1)                         // (30)
-		nhprime = vectorScalarExp(ec, hprime[:nprime], x)
-		nhprime2 = vectorScalarExp(ec, hprime[nprime:], xinv)
-		hprime, _ = VectorECAdd(ec, nhprime, nhprime2)
-		// Compute P' = L^(x^2).P.R^(x^-2)                                    // (31)
-		x2 = bn.Mod(bn.Multiply(x, x), order)
-		x2inv = bn.ModInverse(x2, order)
-		Pprime.Add(ec, Pprime, new(curve.Point).ScalarMult(ec, proof.Ls[i], x2))
-		Pprime.Add(ec, Pprime, new(curve.Point).ScalarMult(ec, proof.Rs[i], x2inv))
+	// g' = g[:n']^(x^-1) * g[n':]^(x), h' = h[:n']^(x) * h[n':]^(x^-1)          // (29), (30)
+	for i := int64(0); i < n; i++ {
+		si := big.NewInt(1)
+		siinv := big.NewInt(1)
+		for j := 0; j < logn; j++ {
+			if i&(1<<uint(logn-1-j)) != 0 {
+				si = bn.Mod(bn.Multiply(si, xs[j]), order)
+				siinv = bn.Mod(bn.Multiply(siinv, xinvs[j]), order)
+			} else {
+				si = bn.Mod(bn.Multiply(si, xinvs[j]), order)
+				siinv = bn.Mod(bn.Multiply(siinv, xs[j]), order)
+			}
+		}
+		points = append(points, proof.Params.Gg[i], proof.Params.Hh[i])
+		scalars = append(scalars, bn.Mod(bn.Multiply(proof.A, si), order), bn.Mod(bn.Multiply(proof.B, siinv), order))
 	}
 
-	// c == a*b and checks if P = g^a.h^b.u^c                                     // (16)
-	ab := bn.Multiply(proof.A, proof.B)
-	ab = bn.Mod(ab, order)
-	// Compute right hand side
-	rhs := new(curve.Point).ScalarMult(ec, gprime[0], proof.A)
-	hb := new(curve.Point).ScalarMult(ec, hprime[0], proof.B)
-	rhs.Add(ec, rhs, hb)
-	rhs.Add(ec, rhs, new(curve.Point).ScalarMult(ec, proof.U, ab))
-	// Compute inverse of left hand side
-	nP := Pprime.Neg(ec, Pprime)
-	nP.Add(ec, nP, rhs)
-	// If both sides are equal then nP must be zero                               // (17)
-	c := nP.IsZero()
+	// c == a*b and checks if P' = g^a.h^b.u^c                                    // (16)
+	abc := bn.Sub(bn.Multiply(proof.A, proof.B), proof.Params.Cc)
+	abc = bn.Mod(abc, order)
+	points = append(points, u, proof.Params.P)
+	scalars = append(scalars, abc, big.NewInt(-1))
+
+	// If both sides are equal then the difference must be zero                   // (17)
+	c := curve.MultiScalarMult(ec, points, scalars).IsZero()
 
 	return c, nil
 }
 
 /*
-hashIP is responsible for the computing a Zp element given elements from GT and G1.
+appendInnerProductStatement appends the statement of the inner product proof to
+t and returns the challenge x that binds it to u.
 */
-func hashIP(context *gost3410.Context, g, h []*curve.Point, P *curve.Point, c *big.Int, n int64) (*big.Int, error) {
-	ec := context.Curve
-	ha := context.HashAlgorithm
-
-	digest := ha.New()
-	digest.Write(P.Bytes(ec))
-
-	for i := int64(0); i < n; i++ {
-		digest.Write(g[i].Bytes(ec))
-		digest.Write(h[i].Bytes(ec))
-	}
-
-	digest.Write([]byte(c.String()))
-	output := digest.Sum(nil)
-	tmp := output[0:]
-	result, err := byteconversion.FromByteArray(tmp)
-
-	return result, err
+func appendInnerProductStatement(t *transcript.Transcript, n int64, P *curve.Point, c *big.Int) *big.Int {
+	t.AppendUint64("ip-n", uint64(n))
+	t.AppendPoint("ip-P", P)
+	t.AppendScalar("ip-c", c)
+	return t.ChallengeScalar("ip-x")
 }
 
 /*
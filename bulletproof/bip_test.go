@@ -24,6 +24,7 @@ import (
 	"github.com/AllFi/go-gost3410"
 	"github.com/AllFi/go-gost3410/curve"
 	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/transcript"
 )
 
 /*
@@ -52,9 +53,57 @@ func TestInnerProduct(t *testing.T) {
 	b[3] = new(big.Int).SetInt64(7)
 	commit := commitInnerProduct(context.Curve, innerProductParams.Gg, innerProductParams.Hh, a, b)
 
-	proof, _ := proveInnerProduct(context, a, b, commit, innerProductParams)
-	ok, _ := proof.Verify(context)
+	proof, _ := proveInnerProduct(context, transcript.New(context, "test"), a, b, commit, innerProductParams)
+	ok, _ := proof.Verify(context, transcript.New(context, "test"))
 	if ok != true {
 		t.Errorf("Assert failure: expected true, actual: %t", ok)
 	}
 }
+
+/*
+Test that a tampered Inner Product proof is rejected.
+*/
+func TestInnerProductTampered(t *testing.T) {
+	c := new(big.Int).SetInt64(4)
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+
+	innerProductParams, _ := setupInnerProduct(context, nil, nil, nil, c, 8)
+	a := make([]*big.Int, innerProductParams.N)
+	b := make([]*big.Int, innerProductParams.N)
+	for i := range a {
+		a[i] = new(big.Int).SetInt64(int64(i % 3))
+		b[i] = new(big.Int).SetInt64(int64(i % 2))
+	}
+	commit := commitInnerProduct(context.Curve, innerProductParams.Gg, innerProductParams.Hh, a, b)
+
+	proof, _ := proveInnerProduct(context, transcript.New(context, "test"), a, b, commit, innerProductParams)
+	ok, _ := proof.Verify(context, transcript.New(context, "test"))
+	if ok != true {
+		t.Errorf("Assert failure: expected true, actual: %t", ok)
+	}
+	// verifying twice gives the same answer
+	ok, _ = proof.Verify(context, transcript.New(context, "test"))
+	if ok != true {
+		t.Errorf("Assert failure: expected true, actual: %t", ok)
+	}
+
+	// a transcript with another history
+	ok, _ = proof.Verify(context, transcript.New(context, "other"))
+	if ok != false {
+		t.Errorf("Assert failure: expected false, actual: %t", ok)
+	}
+
+	// another statement
+	other := proof
+	other.Params.Cc = new(big.Int).Add(c, big.NewInt(1))
+	ok, _ = other.Verify(context, transcript.New(context, "test"))
+	if ok != false {
+		t.Errorf("Assert failure: expected false, actual: %t", ok)
+	}
+
+	proof.A = new(big.Int).Add(proof.A, big.NewInt(1))
+	ok, _ = proof.Verify(context, transcript.New(context, "test"))
+	if ok != false {
+		t.Errorf("Assert failure: expected false, actual: %t", ok)
+	}
+}
@@ -20,15 +20,13 @@ package bulletproofs
 import (
 	"crypto/elliptic"
 	"crypto/rand"
-	"errors"
-	"fmt"
-	"math"
 	"math/big"
-	"strconv"
 
 	"github.com/AllFi/go-gost3410"
 	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/transcript"
 	"github.com/ing-bank/zkrp/util/bn"
+	"github.com/pkg/errors"
 )
 
 /*
@@ -36,14 +34,17 @@ BulletProofSetupParams is the structure that stores the parameters for
 the Zero Knowledge Proof system.
 */
 type BulletProofSetupParams struct {
-	// N is the bit-length of the range.
+	// N is the length of the vectors: Bits rounded up to a power of two.
 	N int64
+	// Bits is the bit-length of the range [0, 2^Bits). The bits of the vectors
+	// past Bits do not count towards the value. Zero stands for N.
+	Bits int64
 	// G is the Elliptic Curve generator.
 	G *curve.Point
-	// H is a new generator, computed using MapToGroup function,
+	// H is a new generator, computed using HashToCurve function,
 	// such that there is no discrete logarithm relation with G.
 	H *curve.Point
-	// Gg and Hh are sets of new generators obtained using MapToGroup.
+	// Gg and Hh are sets of new generators obtained using HashToCurve.
 	// They are used to compute Pedersen Vector Commitments.
 	Gg []*curve.Point
 	Hh []*curve.Point
@@ -70,35 +71,50 @@ type BulletProof struct {
 }
 
 /*
-SetupInnerProduct is responsible for computing the common parameters.
-Only works for ranges to 0 to 2^n, where n is a power of 2 and n <= 32
-TODO: allow n > 32 (need uint64 for that).
+Setup is responsible for computing the common parameters of the range proofs for
+[0, 2^bits), 0 < bits <= MaxBits. When bits is not a power of two, the vectors
+of the proofs are padded to the next one.
 */
-func Setup(context *gost3410.Context, b int64) (BulletProofSetupParams, error) {
-	ec := context.Curve
-	ha := context.HashAlgorithm
-
-	if !IsPowerOfTwo(b) {
-		return BulletProofSetupParams{}, errors.New("range end is not a power of 2")
+func Setup(context *gost3410.Context, bits int64) (BulletProofSetupParams, error) {
+	if bits <= 0 || bits > MaxBits {
+		return BulletProofSetupParams{}, errors.Errorf("bit length must be between 1 and %d", MaxBits)
 	}
 
 	params := BulletProofSetupParams{}
-	params.G = new(curve.Point).ScalarBaseMult(ec, new(big.Int).SetInt64(1))
-	params.H, _ = curve.MapToGroup(ec, ha, SEEDH)
-	params.N = int64(math.Log2(float64(b)))
-	if !IsPowerOfTwo(params.N) {
-		return BulletProofSetupParams{}, fmt.Errorf("range end is a power of 2, but it's exponent should also be. Exponent: %d", params.N)
+	params.N = nextPowerOfTwo(bits)
+	params.Bits = bits
+	gens := loadGenerators(context, params.N)
+	params.G = gens.G.Point()
+	params.H = gens.H.Point()
+	params.Gg = gens.Gg.Points()[:params.N]
+	params.Hh = gens.Hh.Points()[:params.N]
+	return params, nil
+}
+
+// bits returns the bit-length of the range.
+func (params *BulletProofSetupParams) bits() int64 {
+	if params.Bits == 0 {
+		return params.N
 	}
-	if params.N > 32 {
-		return BulletProofSetupParams{}, errors.New("range end can not be greater than 2**32")
+	return params.Bits
+}
+
+// equal reports whether params and other have the same bit-length and generators.
+func (params *BulletProofSetupParams) equal(other BulletProofSetupParams) bool {
+	if params.N != other.N || params.bits() != other.bits() ||
+		!params.G.Equal(other.G) || !params.H.Equal(other.H) {
+		return false
 	}
-	params.Gg = make([]*curve.Point, params.N)
-	params.Hh = make([]*curve.Point, params.N)
-	for i := int64(0); i < params.N; i++ {
-		params.Gg[i], _ = curve.MapToGroup(ec, ha, SEEDH+"g"+strconv.Itoa(int(i)))
-		params.Hh[i], _ = curve.MapToGroup(ec, ha, SEEDH+"h"+strconv.Itoa(int(i)))
+	n := int(params.N)
+	if len(params.Gg) < n || len(params.Hh) < n || len(other.Gg) < n || len(other.Hh) < n {
+		return false
 	}
-	return params, nil
+	for i := 0; i < n; i++ {
+		if !params.Gg[i].Equal(other.Gg[i]) || !params.Hh[i].Equal(other.Hh[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 /*
@@ -108,12 +124,12 @@ https://eprint.iacr.org/2017/1066.pdf
 */
 func Prove(context *gost3410.Context, secret *big.Int, params BulletProofSetupParams) (BulletProof, error) {
 	ec := context.Curve
-	ha := context.HashAlgorithm
 
 	var (
 		proof BulletProof
 	)
 	order := ec.Params().N
+	gens := params.precomputed(context)
 
 	// ////////////////////////////////////////////////////////////////////////////
 	// First phase: page 19
@@ -121,22 +137,26 @@ func Prove(context *gost3410.Context, secret *big.Int, params BulletProofSetupPa
 
 	// commitment to v and gamma
 	gamma, _ := rand.Int(rand.Reader, order)
-	V, _ := CommitG1(ec, secret, gamma, params.H)
+	V := gens.commitSecret(context, secret, gamma)
 
 	// aL, aR and commitment: (A, alpha)
-	aL, _ := Decompose(secret, 2, params.N)                                        // (41)
-	aR, _ := computeAR(aL)                                                         // (42)
-	alpha, _ := rand.Int(rand.Reader, order)                                       // (43)
-	A := commitVector(ec, aL, aR, alpha, params.H, params.Gg, params.Hh, params.N) // (44)
+	aL, _ := Decompose(secret, big.NewInt(2), params.N)          // (41)
+	aR, _ := computeAR(aL)                                       // (42)
+	alpha, _ := rand.Int(rand.Reader, order)                     // (43)
+	A := commitVectorBig(context, gens, aL, aR, alpha, params.N) // (44)
 
 	// sL, sR and commitment: (S, rho)                                     // (45)
 	sL := sampleRandomVector(ec, params.N)
 	sR := sampleRandomVector(ec, params.N)
-	rho, _ := rand.Int(rand.Reader, order)                                          // (46)
-	S := commitVectorBig(ec, sL, sR, rho, params.H, params.Gg, params.Hh, params.N) // (47)
+	rho, _ := rand.Int(rand.Reader, order)                     // (46)
+	S := commitVectorBig(context, gens, sL, sR, rho, params.N) // (47)
 
 	// Fiat-Shamir heuristic to compute challenges y and z, corresponds to    (49)
-	y, z, _ := HashBP(ha, A, S)
+	t := params.transcript(context, V)
+	t.AppendPoint("A", A)
+	t.AppendPoint("S", S)
+	y := t.ChallengeScalar("y")
+	z := t.ChallengeScalar("z")
 
 	// ////////////////////////////////////////////////////////////////////////////
 	// Second phase: page 20
@@ -152,8 +172,7 @@ func Prove(context *gost3410.Context, secret *big.Int, params BulletProofSetupPa
 	vy := powerOf(ec, y, params.N)
 
 	// aL - z.1^n
-	naL, _ := VectorConvertToBig(aL, params.N)
-	aLmvz, _ := VectorSub(ec, naL, vz)
+	aLmvz, _ := VectorSub(ec, aL, vz)
 
 	// y^n .sR
 	ynsR, _ := VectorMul(ec, vy, sR)
@@ -162,13 +181,12 @@ func Prove(context *gost3410.Context, secret *big.Int, params BulletProofSetupPa
 	sp1, _ := ScalarProduct(ec, aLmvz, ynsR)
 
 	// scalar prod: < sL, y^n . (aR + z . 1^n) >
-	naR, _ := VectorConvertToBig(aR, params.N)
-	aRzn, _ := VectorAdd(ec, naR, vz)
+	aRzn, _ := VectorAdd(ec, aR, vz)
 	ynaRzn, _ := VectorMul(ec, vy, aRzn)
 
 	// Add z^2.2^n to the result
 	// z^2 . 2^n
-	p2n := powerOf(ec, new(big.Int).SetInt64(2), params.N)
+	p2n := powersOfTwo(params.bits(), params.N)
 	zsquared := bn.Multiply(z, z)
 	z22n, _ := VectorScalarMul(ec, p2n, zsquared)
 	ynaRzn, _ = VectorAdd(ec, ynaRzn, z22n)
@@ -183,13 +201,15 @@ func Prove(context *gost3410.Context, secret *big.Int, params BulletProofSetupPa
 	t2 = bn.Mod(t2, order)
 
 	// compute T1
-	T1, _ := CommitG1(ec, t1, tau1, params.H) // (53)
+	T1 := gens.commitSecret(context, t1, tau1) // (53)
 
 	// compute T2
-	T2, _ := CommitG1(ec, t2, tau2, params.H) // (53)
+	T2 := gens.commitSecret(context, t2, tau2) // (53)
 
 	// Fiat-Shamir heuristic to compute 'random' challenge x
-	x, _, _ := HashBP(ha, T1, T2)
+	t.AppendPoint("T1", T1)
+	t.AppendPoint("T2", T2)
+	x := t.ChallengeScalar("x")
 
 	// ////////////////////////////////////////////////////////////////////////////
 	// Third phase                                                              //
@@ -231,7 +251,7 @@ func Prove(context *gost3410.Context, secret *big.Int, params BulletProofSetupPa
 		return proof, setupErr
 	}
 	commit := commitInnerProduct(ec, params.Gg, hprime, bl, br)
-	proofip, _ := proveInnerProduct(context, bl, br, commit, params.InnerProductParams)
+	proofip, _ := proveInnerProduct(context, t, bl, br, commit, params.InnerProductParams)
 
 	proof.V = V
 	proof.A = A
@@ -253,24 +273,31 @@ Verify returns true if and only if the proof is valid.
 */
 func (proof *BulletProof) Verify(context *gost3410.Context) (bool, error) {
 	ec := context.Curve
-	ha := context.HashAlgorithm
+
+	if err := proof.validateCommitments(context); err != nil {
+		return false, errors.Wrap(err, "invalid proof")
+	}
 
 	params := proof.Params
 	order := ec.Params().N
+	gens := params.precomputed(context)
 
 	// Recover x, y, z using Fiat-Shamir heuristic
-	x, _, _ := HashBP(ha, proof.T1, proof.T2)
-	y, z, _ := HashBP(ha, proof.A, proof.S)
-
-	// Switch generators                                                   // (64)
-	hprime := updateGenerators(ec, params.Hh, y, params.N)
+	t := params.transcript(context, proof.V)
+	t.AppendPoint("A", proof.A)
+	t.AppendPoint("S", proof.S)
+	y := t.ChallengeScalar("y")
+	z := t.ChallengeScalar("z")
+	t.AppendPoint("T1", proof.T1)
+	t.AppendPoint("T2", proof.T2)
+	x := t.ChallengeScalar("x")
 
 	// ////////////////////////////////////////////////////////////////////////////
 	// Check that tprime  = t(x) = t0 + t1x + t2x^2  ----------  Condition (65) //
 	// ////////////////////////////////////////////////////////////////////////////
 
 	// Compute left hand side
-	lhs, _ := CommitG1(ec, proof.Tprime, proof.Taux, params.H)
+	lhs := gens.commit(context, proof.Tprime, proof.Taux)
 
 	// Compute right hand side
 	z2 := bn.Multiply(z, z)
@@ -282,7 +309,7 @@ func (proof *BulletProof) Verify(context *gost3410.Context) (bool, error) {
 
 	delta := params.delta(ec, y, z)
 
-	gdelta := new(curve.Point).ScalarBaseMult(ec, delta)
+	gdelta := gens.G.ScalarMult(delta)
 
 	rhs.Add(ec, rhs, gdelta)
 
@@ -292,10 +319,7 @@ func (proof *BulletProof) Verify(context *gost3410.Context) (bool, error) {
 	rhs.Add(ec, rhs, T1x)
 	rhs.Add(ec, rhs, T2x2)
 
-	// Subtract lhs and rhs and compare with poitn at infinity
-	lhs.Neg(ec, lhs)
-	rhs.Add(ec, rhs, lhs)
-	c65 := rhs.IsZero() // Condition (65), page 20, from eprint version
+	c65 := rhs.Equal(lhs) // Condition (65), page 20, from eprint version
 
 	// Compute P - lhs  #################### Condition (66) ######################
 
@@ -307,14 +331,14 @@ func (proof *BulletProof) Verify(context *gost3410.Context) (bool, error) {
 	// g^-z
 	mz := bn.Sub(order, z)
 	vmz, _ := VectorCopy(mz, params.N)
-	gpmz, _ := VectorExp(ec, params.Gg, vmz)
+	gpmz := gens.Gg.MultiScalarMult(vmz)
 
 	// z.y^n
 	vz, _ := VectorCopy(z, params.N)
 	vy := powerOf(ec, y, params.N)
 	zyn, _ := VectorMul(ec, vy, vz)
 
-	p2n := powerOf(ec, new(big.Int).SetInt64(2), params.N)
+	p2n := powersOfTwo(params.bits(), params.N)
 	zsquared := bn.Multiply(z, z)
 	z22n, _ := VectorScalarMul(ec, p2n, zsquared)
 
@@ -324,30 +348,75 @@ func (proof *BulletProof) Verify(context *gost3410.Context) (bool, error) {
 	lP := new(curve.Point)
 	lP.Add(ec, ASx, gpmz)
 
-	// h'^(z.y^n + z^2.2^n)
-	hprimeexp, _ := VectorExp(ec, hprime, zynz22n)
+	// h'^(z.y^n + z^2.2^n) = h^((z.y^n + z^2.2^n) . y^-n), see (64)
+	yinvn := powerOf(ec, bn.ModInverse(y, order), params.N)
+	exponents, _ := VectorMul(ec, zynz22n, yinvn)
+	hprimeexp := gens.Hh.MultiScalarMult(exponents)
+	hprime := updateGenerators(ec, params.Hh, y, params.N)
 
 	lP.Add(ec, lP, hprimeexp)
 
 	// Compute P - rhs  #################### Condition (67) ######################
 
 	// h^mu
-	rP := new(curve.Point).ScalarMult(ec, params.H, proof.Mu)
+	rP := gens.H.ScalarMult(proof.Mu)
 	rP.Add(ec, rP, proof.Commit)
 
-	// Subtract lhs and rhs and compare with poitn at infinity
-	lP = lP.Neg(ec, lP)
-	rP.Add(ec, rP, lP)
-	c67 := rP.IsZero()
+	c67 := rP.Equal(lP)
 
 	// Verify Inner Product Proof ################################################
-	ok, _ := proof.InnerProductProof.Verify(context)
+	// over the statement (g, h', P, tprime) of this proof rather than the one
+	// the inner product proof carries
+	ipp := proof.InnerProductProof
+	if ipp.N != params.N {
+		return false, errors.New("invalid inner product proof: wrong length")
+	}
+	var err error
+	ipp.Params, err = setupInnerProduct(context, params.H, params.Gg, hprime, proof.Tprime, params.N)
+	if err != nil {
+		return false, err
+	}
+	ipp.Params.P = proof.Commit
+	ok, err := ipp.Verify(context, t)
+	if err != nil {
+		return false, err
+	}
 
 	result := c65 && c67 && ok
 
 	return result, nil
 }
 
+/*
+transcript starts the Fiat-Shamir transcript of a range proof for the
+commitment V.
+*/
+func (params *BulletProofSetupParams) transcript(context *gost3410.Context, V *curve.Point) *transcript.Transcript {
+	return newTranscript(context, params.N, params.bits(), params.G, params.H, params.Gg[:params.N], params.Hh[:params.N], []*curve.Point{V})
+}
+
+/*
+newTranscript starts the Fiat-Shamir transcript of a range proof of the given
+bits and vectors of n elements for the commitments V, binding all the
+generators.
+*/
+func newTranscript(context *gost3410.Context, n, bits int64, G, H *curve.Point, Gg, Hh, V []*curve.Point) *transcript.Transcript {
+	t := transcript.New(context, TranscriptLabel)
+	t.AppendUint64("n", uint64(n))
+	t.AppendUint64("bits", uint64(bits))
+	t.AppendUint64("m", uint64(len(V)))
+	t.AppendPoint("G", G)
+	t.AppendPoint("H", H)
+	for i := range Gg {
+		t.AppendPoint("Gg", Gg[i])
+		t.AppendPoint("Hh", Hh[i])
+	}
+	for j := range V {
+		t.AppendPoint("V", V[j])
+	}
+	return t
+}
+
 /*
 SampleRandomVector generates a vector composed by random big numbers.
 */
@@ -391,13 +460,13 @@ func updateGenerators(ec elliptic.Curve, Hh []*curve.Point, y *big.Int, N int64)
 /*
 aR = aL - 1^n
 */
-func computeAR(x []int64) ([]int64, error) {
-	result := make([]int64, len(x))
+func computeAR(x []*big.Int) ([]*big.Int, error) {
+	result := make([]*big.Int, len(x))
 	for i := int64(0); i < int64(len(x)); i++ {
-		if x[i] == 0 {
-			result[i] = -1
-		} else if x[i] == 1 {
-			result[i] = 0
+		if x[i].Sign() == 0 {
+			result[i] = big.NewInt(-1)
+		} else if x[i].Cmp(big.NewInt(1)) == 0 {
+			result[i] = big.NewInt(0)
 		} else {
 			return nil, errors.New("input contains non-binary element")
 		}
@@ -405,33 +474,18 @@ func computeAR(x []int64) ([]int64, error) {
 	return result, nil
 }
 
-func commitVectorBig(ec elliptic.Curve, aL, aR []*big.Int, alpha *big.Int, H *curve.Point, g, h []*curve.Point, n int64) *curve.Point {
+func commitVectorBig(context *gost3410.Context, gens *generators, aL, aR []*big.Int, alpha *big.Int, n int64) *curve.Point {
 	// Compute h^alpha.vg^aL.vh^aR
-	R := new(curve.Point).ScalarMult(ec, H, alpha)
-	for i := int64(0); i < n; i++ {
-		R.Add(ec, R, new(curve.Point).ScalarMult(ec, g[i], aL[i]))
-		R.Add(ec, R, new(curve.Point).ScalarMult(ec, h[i], aR[i]))
-	}
-	return R
-}
-
-/*
-Commitvector computes a commitment to the bit of the secret.
-*/
-func commitVector(ec elliptic.Curve, aL, aR []int64, alpha *big.Int, H *curve.Point, g, h []*curve.Point, n int64) *curve.Point {
-	// Compute h^alpha.vg^aL.vh^aR
-	R := new(curve.Point).ScalarMult(ec, H, alpha)
-	for i := int64(0); i < n; i++ {
-		gaL := new(curve.Point).ScalarMult(ec, g[i], new(big.Int).SetInt64(aL[i]))
-		haR := new(curve.Point).ScalarMult(ec, h[i], new(big.Int).SetInt64(aR[i]))
-		R.Add(ec, R, gaL)
-		R.Add(ec, R, haR)
-	}
-	return R
+	ec := context.Curve
+	C := gens.H.ScalarMultSecret(alpha)
+	C.Add(ec, C, gens.Gg.MultiScalarMultSecret(aL[:n]))
+	C.Add(ec, C, gens.Hh.MultiScalarMultSecret(aR[:n]))
+	return C
 }
 
 /*
-delta(y,z) = (z-z^2) . < 1^n, y^n > - z^3 . < 1^n, 2^n >
+delta(y,z) = (z-z^2) . < 1^n, y^n > - z^3 . < 1^n, 2^n >, 2^n being padded with
+zeros past the bits of the range.
 */
 func (params *BulletProofSetupParams) delta(ec elliptic.Curve, y, z *big.Int) *big.Int {
 	var (
@@ -451,7 +505,7 @@ func (params *BulletProofSetupParams) delta(ec elliptic.Curve, y, z *big.Int) *b
 	sp1y, _ := ScalarProduct(ec, v1, vy)
 
 	// < 1^n, 2^n >
-	p2n := powerOf(ec, new(big.Int).SetInt64(2), params.N)
+	p2n := powersOfTwo(params.bits(), params.N)
 	sp12, _ := ScalarProduct(ec, v1, p2n)
 
 	result = bn.Sub(z, z2)
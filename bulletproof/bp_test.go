@@ -19,7 +19,6 @@ package bulletproofs
 
 import (
 	"encoding/json"
-	"math"
 	"math/big"
 	"testing"
 
@@ -28,67 +27,75 @@ import (
 	"github.com/AllFi/go-gost3410/hash"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestXEqualsRangeStart(t *testing.T) {
-	rangeEnd := int64(math.Pow(2, 32))
 	x := new(big.Int).SetInt64(0)
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
 
-	params := setupRange(t, context, rangeEnd)
+	params := setupRange(t, context, 32)
 	if proveAndVerifyRange(context, x, params) != true {
 		t.Errorf("x equal to range start should verify successfully")
 	}
 }
 
 func TestXLowerThanRangeStart(t *testing.T) {
-	rangeEnd := int64(math.Pow(2, 32))
 	x := new(big.Int).SetInt64(-1)
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
 
-	params := setupRange(t, context, rangeEnd)
+	params := setupRange(t, context, 32)
 	if proveAndVerifyRange(context, x, params) == true {
 		t.Errorf("x lower than range start should not verify")
 	}
 }
 
 func TestXHigherThanRangeEnd(t *testing.T) {
-	rangeEnd := int64(math.Pow(2, 32))
-	x := new(big.Int).SetInt64(rangeEnd + 1)
+	x := new(big.Int).SetInt64(1<<32 + 1)
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
 
-	params := setupRange(t, context, rangeEnd)
+	params := setupRange(t, context, 32)
 	if proveAndVerifyRange(context, x, params) == true {
 		t.Errorf("x higher than range end should not verify")
 	}
 }
 
 func TestXEqualToRangeEnd(t *testing.T) {
-	rangeEnd := int64(math.Pow(2, 32))
-	x := new(big.Int).SetInt64(rangeEnd)
+	x := new(big.Int).SetInt64(1 << 32)
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
 
-	params := setupRange(t, context, rangeEnd)
+	params := setupRange(t, context, 32)
 	if proveAndVerifyRange(context, x, params) == true {
 		t.Errorf("x equal to range end should not verify")
 	}
 }
 
 func TestXWithinRange(t *testing.T) {
-	rangeEnd := int64(math.Pow(2, 32))
 	x := new(big.Int).SetInt64(3)
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
 
-	params := setupRange(t, context, rangeEnd)
+	params := setupRange(t, context, 32)
 	if proveAndVerifyRange(context, x, params) != true {
 		t.Errorf("x within range should verify successfully")
 	}
 }
 
-func setupRange(t *testing.T, context *gost3410.Context, rangeEnd int64) BulletProofSetupParams {
-	params, err := Setup(context, rangeEnd)
+func TestXWithinRangeCurves(t *testing.T) {
+	x := new(big.Int).SetInt64(200)
+	for _, c := range []*curve.CurveParams{curve.GOST34102012256A, curve.GOST34102012512C} {
+		context := gost3410.NewContext(c, hash.GOST34112012256)
+
+		params := setupRange(t, context, 8)
+		if proveAndVerifyRange(context, x, params) != true {
+			t.Errorf("x within range should verify successfully on %s", c.Name)
+		}
+	}
+}
+
+func setupRange(t *testing.T, context *gost3410.Context, bits int64) BulletProofSetupParams {
+	params, err := Setup(context, bits)
 	if err != nil {
-		t.Errorf("Invalid range end: %s", err)
+		t.Errorf("Invalid bit-length: %s", err)
 		t.FailNow()
 	}
 	return params
@@ -102,7 +109,7 @@ func proveAndVerifyRange(context *gost3410.Context, x *big.Int, params BulletPro
 
 func TestJsonEncodeDecode(t *testing.T) {
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
-	params, _ := Setup(context, MAX_RANGE_END)
+	params, _ := Setup(context, 32)
 	proof, _ := Prove(context, new(big.Int).SetInt64(18), params)
 	jsonEncoded, err := json.Marshal(proof)
 	if err != nil {
@@ -125,3 +132,84 @@ func TestJsonEncodeDecode(t *testing.T) {
 	}
 	assert.True(t, ok, "should verify")
 }
+
+func TestTranscriptBindsStatement(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+	proof, err := Prove(context, big.NewInt(18), params)
+	require.NoError(t, err)
+	other, err := Prove(context, big.NewInt(18), params)
+	require.NoError(t, err)
+
+	ok, err := proof.Verify(context)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the inner product proof of another range proof
+	swapped := proof
+	swapped.InnerProductProof = other.InnerProductProof
+	ok, err = swapped.Verify(context)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the statement carried by the inner product proof is not trusted
+	tampered := proof
+	tampered.InnerProductProof.Params.Cc = new(big.Int).Add(proof.Tprime, big.NewInt(1))
+	ok, err = tampered.Verify(context)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the challenges depend on V
+	moved := proof
+	moved.V = new(curve.Point).Add(context.Curve, proof.V, proof.Params.G)
+	ok, err = moved.Verify(context)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSetupBits(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	for _, bits := range []int64{0, -1, MaxBits + 1} {
+		_, err := Setup(context, bits)
+		assert.Error(t, err, "bits = %d", bits)
+	}
+	for bits, n := range map[int64]int64{1: 1, 8: 8, 10: 16, 33: 64, 64: 64} {
+		params, err := Setup(context, bits)
+		require.NoError(t, err)
+		assert.Equal(t, n, params.N)
+		assert.Equal(t, bits, params.Bits)
+	}
+}
+
+func TestXWithin64BitRange(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	params := setupRange(t, context, 64)
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+
+	assert.True(t, proveAndVerifyRange(context, max, params))
+	assert.True(t, proveAndVerifyRange(context, new(big.Int).Lsh(big.NewInt(1), 63), params))
+	assert.False(t, proveAndVerifyRange(context, new(big.Int).Add(max, big.NewInt(1)), params))
+}
+
+func TestXWithinPaddedRange(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 10)
+
+	assert.True(t, proveAndVerifyRange(context, big.NewInt(0), params))
+	assert.True(t, proveAndVerifyRange(context, big.NewInt(1023), params))
+	// within the 16 bits of the vectors, but not within the 10 bits of the range
+	assert.False(t, proveAndVerifyRange(context, big.NewInt(1024), params))
+	assert.False(t, proveAndVerifyRange(context, big.NewInt(1<<15), params))
+
+	// the bit-length is a part of the statement
+	proof, err := Prove(context, big.NewInt(1023), params)
+	require.NoError(t, err)
+	proof.Params.Bits = 16
+	ok, err := proof.Verify(context)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	proof.Params.Bits = 17
+	ok, err = proof.Verify(context)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,386 @@
+package bulletproofs
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/ing-bank/zkrp/util/bn"
+	"github.com/pkg/errors"
+)
+
+/*
+AggregateBulletProof proves that m commitments V_j hold values of [0, 2^n) at
+once, following section 4.3 of the paper. The vectors of the proof have m*n
+elements, so its inner product proof has log2(m*n) rounds and the proof grows
+with log(m) instead of m.
+*/
+type AggregateBulletProof struct {
+	V                 []*curve.Point
+	A                 *curve.Point
+	S                 *curve.Point
+	T1                *curve.Point
+	T2                *curve.Point
+	Taux              *big.Int
+	Mu                *big.Int
+	Tprime            *big.Int
+	InnerProductProof InnerProductProof
+	Commit            *curve.Point
+	Params            BulletProofSetupParams
+}
+
+/*
+ProveAggregate computes a single range proof for the commitments
+V_j = G^secrets[j].H^gammas[j], of which there can be at most MaxAggregation.
+When the number of secrets is not a power of two, the vectors are padded with
+the bits of commitments to zero with a zero blinding factor, which the verifier
+pads the same way. The generators of Setup are extended to m*n points as
+needed, other generators must have that many.
+*/
+func ProveAggregate(context *gost3410.Context, secrets, gammas []*big.Int, params BulletProofSetupParams) (AggregateBulletProof, error) {
+	ec := context.Curve
+
+	var (
+		proof AggregateBulletProof
+	)
+	if len(secrets) == 0 || len(secrets) != len(gammas) {
+		return proof, errors.New("wrong number of secrets or gammas")
+	}
+	if len(secrets) > MaxAggregation {
+		return proof, errors.Errorf("at most %d secrets can be aggregated", MaxAggregation)
+	}
+	order := ec.Params().N
+	n := params.N
+	m := nextPowerOfTwo(int64(len(secrets)))
+	mn := m * n
+	gens, err := params.aggregateGenerators(context, mn)
+	if err != nil {
+		return proof, err
+	}
+	Gg := gens.Gg.Points()[:mn]
+	Hh := gens.Hh.Points()[:mn]
+
+	// ////////////////////////////////////////////////////////////////////////////
+	// First phase: page 19
+	// ////////////////////////////////////////////////////////////////////////////
+
+	// commitments to v_j and gamma_j, aL is the concatenation of their bits
+	V := make([]*curve.Point, len(secrets))
+	aL := make([]*big.Int, 0, mn)
+	for j := int64(0); j < m; j++ {
+		secret := big.NewInt(0)
+		if j < int64(len(secrets)) {
+			secret = secrets[j]
+			V[j] = gens.commitSecret(context, secrets[j], gammas[j])
+		}
+		digits, _ := Decompose(secret, big.NewInt(2), n) // (41)
+		aL = append(aL, digits...)
+	}
+
+	// aR and commitment: (A, alpha)
+	aR, _ := computeAR(aL)                                 // (42)
+	alpha, _ := rand.Int(rand.Reader, order)               // (43)
+	A := commitVectorBig(context, gens, aL, aR, alpha, mn) // (44)
+
+	// sL, sR and commitment: (S, rho)                                     // (45)
+	sL := sampleRandomVector(ec, mn)
+	sR := sampleRandomVector(ec, mn)
+	rho, _ := rand.Int(rand.Reader, order)               // (46)
+	S := commitVectorBig(context, gens, sL, sR, rho, mn) // (47)
+
+	// Fiat-Shamir heuristic to compute challenges y and z, corresponds to    (49)
+	t := newTranscript(context, n, params.bits(), params.G, params.H, Gg, Hh, V)
+	t.AppendPoint("A", A)
+	t.AppendPoint("S", S)
+	y := t.ChallengeScalar("y")
+	z := t.ChallengeScalar("z")
+
+	// ////////////////////////////////////////////////////////////////////////////
+	// Second phase: page 20
+	// ////////////////////////////////////////////////////////////////////////////
+	tau1, _ := rand.Int(rand.Reader, order) // (52)
+	tau2, _ := rand.Int(rand.Reader, order) // (52)
+
+	// compute t1: < aL - z.1^mn, y^mn . sR > + < sL, y^mn . (aR + z . 1^mn) + z2n >
+	vz, _ := VectorCopy(z, mn)
+	vy := powerOf(ec, y, mn)
+	z2n := aggregateTwos(ec, z, params.bits(), n, m)
+
+	// aL - z.1^mn
+	aLmvz, _ := VectorSub(ec, aL, vz)
+
+	// y^mn .sR
+	ynsR, _ := VectorMul(ec, vy, sR)
+
+	// scalar prod: < aL - z.1^mn, y^mn . sR >
+	sp1, _ := ScalarProduct(ec, aLmvz, ynsR)
+
+	// scalar prod: < sL, y^mn . (aR + z . 1^mn) + z2n >
+	aRzn, _ := VectorAdd(ec, aR, vz)
+	ynaRzn, _ := VectorMul(ec, vy, aRzn)
+	ynaRzn, _ = VectorAdd(ec, ynaRzn, z2n)
+	sp2, _ := ScalarProduct(ec, sL, ynaRzn)
+
+	// sp1 + sp2
+	t1 := bn.Add(sp1, sp2)
+	t1 = bn.Mod(t1, order)
+
+	// compute t2: < sL, y^mn . sR >
+	t2, _ := ScalarProduct(ec, sL, ynsR)
+	t2 = bn.Mod(t2, order)
+
+	// compute T1 and T2
+	T1 := gens.commitSecret(context, t1, tau1) // (53)
+	T2 := gens.commitSecret(context, t2, tau2) // (53)
+
+	// Fiat-Shamir heuristic to compute 'random' challenge x
+	t.AppendPoint("T1", T1)
+	t.AppendPoint("T2", T2)
+	x := t.ChallengeScalar("x")
+
+	// ////////////////////////////////////////////////////////////////////////////
+	// Third phase                                                              //
+	// ////////////////////////////////////////////////////////////////////////////
+
+	// compute bl                                                          // (58)
+	sLx, _ := VectorScalarMul(ec, sL, x)
+	bl, _ := VectorAdd(ec, aLmvz, sLx)
+
+	// compute br = y^mn . ( aR + z.1^mn + sR.x ) + z2n                    // (59)
+	sRx, _ := VectorScalarMul(ec, sR, x)
+	aRzn, _ = VectorAdd(ec, aRzn, sRx)
+	ynaRzn, _ = VectorMul(ec, vy, aRzn)
+	br, _ := VectorAdd(ec, ynaRzn, z2n)
+
+	// Compute t` = < bl, br >                                             // (60)
+	tprime, _ := ScalarProduct(ec, bl, br)
+
+	// Compute taux = tau2 . x^2 + tau1 . x + sum(z^(2+j) . gamma_j)       // (61)
+	taux := bn.Multiply(tau2, bn.Multiply(x, x))
+	taux = bn.Add(taux, bn.Multiply(tau1, x))
+	zj := bn.Mod(bn.Multiply(z, z), order)
+	for j := range gammas {
+		taux = bn.Add(taux, bn.Multiply(zj, gammas[j]))
+		zj = bn.Mod(bn.Multiply(zj, z), order)
+	}
+	taux = bn.Mod(taux, order)
+
+	// Compute mu = alpha + rho.x                                          // (62)
+	mu := bn.Multiply(rho, x)
+	mu = bn.Add(mu, alpha)
+	mu = bn.Mod(mu, order)
+
+	// Inner Product over (g, h', P.h^-mu, tprime)
+	hprime := updateGenerators(ec, Hh, y, mn)
+	ipParams, err := setupInnerProduct(context, gens.H.Point(), Gg, hprime, tprime, mn)
+	if err != nil {
+		return proof, err
+	}
+	commit := commitInnerProduct(ec, Gg, hprime, bl, br)
+	proofip, err := proveInnerProduct(context, t, bl, br, commit, ipParams)
+	if err != nil {
+		return proof, err
+	}
+
+	proof.V = V
+	proof.A = A
+	proof.S = S
+	proof.T1 = T1
+	proof.T2 = T2
+	proof.Taux = taux
+	proof.Mu = mu
+	proof.Tprime = tprime
+	proof.InnerProductProof = proofip
+	proof.Commit = commit
+	proof.Params = params
+
+	return proof, nil
+}
+
+/*
+Verify returns true if and only if the proof is valid for all the commitments V
+under params, the setup of the verifier. A proof made with other generators or
+another bit-length is rejected: whoever knows the discrete logarithms between
+the generators can prove anything.
+*/
+func (proof *AggregateBulletProof) Verify(context *gost3410.Context, params BulletProofSetupParams) (bool, error) {
+	ec := context.Curve
+
+	if !proof.Params.equal(params) {
+		return false, errors.New("invalid proof: made with other params")
+	}
+	if err := proof.validateCommitments(context); err != nil {
+		return false, errors.Wrap(err, "invalid proof")
+	}
+
+	order := ec.Params().N
+	n := params.N
+	m := nextPowerOfTwo(int64(len(proof.V)))
+	mn := m * n
+	gens, err := params.aggregateGenerators(context, mn)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid proof")
+	}
+	Gg := gens.Gg.Points()[:mn]
+	Hh := gens.Hh.Points()[:mn]
+
+	// Recover x, y, z using Fiat-Shamir heuristic
+	t := newTranscript(context, n, params.bits(), params.G, params.H, Gg, Hh, proof.V)
+	t.AppendPoint("A", proof.A)
+	t.AppendPoint("S", proof.S)
+	y := t.ChallengeScalar("y")
+	z := t.ChallengeScalar("z")
+	t.AppendPoint("T1", proof.T1)
+	t.AppendPoint("T2", proof.T2)
+	x := t.ChallengeScalar("x")
+
+	// ////////////////////////////////////////////////////////////////////////////
+	// Check that tprime  = t(x) = t0 + t1x + t2x^2  ----------  Condition (65) //
+	// ////////////////////////////////////////////////////////////////////////////
+
+	// Compute left hand side
+	lhs := gens.commit(context, proof.Tprime, proof.Taux)
+
+	// Compute right hand side: V^(z^2.z^m).g^delta.T1^x.T2^(x^2), where V^(z^m)
+	// stands for the product of V_j^(z^j), the padding being the identity
+	x2 := bn.Multiply(x, x)
+	x2 = bn.Mod(x2, order)
+	points := append([]*curve.Point{proof.T1, proof.T2}, proof.V...)
+	scalars := []*big.Int{x, x2}
+	zj := bn.Mod(bn.Multiply(z, z), order)
+	for range proof.V {
+		scalars = append(scalars, zj)
+		zj = bn.Mod(bn.Multiply(zj, z), order)
+	}
+	rhs := curve.MultiScalarMult(ec, points, scalars)
+	rhs.Add(ec, rhs, gens.G.ScalarMult(aggregateDelta(ec, y, z, params.bits(), n, m)))
+
+	c65 := rhs.Equal(lhs) // Condition (65), page 20, from eprint version
+
+	// Compute P - lhs  #################### Condition (66) ######################
+
+	// A.S^x
+	lP := new(curve.Point).ScalarMult(ec, proof.S, x)
+	lP.Add(ec, lP, proof.A)
+
+	// g^-z
+	mz := bn.Sub(order, z)
+	vmz, _ := VectorCopy(mz, mn)
+	lP.Add(ec, lP, gens.Gg.MultiScalarMult(vmz))
+
+	// h'^(z.y^mn + z2n) = h^((z.y^mn + z2n) . y^-mn), see (64)
+	vz, _ := VectorCopy(z, mn)
+	vy := powerOf(ec, y, mn)
+	zyn, _ := VectorMul(ec, vy, vz)
+	zynz2n, _ := VectorAdd(ec, zyn, aggregateTwos(ec, z, params.bits(), n, m))
+	yinvn := powerOf(ec, bn.ModInverse(y, order), mn)
+	exponents, _ := VectorMul(ec, zynz2n, yinvn)
+	lP.Add(ec, lP, gens.Hh.MultiScalarMult(exponents))
+
+	// Compute P - rhs  #################### Condition (67) ######################
+
+	// h^mu
+	rP := gens.H.ScalarMult(proof.Mu)
+	rP.Add(ec, rP, proof.Commit)
+
+	c67 := rP.Equal(lP)
+
+	// Verify Inner Product Proof ################################################
+	// over the statement (g, h', P, tprime) of this proof
+	ipp := proof.InnerProductProof
+	if ipp.N != mn {
+		return false, errors.New("invalid inner product proof: wrong length")
+	}
+	hprime := updateGenerators(ec, Hh, y, mn)
+	ipp.Params, err = setupInnerProduct(context, gens.H.Point(), Gg, hprime, proof.Tprime, mn)
+	if err != nil {
+		return false, err
+	}
+	ipp.Params.P = proof.Commit
+	ok, err := ipp.Verify(context, t)
+	if err != nil {
+		return false, err
+	}
+
+	result := c65 && c67 && ok
+
+	return result, nil
+}
+
+/*
+aggregateGenerators returns the tables of the generators of params with vectors
+of mn points. The generators of Setup are extended, other generators must have
+at least mn valid points.
+*/
+func (params *BulletProofSetupParams) aggregateGenerators(context *gost3410.Context, mn int64) (*generators, error) {
+	if params.N <= 0 || int64(len(params.Gg)) < params.N || int64(len(params.Hh)) < params.N {
+		return nil, errors.New("generator vectors are shorter than N")
+	}
+	if params.usesGenerators(loadGenerators(context, params.N)) {
+		return loadGenerators(context, mn), nil
+	}
+	if err := validateVectors(context, mn, params.Gg, params.Hh); err != nil {
+		return nil, err
+	}
+	extended := *params
+	extended.N = mn
+	return extended.precomputed(context), nil
+}
+
+/*
+aggregateTwos returns the vector z^2.(2^n || 0^n ...) + z^3.(0^n || 2^n || 0^n ...)
++ ... of m*n elements: block j holds z^(2+j).2^n, 2^n being padded with zeros
+past bits.
+*/
+func aggregateTwos(ec elliptic.Curve, z *big.Int, bits, n, m int64) []*big.Int {
+	order := ec.Params().N
+	p2n := powersOfTwo(bits, n)
+	result := make([]*big.Int, 0, m*n)
+	zj := bn.Mod(bn.Multiply(z, z), order)
+	for j := int64(0); j < m; j++ {
+		block, _ := VectorScalarMul(ec, p2n, zj)
+		result = append(result, block...)
+		zj = bn.Mod(bn.Multiply(zj, z), order)
+	}
+	return result
+}
+
+/*
+delta(y,z) = (z-z^2) . < 1^mn, y^mn > - sum(z^(3+j)) . < 1^n, 2^n >, 2^n being
+padded with zeros past bits.
+*/
+func aggregateDelta(ec elliptic.Curve, y, z *big.Int, bits, n, m int64) *big.Int {
+	order := ec.Params().N
+
+	// < 1^mn, y^mn >
+	v1, _ := VectorCopy(new(big.Int).SetInt64(1), m*n)
+	sp1y, _ := ScalarProduct(ec, v1, powerOf(ec, y, m*n))
+
+	// < 1^n, 2^n >
+	sp12, _ := ScalarProduct(ec, v1[:n], powersOfTwo(bits, n))
+
+	// sum(z^(3+j)) for j < m
+	z2 := bn.Mod(bn.Multiply(z, z), order)
+	zj := bn.Mod(bn.Multiply(z2, z), order)
+	sumz := big.NewInt(0)
+	for j := int64(0); j < m; j++ {
+		sumz = bn.Add(sumz, zj)
+		zj = bn.Mod(bn.Multiply(zj, z), order)
+	}
+
+	result := bn.Sub(z, z2)
+	result = bn.Multiply(result, sp1y)
+	result = bn.Sub(result, bn.Multiply(sumz, sp12))
+	return bn.Mod(result, order)
+}
+
+// nextPowerOfTwo returns the smallest power of two that is not less than m.
+func nextPowerOfTwo(m int64) int64 {
+	p := int64(1)
+	for p < m {
+		p <<= 1
+	}
+	return p
+}
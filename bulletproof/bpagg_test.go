@@ -0,0 +1,184 @@
+package bulletproofs
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proveAggregate(t *testing.T, context *gost3410.Context, secrets []int64, params BulletProofSetupParams) AggregateBulletProof {
+	values := make([]*big.Int, len(secrets))
+	gammas := make([]*big.Int, len(secrets))
+	for j := range secrets {
+		values[j] = big.NewInt(secrets[j])
+		gammas[j], _ = rand.Int(rand.Reader, context.Curve.Params().N)
+	}
+	proof, err := ProveAggregate(context, values, gammas, params)
+	require.NoError(t, err)
+
+	// V_j commits to the secret j
+	gens := loadGenerators(context, params.N)
+	for j := range secrets {
+		assert.True(t, proof.V[j].Equal(gens.commit(context, values[j], gammas[j])))
+	}
+	return proof
+}
+
+func TestProveAggregate(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+
+	for _, m := range []int{1, 2, 3, 4, 5, 16} {
+		secrets := make([]int64, m)
+		for j := range secrets {
+			secrets[j] = int64(j*37) % 256
+		}
+		secrets[m-1] = 255
+		proof := proveAggregate(t, context, secrets, params)
+		ok, err := proof.Verify(context, params)
+		assert.NoError(t, err)
+		assert.True(t, ok, "m = %d", m)
+
+		// log2(m*n) rounds
+		assert.Equal(t, int64(8)*nextPowerOfTwo(int64(m)), proof.InnerProductProof.N)
+		assert.Equal(t, int(3+log2(nextPowerOfTwo(int64(m)))), len(proof.InnerProductProof.Ls))
+	}
+}
+
+func TestProveAggregateOutOfRange(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+
+	proof := proveAggregate(t, context, []int64{3, 256, 7}, params)
+	ok, err := proof.Verify(context, params)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	proof = proveAggregate(t, context, []int64{3, -1}, params)
+	ok, err = proof.Verify(context, params)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestProveAggregatePaddedRange(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 12)
+
+	proof := proveAggregate(t, context, []int64{4095, 0, 17}, params)
+	ok, err := proof.Verify(context, params)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	proof = proveAggregate(t, context, []int64{4095, 4096, 17}, params)
+	ok, err = proof.Verify(context, params)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifyAggregateTampered(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+	proof := proveAggregate(t, context, []int64{1, 2, 3}, params)
+
+	// the commitments are bound to the proof
+	swapped := proof
+	swapped.V = []*curve.Point{proof.V[1], proof.V[0], proof.V[2]}
+	ok, err := swapped.Verify(context, params)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	dropped := proof
+	dropped.V = proof.V[:2]
+	ok, _ = dropped.Verify(context, params)
+	assert.False(t, ok)
+
+	// a wrong number of secrets
+	_, err = ProveAggregate(context, []*big.Int{big.NewInt(1)}, nil, params)
+	assert.Error(t, err)
+	_, err = ProveAggregate(context, nil, nil, params)
+	assert.Error(t, err)
+}
+
+func TestAggregateLimit(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+
+	values := make([]*big.Int, MaxAggregation+1)
+	for j := range values {
+		values[j] = big.NewInt(int64(j))
+	}
+	_, err := ProveAggregate(context, values, values, params)
+	assert.Error(t, err)
+
+	// a proof with too many commitments is rejected before the generators of
+	// m*n points are derived
+	proof := proveAggregate(t, context, []int64{1, 2, 3}, params)
+	for len(proof.V) <= MaxAggregation {
+		proof.V = append(proof.V, proof.V[0])
+	}
+	before := loadGenerators(context, 1).Gg.Len()
+	ok, err := proof.Verify(context, params)
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, before, loadGenerators(context, 1).Gg.Len())
+}
+
+func TestVerifyAggregateParams(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+	proof := proveAggregate(t, context, []int64{1, 2}, params)
+
+	// the verifier decides on the range
+	wider := setupRange(t, context, 16)
+	ok, err := proof.Verify(context, wider)
+	assert.Error(t, err)
+	assert.False(t, ok)
+	narrower := setupRange(t, context, 6)
+	ok, err = proof.Verify(context, narrower)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	// and on the generators, whatever the proof claims
+	forged := proof
+	forged.Params.H = new(curve.Point).ScalarBaseMult(context.Curve, big.NewInt(5))
+	ok, err = forged.Verify(context, params)
+	assert.Error(t, err)
+	assert.False(t, ok)
+	custom := params
+	custom.H = forged.Params.H
+	ok, err = proof.Verify(context, custom)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestJsonEncodeDecodeAggregate(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params := setupRange(t, context, 8)
+	proof := proveAggregate(t, context, []int64{18, 200}, params)
+
+	encoded, err := json.Marshal(proof)
+	require.NoError(t, err)
+	var decoded AggregateBulletProof
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, proof, decoded)
+
+	ok, err := decoded.Verify(context, params)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func log2(n int64) int64 {
+	l := int64(0)
+	for n > 1 {
+		n >>= 1
+		l++
+	}
+	return l
+}
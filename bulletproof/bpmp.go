@@ -26,40 +26,45 @@ type MPCPContext struct {
 func PartialPreProve(context *gost3410.Context, params BulletProofSetupParams) (mpcpContext *MPCPContext, publicTau1 *curve.Point, publicTau2 *curve.Point) {
 	ec := context.Curve
 	order := ec.Params().N
+	gens := params.precomputed(context)
 	tau1, _ := rand.Int(rand.Reader, order) // (52)
 	tau2, _ := rand.Int(rand.Reader, order) // (52)
-	publicTau1, _ = CommitG1(ec, big.NewInt(0), tau1, params.H)
-	publicTau2, _ = CommitG1(ec, big.NewInt(0), tau2, params.H)
+	publicTau1 = gens.H.ScalarMultSecret(tau1)
+	publicTau2 = gens.H.ScalarMultSecret(tau2)
 	mpcpContext = &MPCPContext{tau1: tau1, tau2: tau2}
 	return
 }
 
 func PartialProve(context *gost3410.Context, secret *big.Int, gamma *big.Int, inContext *MPCPContext, publicTau1s []*curve.Point, publicTau2s []*curve.Point, params BulletProofSetupParams) (outContext *MPCPContext, taux *big.Int, err error) {
 	ec := context.Curve
-	ha := context.HashAlgorithm
 	order := ec.Params().N
+	gens := params.precomputed(context)
 
 	// ////////////////////////////////////////////////////////////////////////////
 	// First phase: page 19
 	// ////////////////////////////////////////////////////////////////////////////
 
 	// commitment to v and gamma
-	V, _ := CommitG1(ec, secret, gamma, params.H)
+	V := gens.commitSecret(context, secret, gamma)
 
 	// aL, aR and commitment: (A, alpha)
-	aL, _ := Decompose(secret, 2, params.N)                                        // (41)
-	aR, _ := computeAR(aL)                                                         // (42)
-	alpha, _ := rand.Int(rand.Reader, order)                                       // (43)
-	A := commitVector(ec, aL, aR, alpha, params.H, params.Gg, params.Hh, params.N) // (44)
+	aL, _ := Decompose(secret, big.NewInt(2), params.N)          // (41)
+	aR, _ := computeAR(aL)                                       // (42)
+	alpha, _ := rand.Int(rand.Reader, order)                     // (43)
+	A := commitVectorBig(context, gens, aL, aR, alpha, params.N) // (44)
 
 	// sL, sR and commitment: (S, rho)                                     // (45)
 	sL := sampleRandomVector(ec, params.N)
 	sR := sampleRandomVector(ec, params.N)
-	rho, _ := rand.Int(rand.Reader, order)                                          // (46)
-	S := commitVectorBig(ec, sL, sR, rho, params.H, params.Gg, params.Hh, params.N) // (47)
+	rho, _ := rand.Int(rand.Reader, order)                     // (46)
+	S := commitVectorBig(context, gens, sL, sR, rho, params.N) // (47)
 
 	// Fiat-Shamir heuristic to compute challenges y and z, corresponds to    (49)
-	y, z, _ := HashBP(ha, A, S)
+	t := params.transcript(context, V)
+	t.AppendPoint("A", A)
+	t.AppendPoint("S", S)
+	y := t.ChallengeScalar("y")
+	z := t.ChallengeScalar("z")
 
 	// ////////////////////////////////////////////////////////////////////////////
 	// Second phase: page 20
@@ -73,8 +78,7 @@ func PartialProve(context *gost3410.Context, secret *big.Int, gamma *big.Int, in
 	vy := powerOf(ec, y, params.N)
 
 	// aL - z.1^n
-	naL, _ := VectorConvertToBig(aL, params.N)
-	aLmvz, _ := VectorSub(ec, naL, vz)
+	aLmvz, _ := VectorSub(ec, aL, vz)
 
 	// y^n .sR
 	ynsR, _ := VectorMul(ec, vy, sR)
@@ -83,13 +87,12 @@ func PartialProve(context *gost3410.Context, secret *big.Int, gamma *big.Int, in
 	sp1, _ := ScalarProduct(ec, aLmvz, ynsR)
 
 	// scalar prod: < sL, y^n . (aR + z . 1^n) >
-	naR, _ := VectorConvertToBig(aR, params.N)
-	aRzn, _ := VectorAdd(ec, naR, vz)
+	aRzn, _ := VectorAdd(ec, aR, vz)
 	ynaRzn, _ := VectorMul(ec, vy, aRzn)
 
 	// Add z^2.2^n to the result
 	// z^2 . 2^n
-	p2n := powerOf(ec, new(big.Int).SetInt64(2), params.N)
+	p2n := powersOfTwo(params.bits(), params.N)
 	zsquared := bn.Multiply(z, z)
 	z22n, _ := VectorScalarMul(ec, p2n, zsquared)
 	ynaRzn, _ = VectorAdd(ec, ynaRzn, z22n)
@@ -104,19 +107,21 @@ func PartialProve(context *gost3410.Context, secret *big.Int, gamma *big.Int, in
 	t2 = big.NewInt(0).Mod(t2, order)
 
 	// compute T1
-	T1, _ := CommitG1(ec, t1, big.NewInt(0), params.H) // (53)
+	T1 := gens.G.ScalarMultSecret(t1) // (53)
 	for _, publicTau1 := range publicTau1s {
 		T1 = T1.Add(ec, T1, publicTau1)
 	}
 
 	// compute T2
-	T2, _ := CommitG1(ec, t2, big.NewInt(0), params.H) // (53)
+	T2 := gens.G.ScalarMultSecret(t2) // (53)
 	for _, publicTau2 := range publicTau2s {
 		T2 = T2.Add(ec, T1, publicTau2)
 	}
 
 	// Fiat-Shamir heuristic to compute 'random' challenge x
-	x, _, _ := HashBP(ha, T1, T2)
+	t.AppendPoint("T1", T1)
+	t.AppendPoint("T2", T2)
+	x := t.ChallengeScalar("x")
 
 	// compute bl                                                          // (58)
 	sLx, _ := VectorScalarMul(ec, sL, x)
@@ -154,7 +159,7 @@ func PartialProve(context *gost3410.Context, secret *big.Int, gamma *big.Int, in
 		return nil, nil, setupErr
 	}
 	commit := commitInnerProduct(ec, params.Gg, hprime, bl, br)
-	proofip, _ := proveInnerProduct(context, bl, br, commit, params.InnerProductParams)
+	proofip, _ := proveInnerProduct(context, t, bl, br, commit, params.InnerProductParams)
 
 	outContext = &MPCPContext{
 		V:                 V,
@@ -14,7 +14,7 @@ import (
 func TestMultiparty(t *testing.T) {
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
 	order := context.Curve.Params().N
-	params, errSetup := Setup(context, MAX_RANGE_END)
+	params, errSetup := Setup(context, 32)
 	assert.NoError(t, errSetup)
 
 	dealerValue := new(big.Int).SetInt64(int64(300))
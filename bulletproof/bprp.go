@@ -18,92 +18,111 @@
 package bulletproofs
 
 import (
+	"crypto/rand"
 	"math/big"
 
 	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/pkg/errors"
 )
 
 /*
-bprp structure contains 2 BulletProofs in order to allow computation of
-generic Range Proofs, for any interval [A, B).
+bprp structure contains the setup of the aggregated BulletProof that allows
+computation of generic Range Proofs, for any interval [A, B).
 */
 type bprp struct {
-	A   int64
-	B   int64
-	BP1 BulletProofSetupParams
-	BP2 BulletProofSetupParams
+	A  int64
+	B  int64
+	BP BulletProofSetupParams
 }
 
 /*
-ProofBPRP stores the generic ZKRP.
+ProofBPRP stores the generic ZKRP: a single aggregated proof for both of the
+ranges.
 */
 type ProofBPRP struct {
-	P1 BulletProof
-	P2 BulletProof
+	P AggregateBulletProof
 }
 
 /*
-SetupGeneric is responsible for calling the Setup algorithm for each
-BulletProof.
+SetupGeneric is responsible for calling the Setup algorithm for the aggregated
+BulletProof with the given bit-length. The proof is only sound if the width of
+the interval B - A is at most 2^bits, so a wider interval is rejected.
 */
-func SetupGeneric(context *gost3410.Context, a, b int64) (*bprp, error) {
+func SetupGeneric(context *gost3410.Context, a, b, bits int64) (*bprp, error) {
+	if a >= b {
+		return nil, errors.New("range start must be less than range end")
+	}
 	params := new(bprp)
 	params.A = a
 	params.B = b
-	var errBp1, errBp2 error
-	params.BP1, errBp1 = Setup(context, MAX_RANGE_END)
-	if errBp1 != nil {
-		return nil, errBp1
+	var errBp error
+	params.BP, errBp = Setup(context, bits)
+	if errBp != nil {
+		return nil, errBp
 	}
-	params.BP2, errBp2 = Setup(context, MAX_RANGE_END)
-	if errBp2 != nil {
-		return nil, errBp2
+	width := new(big.Int).Sub(big.NewInt(b), big.NewInt(a))
+	if width.Cmp(new(big.Int).Lsh(big.NewInt(1), uint(bits))) > 0 {
+		return nil, errors.Errorf("range does not fit in %d bits", bits)
 	}
 	return params, nil
 }
 
 /*
 BulletProof only works for interval in the format [0, 2^N). In order to
-allow generic intervals in the format [A, B) it is necessary to use 2
-BulletProofs, as explained in Section 4.3 from the following paper:
+allow generic intervals in the format [A, B) it is necessary to prove 2
+ranges, as explained in Section 4.3 from the following paper:
 https://infoscience.epfl.ch/record/128718/files/CCS08.pdf
+Both of them are proven with one aggregated BulletProof. The two commitments
+share their blinding factor, so that V0 - V1 = (2^N + A - B)*G shows that they
+hold values derived from the same x; V1 + A*G is the commitment to x.
 */
 func ProveGeneric(context *gost3410.Context, secret *big.Int, params *bprp) (ProofBPRP, error) {
 	var proof ProofBPRP
 
 	// x - b + 2^N
-	p2 := new(big.Int).SetInt64(MAX_RANGE_END)
+	p2 := new(big.Int).Lsh(big.NewInt(1), uint(params.BP.bits()))
 	xb := new(big.Int).Sub(secret, new(big.Int).SetInt64(params.B))
 	xb.Add(xb, p2)
 
-	var err1 error
-	proof.P1, err1 = Prove(context, xb, params.BP1)
-	if err1 != nil {
-		return proof, err1
+	// x - a
+	xa := new(big.Int).Sub(secret, new(big.Int).SetInt64(params.A))
+
+	gamma, err := rand.Int(rand.Reader, context.Curve.Params().N)
+	if err != nil {
+		return proof, err
 	}
 
-	xa := new(big.Int).Sub(secret, new(big.Int).SetInt64(params.A))
-	var err2 error
-	proof.P2, err2 = Prove(context, xa, params.BP2)
-	if err2 != nil {
-		return proof, err2
+	proof.P, err = ProveAggregate(context, []*big.Int{xb, xa}, []*big.Int{gamma, gamma}, params.BP)
+	if err != nil {
+		return proof, err
 	}
 
 	return proof, nil
 }
 
 /*
-Verify call the Verification algorithm for each BulletProof argument.
+Verify checks that the proof shows A <= x < B for the interval and setup of
+params, the ones of the verifier: V0 - V1 must be (2^N + A - B)*G and the
+aggregated BulletProof must be valid under params.BP.
 */
-func (proof ProofBPRP) Verify(context *gost3410.Context) (bool, error) {
-	ok1, err1 := proof.P1.Verify(context)
-	if !ok1 {
-		return false, err1
+func (proof ProofBPRP) Verify(context *gost3410.Context, params *bprp) (bool, error) {
+	if len(proof.P.V) != 2 {
+		return false, errors.New("generic range proof needs 2 commitments")
 	}
-	ok2, err2 := proof.P2.Verify(context)
-	if !ok2 {
-		return false, err2
+	if proof.P.V[0] == nil || proof.P.V[1] == nil {
+		return false, errors.New("generic range proof has a missing commitment")
 	}
 
-	return ok1 && ok2, nil
+	// (2^N + A - B)*G
+	ec := context.Curve
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(params.BP.bits()))
+	offset.Add(offset, big.NewInt(params.A))
+	offset.Sub(offset, big.NewInt(params.B))
+	offset.Mod(offset, ec.Params().N)
+	expected := new(curve.Point).ScalarMult(ec, params.BP.G, offset)
+	if !new(curve.Point).Sub(ec, proof.P.V[0], proof.P.V[1]).Equal(expected) {
+		return false, nil
+	}
+	return proof.P.Verify(context, params.BP)
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/AllFi/go-gost3410/curve"
 	"github.com/AllFi/go-gost3410/hash"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestXWithinGenericRange(t *testing.T) {
@@ -64,7 +65,7 @@ func TestXEqualToRangeEndGeneric(t *testing.T) {
 }
 
 func setupProveVerify18To200(t *testing.T, context *gost3410.Context, secret int) bool {
-	params, errSetup := SetupGeneric(context, 18, 200)
+	params, errSetup := SetupGeneric(context, 18, 200, 8)
 	if errSetup != nil {
 		t.Errorf(errSetup.Error())
 		t.FailNow()
@@ -75,7 +76,7 @@ func setupProveVerify18To200(t *testing.T, context *gost3410.Context, secret int
 		t.Errorf(errProve.Error())
 		t.FailNow()
 	}
-	ok, errVerify := proof.Verify(context)
+	ok, errVerify := proof.Verify(context, params)
 	if errVerify != nil {
 		t.Errorf(errVerify.Error())
 		t.FailNow()
@@ -87,7 +88,7 @@ func TestJsonEncodeDecodeBPRP(t *testing.T) {
 	// Set up the range, [18, 200) in this case.
 	// We want to prove that we are over 18, and less than 200 years old.
 	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
-	params, errSetup := SetupGeneric(context, 18, 200)
+	params, errSetup := SetupGeneric(context, 18, 200, 8)
 	if errSetup != nil {
 		t.Errorf(errSetup.Error())
 		t.FailNow()
@@ -119,10 +120,69 @@ func TestJsonEncodeDecodeBPRP(t *testing.T) {
 	assert.Equal(t, proof, decodedProof, "should be equal")
 
 	// Verify the proof
-	ok, errVerify := decodedProof.Verify(context)
+	ok, errVerify := decodedProof.Verify(context, params)
 	if errVerify != nil {
 		t.Errorf(errVerify.Error())
 		t.FailNow()
 	}
 	assert.True(t, ok, "should verify")
 }
+
+func TestSetupGeneric(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+
+	// B - A must fit the bit-length
+	for _, c := range []struct{ a, b, bits int64 }{
+		{18, 18, 8}, {200, 18, 8}, {0, 257, 8}, {18, 200, 0}, {18, 200, MaxBits + 1},
+	} {
+		_, err := SetupGeneric(context, c.a, c.b, c.bits)
+		assert.Error(t, err, "[%d, %d) in %d bits", c.a, c.b, c.bits)
+	}
+
+	// a range wider than 32 bits
+	params, err := SetupGeneric(context, -1<<40, 1<<40, 41)
+	assert.NoError(t, err)
+	for _, c := range []struct {
+		x  int64
+		ok bool
+	}{{-1 << 40, true}, {1<<40 - 1, true}, {1 << 40, false}, {-1<<40 - 1, false}} {
+		proof, err := ProveGeneric(context, big.NewInt(c.x), params)
+		assert.NoError(t, err)
+		ok, err := proof.Verify(context, params)
+		assert.NoError(t, err)
+		assert.Equal(t, c.ok, ok, "x = %d", c.x)
+	}
+}
+
+func TestVerifyGenericBinding(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params, err := SetupGeneric(context, 18, 200, 8)
+	require.NoError(t, err)
+
+	// 19 - 200 + 2^8 and 250 - 18 are both in [0, 2^8), but come from
+	// different x, and 250 is not in [18, 200)
+	values := []*big.Int{big.NewInt(19 - 200 + 256), big.NewInt(250 - 18)}
+	for _, gammas := range [][]*big.Int{
+		{big.NewInt(3), big.NewInt(3)},
+		{big.NewInt(3), big.NewInt(4)},
+	} {
+		aggregated, err := ProveAggregate(context, values, gammas, params.BP)
+		require.NoError(t, err)
+		ok, err := aggregated.Verify(context, params.BP)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = ProofBPRP{P: aggregated}.Verify(context, params)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	// a proof is only valid for the interval it was made for
+	proof, err := ProveGeneric(context, big.NewInt(40), params)
+	require.NoError(t, err)
+	other, err := SetupGeneric(context, 30, 200, 8)
+	require.NoError(t, err)
+	ok, err := proof.Verify(context, other)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
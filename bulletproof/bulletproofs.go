@@ -25,5 +25,29 @@ Asiacrypt 2008
 package bulletproofs
 
 var SEEDH = "BulletproofsDoesNotNeedTrustedSetupH"
-var MAX_RANGE_END int64 = 4294967296 // 2**32
-var MAX_RANGE_END_EXPONENT = 32      // 2**32
+
+/*
+GeneratorsDST is the domain separation tag under which the generators are hashed
+to the curve with curve.HashToCurve: H from SEEDH, U from SEEDU, and the i-th
+points of Gg and Hh from SEEDH followed by "g" or "h" and i in decimal.
+*/
+const GeneratorsDST = "GOST3410-V01-BULLETPROOFS-GENERATORS-XMD-SSWU-RO"
+
+/*
+TranscriptLabel is the protocol label of the Fiat-Shamir transcripts of the range
+proofs. The transcript binds the bit length, the generators and V before the
+first challenge, so every challenge depends on the whole statement.
+*/
+const TranscriptLabel = "GOST3410-V01-BULLETPROOFS"
+
+/*
+MaxBits is the largest bit-length of the ranges that Setup accepts.
+*/
+const MaxBits = 64
+
+/*
+MaxAggregation is the largest number of commitments that ProveAggregate and
+AggregateBulletProof.Verify accept. It bounds the generators that a proof can
+make the cache derive to MaxAggregation*MaxBits points per context.
+*/
+const MaxAggregation = 16
@@ -0,0 +1,136 @@
+package bulletproofs
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+)
+
+/*
+generators holds the precomputed tables of the generators of the proofs: G, H
+and U for the single points, Gg and Hh for the vectors.
+*/
+type generators struct {
+	G, H, U *curve.FixedBase
+	Gg, Hh  *curve.FixedBases
+}
+
+// generatorCache keeps the generators of a curve and hash algorithm, extended
+// when a longer vector is needed.
+type generatorCache struct {
+	mu   sync.Mutex
+	gens *generators
+}
+
+// cachedGenerators holds a *generatorCache per curve.ContextKey.
+var cachedGenerators sync.Map
+
+/*
+loadGenerators returns the generators derived from SEEDH and SEEDU, with vectors
+of at least n points. They are computed once per curve and hash algorithm and
+shared by all the goroutines.
+*/
+func loadGenerators(context *gost3410.Context, n int64) *generators {
+	key, ok := curve.NewContextKey(context)
+	if !ok {
+		return deriveGenerators(context, n, nil)
+	}
+	value, _ := cachedGenerators.LoadOrStore(key, new(generatorCache))
+	cache := value.(*generatorCache)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.gens == nil || int64(cache.gens.Gg.Len()) < n {
+		cache.gens = deriveGenerators(context, n, cache.gens)
+	}
+	return cache.gens
+}
+
+/*
+deriveGenerators maps the seeds to the generators with vectors of n points. The
+points and tables of old are reused, so extending the vectors only derives the
+new points.
+*/
+func deriveGenerators(context *gost3410.Context, n int64, old *generators) *generators {
+	ec := context.Curve
+	ha := context.HashAlgorithm
+
+	if old == nil {
+		H, _ := hashToCurve(ec, ha, SEEDH)
+		U, _ := hashToCurve(ec, ha, SEEDU)
+		old = &generators{
+			G:  curve.FixedBaseG(context),
+			H:  curve.NewFixedBase(ec, H),
+			U:  curve.NewFixedBase(ec, U),
+			Gg: curve.NewFixedBases(ec, nil),
+			Hh: curve.NewFixedBases(ec, nil),
+		}
+	}
+
+	from := int64(old.Gg.Len())
+	if n <= from {
+		return old
+	}
+	Gg := make([]*curve.Point, 0, n-from)
+	Hh := make([]*curve.Point, 0, n-from)
+	for i := from; i < n; i++ {
+		g, _ := hashToCurve(ec, ha, SEEDH+"g"+strconv.Itoa(int(i)))
+		h, _ := hashToCurve(ec, ha, SEEDH+"h"+strconv.Itoa(int(i)))
+		Gg, Hh = append(Gg, g), append(Hh, h)
+	}
+	return &generators{G: old.G, H: old.H, U: old.U, Gg: old.Gg.Append(Gg), Hh: old.Hh.Append(Hh)}
+}
+
+// hashToCurve maps a seed to a generator under GeneratorsDST.
+func hashToCurve(ec elliptic.Curve, ha gost3410.HashAlgorithm, seed string) (*curve.Point, error) {
+	return curve.HashToCurve(ec, ha, []byte(seed), []byte(GeneratorsDST))
+}
+
+/*
+precomputed returns the tables of the generators of params: the cached ones when
+params come from Setup, new ones for other generators.
+*/
+func (params *BulletProofSetupParams) precomputed(context *gost3410.Context) *generators {
+	gens := loadGenerators(context, params.N)
+	if params.usesGenerators(gens) {
+		return gens
+	}
+	return &generators{
+		G:  curve.NewFixedBase(context.Curve, params.G),
+		H:  curve.NewFixedBase(context.Curve, params.H),
+		U:  gens.U,
+		Gg: curve.NewFixedBases(context.Curve, params.Gg[:params.N]),
+		Hh: curve.NewFixedBases(context.Curve, params.Hh[:params.N]),
+	}
+}
+
+func (params *BulletProofSetupParams) usesGenerators(gens *generators) bool {
+	if !params.G.Equal(gens.G.Point()) || !params.H.Equal(gens.H.Point()) {
+		return false
+	}
+	for i := 0; i < int(params.N); i++ {
+		if !gens.Gg.Equal(i, params.Gg[i]) || !gens.Hh.Equal(i, params.Hh[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+commit computes the Pedersen commitment G^x.H^r of the public x and r.
+*/
+func (gens *generators) commit(context *gost3410.Context, x, r *big.Int) *curve.Point {
+	return new(curve.Point).Add(context.Curve, gens.G.ScalarMult(x), gens.H.ScalarMult(r))
+}
+
+/*
+commitSecret computes the Pedersen commitment G^x.H^r in constant time with
+respect to x and r.
+*/
+func (gens *generators) commitSecret(context *gost3410.Context, x, r *big.Int) *curve.Point {
+	return new(curve.Point).Add(context.Curve, gens.G.ScalarMultSecret(x), gens.H.ScalarMultSecret(r))
+}
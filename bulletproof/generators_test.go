@@ -0,0 +1,68 @@
+package bulletproofs
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGenerators(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256B, hash.GOST34112012256)
+	ec := context.Curve
+
+	// the generators are the seeds hashed to the curve
+	gens := loadGenerators(context, 4)
+	dst := []byte(GeneratorsDST)
+	H, _ := curve.HashToCurve(ec, context.HashAlgorithm, []byte(SEEDH), dst)
+	U, _ := curve.HashToCurve(ec, context.HashAlgorithm, []byte(SEEDU), dst)
+	g3, _ := curve.HashToCurve(ec, context.HashAlgorithm, []byte(SEEDH+"g3"), dst)
+	h3, _ := curve.HashToCurve(ec, context.HashAlgorithm, []byte(SEEDH+"h3"), dst)
+	assert.True(t, H.Equal(gens.H.Point()))
+	assert.True(t, U.Equal(gens.U.Point()))
+	assert.True(t, g3.Equal(gens.Gg.Points()[3]))
+	assert.True(t, h3.Equal(gens.Hh.Points()[3]))
+
+	// other contexts with the same curve and hash share them, longer vectors
+	// extend them
+	other := gost3410.NewContext(curve.GOST34102012256B, hash.GOST34112012256)
+	assert.True(t, gens == loadGenerators(other, 2))
+	longer := loadGenerators(other, 8)
+	assert.Equal(t, 8, longer.Gg.Len())
+	assert.True(t, gens.H == longer.H)
+	assert.True(t, longer.Gg.Equal(3, g3))
+	assert.True(t, longer.Hh.Equal(3, h3))
+	assert.Equal(t, 4, gens.Gg.Len())
+	assert.True(t, longer == loadGenerators(context, 4))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, longer == loadGenerators(context, 8))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPrecomputed(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012256)
+	params, _ := Setup(context, 4)
+	gens := params.precomputed(context)
+	assert.True(t, gens == loadGenerators(context, params.N))
+
+	// other generators get their own tables
+	params.H = new(curve.Point).ScalarBaseMult(context.Curve, big.NewInt(5))
+	custom := params.precomputed(context)
+	assert.False(t, custom == gens)
+	assert.True(t, params.H.Equal(custom.H.Point()))
+
+	proof, _ := Prove(context, big.NewInt(3), params)
+	ok, _ := proof.Verify(context)
+	assert.True(t, ok)
+}
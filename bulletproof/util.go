@@ -18,12 +18,10 @@
 package bulletproofs
 
 import (
-	"bytes"
 	"crypto/elliptic"
 	"errors"
 	"math/big"
 
-	"github.com/AllFi/go-gost3410"
 	"github.com/AllFi/go-gost3410/curve"
 	"github.com/ing-bank/zkrp/util/bn"
 	"github.com/ing-bank/zkrp/util/intconversion"
@@ -49,56 +47,14 @@ func powerOf(ec elliptic.Curve, x *big.Int, n int64) []*big.Int {
 	return result
 }
 
-/*
-Hash is responsible for the computing a Zp element given elements from GT and G1.
-*/
-func HashBP(ha gost3410.HashAlgorithm, A, S *curve.Point) (*big.Int, *big.Int, error) {
-	digest1 := ha.New()
-	var buffer bytes.Buffer
-	buffer.WriteString(A.X.String())
-	buffer.WriteString(A.Y.String())
-	buffer.WriteString(S.X.String())
-	buffer.WriteString(S.Y.String())
-	digest1.Write(buffer.Bytes())
-	output1 := digest1.Sum(nil)
-	tmp1 := output1[0:]
-	result1 := new(big.Int).SetBytes(tmp1)
-
-	digest2 := ha.New()
-	var buffer2 bytes.Buffer
-	buffer2.WriteString(A.X.String())
-	buffer2.WriteString(A.Y.String())
-	buffer2.WriteString(S.X.String())
-	buffer2.WriteString(S.Y.String())
-	buffer2.WriteString(result1.String())
-	digest2.Write(buffer.Bytes())
-	output2 := digest2.Sum(nil)
-	tmp2 := output2[0:]
-	result2 := new(big.Int).SetBytes(tmp2)
-
-	return result1, result2, nil
-}
-
 /*
 VectorExp computes Prod_i^n{a[i]^b[i]}.
 */
 func VectorExp(ec elliptic.Curve, a []*curve.Point, b []*big.Int) (*curve.Point, error) {
-	var (
-		result  *curve.Point
-		i, n, m int64
-	)
-	n = int64(len(a))
-	m = int64(len(b))
-	if n != m {
+	if len(a) != len(b) {
 		return nil, errors.New("Size of first argument is different from size of second argument.")
 	}
-	i = 0
-	result = new(curve.Point).SetInfinity()
-	for i < n {
-		result.Add(ec, result, new(curve.Point).ScalarMult(ec, a[i], b[i]))
-		i = i + 1
-	}
-	return result, nil
+	return curve.MultiScalarMult(ec, a, b), nil
 }
 
 /*
@@ -145,20 +101,37 @@ func CommitG1(ec elliptic.Curve, x, r *big.Int, h *curve.Point) (*curve.Point, e
 }
 
 /*
-Decompose receives as input a bigint x and outputs an array of integers such that
-x = sum(xi.u^i), i.e. it returns the decomposition of x into base u.
+Decompose receives as input a bigint x and outputs the array of its l lowest
+digits in base u, i.e. x = sum(xi.u^i) mod u^l. A negative x is decomposed as
+x mod u^l.
 */
-func Decompose(x *big.Int, u int64, l int64) ([]int64, error) {
-	var (
-		result []int64
-		i      int64
-	)
-	result = make([]int64, l)
-	i = 0
-	for i < l {
-		result[i] = bn.Mod(x, new(big.Int).SetInt64(u)).Int64()
-		x = new(big.Int).Div(x, new(big.Int).SetInt64(u))
-		i = i + 1
+func Decompose(x *big.Int, u *big.Int, l int64) ([]*big.Int, error) {
+	if u.Cmp(big.NewInt(2)) < 0 {
+		return nil, errors.New("base must be at least 2")
+	}
+	if l < 0 {
+		return nil, errors.New("number of digits must not be negative")
+	}
+	result := make([]*big.Int, l)
+	q := new(big.Int).Set(x)
+	for i := int64(0); i < l; i++ {
+		result[i] = new(big.Int)
+		q.DivMod(q, u, result[i])
 	}
 	return result, nil
 }
+
+/*
+powersOfTwo returns the vector (1, 2, ..., 2^(bits-1)) padded with zeros to n
+elements.
+*/
+func powersOfTwo(bits, n int64) []*big.Int {
+	result := make([]*big.Int, n)
+	for i := int64(0); i < n; i++ {
+		result[i] = new(big.Int)
+		if i < bits {
+			result[i].SetBit(result[i], int(i), 1)
+		}
+	}
+	return result
+}
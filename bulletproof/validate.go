@@ -0,0 +1,158 @@
+package bulletproofs
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/pkg/errors"
+)
+
+/*
+Proofs are plain structures and are usually decoded from JSON, so nothing
+guarantees that their points are on the curve. The Verify methods check them
+before any arithmetic; UnmarshalProof does it right after decoding.
+*/
+
+/*
+UnmarshalProof decodes a JSON encoded proof and validates it.
+*/
+func UnmarshalProof(context *gost3410.Context, data []byte) (proof BulletProof, err error) {
+	if err = json.Unmarshal(data, &proof); err != nil {
+		err = errors.Wrap(err, "cannot Unmarshal")
+		return
+	}
+	if err = proof.Validate(context); err != nil {
+		err = errors.Wrap(err, "cannot Validate")
+		return
+	}
+	return
+}
+
+/*
+Validate checks that all points of the proof and its parameters are points of
+the subgroup of order N other than the point at infinity, and that the vectors
+are long enough for N. The errors wrap the curve.PointError of the failed check.
+*/
+func (proof *BulletProof) Validate(context *gost3410.Context) error {
+	if err := proof.validateCommitments(context); err != nil {
+		return err
+	}
+	return errors.Wrap(proof.InnerProductProof.Validate(context), "invalid inner product proof")
+}
+
+// validateCommitments validates the proof except for the inner product proof.
+func (proof *BulletProof) validateCommitments(context *gost3410.Context) error {
+	params := proof.Params
+	err := validatePoints(context,
+		[]string{"V", "A", "S", "T1", "T2", "Commit"},
+		[]*curve.Point{proof.V, proof.A, proof.S, proof.T1, proof.T2, proof.Commit},
+	)
+	if err != nil {
+		return err
+	}
+	return validateParams(context, params)
+}
+
+/*
+Validate checks the points of the aggregated proof like BulletProof.Validate.
+*/
+func (proof *AggregateBulletProof) Validate(context *gost3410.Context) error {
+	if err := proof.validateCommitments(context); err != nil {
+		return err
+	}
+	return errors.Wrap(proof.InnerProductProof.Validate(context), "invalid inner product proof")
+}
+
+// validateCommitments validates the proof except for the inner product proof.
+func (proof *AggregateBulletProof) validateCommitments(context *gost3410.Context) error {
+	if len(proof.V) == 0 {
+		return errors.New("no commitments")
+	}
+	if len(proof.V) > MaxAggregation {
+		return errors.Errorf("more than %d commitments", MaxAggregation)
+	}
+	names := []string{"A", "S", "T1", "T2", "Commit"}
+	points := []*curve.Point{proof.A, proof.S, proof.T1, proof.T2, proof.Commit}
+	for j := range proof.V {
+		names = append(names, "V"+strconv.Itoa(j))
+		points = append(points, proof.V[j])
+	}
+	if err := validatePoints(context, names, points); err != nil {
+		return err
+	}
+	return validateParams(context, proof.Params)
+}
+
+// validateParams validates G, H, the first N points of the vectors and Bits.
+func validateParams(context *gost3410.Context, params BulletProofSetupParams) error {
+	if params.Bits < 0 || params.Bits > params.N {
+		return errors.New("bit-length of the range does not fit N")
+	}
+	if params.N > MaxBits {
+		return errors.Errorf("N is larger than %d", MaxBits)
+	}
+	// the generators of Setup are known to be valid
+	gens := loadGenerators(context, 1)
+	if !params.G.Equal(gens.G.Point()) || !params.H.Equal(gens.H.Point()) {
+		if err := validatePoints(context, []string{"G", "H"}, []*curve.Point{params.G, params.H}); err != nil {
+			return err
+		}
+	}
+	return validateVectors(context, params.N, params.Gg, params.Hh)
+}
+
+/*
+Validate checks the points of the inner product proof like BulletProof.Validate,
+and that there are log2(N) rounds.
+*/
+func (proof InnerProductProof) Validate(context *gost3410.Context) error {
+	if len(proof.Ls) != len(proof.Rs) || len(proof.Ls) >= 63 || proof.N != 1<<uint(len(proof.Ls)) {
+		return errors.New("wrong number of rounds")
+	}
+	if proof.A == nil || proof.B == nil {
+		return errors.New("missing scalars")
+	}
+	names := []string{"U", "P"}
+	points := []*curve.Point{proof.U, proof.Params.P}
+	for j := range proof.Ls {
+		names = append(names, "L"+strconv.Itoa(j), "R"+strconv.Itoa(j))
+		points = append(points, proof.Ls[j], proof.Rs[j])
+	}
+	if err := validatePoints(context, names, points); err != nil {
+		return err
+	}
+	return validateVectors(context, proof.N, proof.Params.Gg, proof.Params.Hh)
+}
+
+func validatePoints(context *gost3410.Context, names []string, points []*curve.Point) error {
+	for i, p := range points {
+		if err := p.Validate(context.Curve); err != nil {
+			return errors.Wrapf(err, "invalid %s", names[i])
+		}
+	}
+	return nil
+}
+
+// validateVectors validates the first n points of g and h, except those equal
+// to the generators of Setup.
+func validateVectors(context *gost3410.Context, n int64, g, h []*curve.Point) error {
+	if n <= 0 || int64(len(g)) < n || int64(len(h)) < n {
+		return errors.New("generator vectors are shorter than N")
+	}
+	gens := loadGenerators(context, n)
+	for i := 0; i < int(n); i++ {
+		if !gens.Gg.Equal(i, g[i]) {
+			if err := g[i].Validate(context.Curve); err != nil {
+				return errors.Wrapf(err, "invalid Gg[%d]", i)
+			}
+		}
+		if !gens.Hh.Equal(i, h[i]) {
+			if err := h[i].Validate(context.Curve); err != nil {
+				return errors.Wrapf(err, "invalid Hh[%d]", i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package bulletproofs
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalProof(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	params, err := Setup(context, 4)
+	require.NoError(t, err)
+	proof, err := Prove(context, big.NewInt(9), params)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(proof)
+	require.NoError(t, err)
+
+	decoded, err := UnmarshalProof(context, encoded)
+	require.NoError(t, err)
+	ok, err := decoded.Verify(context)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// a point off the curve
+	offCurve := decoded
+	offCurve.V = &curve.Point{X: proof.V.X, Y: new(big.Int).Xor(proof.V.Y, big.NewInt(1))}
+	assertInvalid(t, context, offCurve, curve.ErrNotOnCurve)
+
+	// a point with a component of order 2, the curve has a cofactor of 4
+	c := curve.GOST34102012256A
+	x, y := c.FromEdwards(new(big.Int), new(big.Int).Sub(c.P, big.NewInt(1)))
+	mixed := decoded
+	mixed.InnerProductProof.Ls = append([]*curve.Point{}, proof.InnerProductProof.Ls...)
+	mixed.InnerProductProof.Ls[0] = new(curve.Point).Add(c, proof.InnerProductProof.Ls[0], &curve.Point{X: x, Y: y})
+	assertInvalid(t, context, mixed, curve.ErrNotInSubgroup)
+
+	// the point at infinity
+	identity := decoded
+	identity.A = new(curve.Point).SetInfinity()
+	assertInvalid(t, context, identity, curve.ErrIdentity)
+
+	// generator vectors shorter than N
+	short := decoded
+	short.Params.Gg = proof.Params.Gg[:2]
+	ok, err = short.Verify(context)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func assertInvalid(t *testing.T, context *gost3410.Context, proof BulletProof, expected error) {
+	encoded, err := json.Marshal(proof)
+	require.NoError(t, err)
+	_, err = UnmarshalProof(context, encoded)
+	assert.True(t, errors.Is(err, expected), "%v", err)
+
+	ok, err := proof.Verify(context)
+	assert.True(t, errors.Is(err, expected), "%v", err)
+	assert.False(t, ok)
+}
@@ -9,6 +9,11 @@ type HashAlgorithm interface {
 	New() hash.Hash
 }
 
+// MACAlgorithm is the keyed sibling of HashAlgorithm, e.g. HMAC-Streebog.
+type MACAlgorithm interface {
+	New(key []byte) hash.Hash
+}
+
 type Context struct {
 	Curve         elliptic.Curve
 	HashAlgorithm HashAlgorithm
@@ -2,23 +2,76 @@ package curve
 
 import (
 	"crypto/elliptic"
+	"encoding/asn1"
 	"math/big"
 )
 
-var GOST34102001 *elliptic.CurveParams
+/*
+CurveParams contains the parameters of a GOST R 34.10 elliptic curve given in
+the short Weierstrass form y^2 = x^3 + a*x + b mod p. Unlike elliptic.CurveParams
+it does not assume a = -3, so it can describe every standardized parameter set.
+Params() returns the embedded elliptic.CurveParams, hence P, N, B, Gx, Gy,
+BitSize and Name are accessible through the elliptic.Curve interface.
+*/
+type CurveParams struct {
+	*elliptic.CurveParams
+	// A is the coefficient a of the Weierstrass equation.
+	A *big.Int
+	// Cofactor is the ratio of the curve order to the order N of the base point.
+	Cofactor *big.Int
+	// OID is the object identifier of the parameter set.
+	OID asn1.ObjectIdentifier
+	// Edwards is the twisted Edwards form of the curve, nil if it has none. When
+	// set, the group operations are computed in extended Edwards coordinates.
+	Edwards *EdwardsParams
+
+	arith *arithmetic
+	// base holds the tables of G, built on first use.
+	base *lazyFixedBase
+}
+
+// GOST34102001 is kept for backward compatibility, it is the CryptoPro-A parameter set.
+var GOST34102001 *CurveParams
 
 func init() {
-	initGOST34102001()
+	initCurves()
+	GOST34102001 = GOST34102001CryptoProA
+}
+
+func newCurve(name string, oid asn1.ObjectIdentifier, bitSize int, p, n, a, b, gx, gy string, cofactor int64) *CurveParams {
+	c := &CurveParams{CurveParams: &elliptic.CurveParams{Name: name}}
+	c.P, _ = new(big.Int).SetString(p, 16)
+	c.N, _ = new(big.Int).SetString(n, 16)
+	c.A, _ = new(big.Int).SetString(a, 16)
+	c.B, _ = new(big.Int).SetString(b, 16)
+	c.Gx, _ = new(big.Int).SetString(gx, 16)
+	c.Gy, _ = new(big.Int).SetString(gy, 16)
+	c.BitSize = bitSize
+	c.Cofactor = big.NewInt(cofactor)
+	c.OID = oid
+	c.arith = newArithmetic(c)
+	c.base = new(lazyFixedBase)
+	return c
+}
+
+/*
+coefficientA returns the coefficient a of the curve equation. Curves that are
+not described by CurveParams are assumed to have a = -3.
+*/
+func coefficientA(ec elliptic.Curve) *big.Int {
+	if c, ok := ec.(*CurveParams); ok {
+		return c.A
+	}
+	return new(big.Int).Sub(ec.Params().P, big.NewInt(3))
 }
 
-func initGOST34102001() {
-	// CurveParamsGostR34102001CryptoProA
-	GOST34102001 = &elliptic.CurveParams{Name: "GOST R 34.10 CryptoProA"}
-	GOST34102001.P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97", 16)
-	GOST34102001.N, _ = new(big.Int).SetString("ffffffffffffffffffffffffffffffff6c611070995ad10045841b09b761b893", 16)
-	// a = -3
-	GOST34102001.B, _ = new(big.Int).SetString("00000000000000000000000000000000000000000000000000000000000000a6", 16)
-	GOST34102001.Gx, _ = new(big.Int).SetString("0000000000000000000000000000000000000000000000000000000000000001", 16)
-	GOST34102001.Gy, _ = new(big.Int).SetString("8d91e471e0989cda27df505a453f2b7635294f2ddf23e3b122acc99c9e9f1e14", 16)
-	GOST34102001.BitSize = 256
+/*
+cofactor returns the cofactor of the curve. Curves that are not described by
+CurveParams are assumed to have a prime order.
+*/
+func cofactor(ec elliptic.Curve) *big.Int {
+	if c, ok := ec.(*CurveParams); ok && c.Cofactor != nil {
+		return c.Cofactor
+	}
+	return big.NewInt(1)
 }
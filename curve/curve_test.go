@@ -0,0 +1,88 @@
+package curve
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/martinlindhe/gogost/gost3410"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurvesParams(t *testing.T) {
+	for _, c := range Curves() {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.True(t, c.IsOnCurve(c.Gx, c.Gy), "generator must be on curve")
+			assert.True(t, c.P.BitLen() <= c.BitSize)
+
+			x, y := c.ScalarBaseMult(c.N.Bytes())
+			assert.Equal(t, 0, x.Sign(), "N*G must be infinity")
+			assert.Equal(t, 0, y.Sign(), "N*G must be infinity")
+		})
+	}
+}
+
+func TestCurvesLookup(t *testing.T) {
+	for _, c := range Curves() {
+		byOID, err := ByOID(c.OID)
+		assert.NoError(t, err)
+		assert.Equal(t, c, byOID)
+
+		byName, err := ByName(c.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, c, byName)
+	}
+
+	_, err := ByName("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, GOST34102001CryptoProA, GOST34102001)
+}
+
+func TestCurvesScalarMult(t *testing.T) {
+	for _, c := range Curves() {
+		t.Run(c.Name, func(t *testing.T) {
+			mode := c.BitSize / 8
+			ref, err := gost3410.NewCurve(
+				utils.Pad(c.P.Bytes(), mode),
+				utils.Pad(c.N.Bytes(), mode),
+				utils.Pad(c.A.Bytes(), mode),
+				utils.Pad(c.B.Bytes(), mode),
+				utils.Pad(c.Gx.Bytes(), mode),
+				utils.Pad(c.Gy.Bytes(), mode),
+			)
+			assert.NoError(t, err)
+
+			for i := 0; i < 4; i++ {
+				k := new(big.Int).SetBytes(utils.RandomBytes(mode))
+				k.Mod(k, c.N)
+
+				x, y := c.ScalarBaseMult(k.Bytes())
+				refX, refY, err := ref.Exp(k, c.Gx, c.Gy)
+				assert.NoError(t, err)
+				assert.Equal(t, refX, x)
+				assert.Equal(t, refY, y)
+				assert.True(t, c.IsOnCurve(x, y))
+
+				// 2*k*G == k*G + k*G
+				x2, y2 := c.Double(x, y)
+				x3, y3 := c.Add(x, y, x, y)
+				assert.Equal(t, x2, x3)
+				assert.Equal(t, y2, y3)
+			}
+		})
+	}
+}
+
+func TestMapToGroupCofactor(t *testing.T) {
+	for _, c := range []*CurveParams{GOST34102012256A, GOST34102012512C} {
+		p, err := MapToGroup(c, hash.GOST34112012256, "seed")
+		assert.NoError(t, err)
+		assert.True(t, p.IsOnCurve(c))
+
+		// the point must belong to the subgroup of order N
+		x, y := c.ScalarMult(p.X, p.Y, c.N.Bytes())
+		assert.Equal(t, 0, x.Sign())
+		assert.Equal(t, 0, y.Sign())
+	}
+}
@@ -0,0 +1,108 @@
+package curve
+
+import (
+	"math/big"
+)
+
+/*
+EdwardsParams contains the twisted Edwards form e*u^2 + v^2 = 1 + d*u^2*v^2 of
+a curve (R 1323565.1.024-2019). The Weierstrass form of CurveParams is related to
+it by
+
+	x = s*(1 + v)/(1 - v) + t, y = s*(1 + v)/((1 - v)*u)
+	u = (x - t)/y,             v = (x - t - s)/(x - t + s)
+
+where s = (e - d)/4 and t = (e + d)/6.
+*/
+type EdwardsParams struct {
+	E *big.Int
+	D *big.Int
+	// U and V are the coordinates of the base point.
+	U *big.Int
+	V *big.Int
+
+	s *big.Int
+	t *big.Int
+}
+
+func (curve *CurveParams) withEdwards(e, d, u, v string) *CurveParams {
+	p := curve.P
+	ed := &EdwardsParams{}
+	ed.E, _ = new(big.Int).SetString(e, 16)
+	ed.D, _ = new(big.Int).SetString(d, 16)
+	ed.U, _ = new(big.Int).SetString(u, 16)
+	ed.V, _ = new(big.Int).SetString(v, 16)
+
+	// s = (e - d)/4, t = (e + d)/6
+	ed.s = new(big.Int).Sub(ed.E, ed.D)
+	ed.s.Mul(ed.s, new(big.Int).ModInverse(big.NewInt(4), p))
+	ed.s.Mod(ed.s, p)
+	ed.t = new(big.Int).Add(ed.E, ed.D)
+	ed.t.Mul(ed.t, new(big.Int).ModInverse(big.NewInt(6), p))
+	ed.t.Mod(ed.t, p)
+
+	curve.Edwards = ed
+	curve.arith = newArithmetic(curve)
+	return curve
+}
+
+/*
+ToEdwards converts a point given in Weierstrass affine coordinates to twisted
+Edwards affine coordinates. The point at infinity (0, 0) is mapped to the
+neutral element (0, 1).
+*/
+func (curve *CurveParams) ToEdwards(x, y *big.Int) (u, v *big.Int) {
+	p := curve.P
+	ed := curve.Edwards
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return new(big.Int), big.NewInt(1)
+	}
+	if y.Sign() == 0 {
+		// (t, 0) is the point of order 2
+		return new(big.Int), new(big.Int).Sub(p, big.NewInt(1))
+	}
+
+	xt := new(big.Int).Sub(x, ed.t)
+	// u = (x - t)/y
+	u = new(big.Int).ModInverse(y, p)
+	u.Mul(u, xt)
+	u.Mod(u, p)
+	// v = (x - t - s)/(x - t + s)
+	v = new(big.Int).Add(xt, ed.s)
+	v.ModInverse(v.Mod(v, p), p)
+	v.Mul(v, new(big.Int).Sub(xt, ed.s))
+	v.Mod(v, p)
+	return
+}
+
+/*
+FromEdwards converts a point given in twisted Edwards affine coordinates to
+Weierstrass affine coordinates. The neutral element (0, 1) is mapped to the point
+at infinity (0, 0).
+*/
+func (curve *CurveParams) FromEdwards(u, v *big.Int) (x, y *big.Int) {
+	p := curve.P
+	ed := curve.Edwards
+	if u.Sign() == 0 {
+		if v.Cmp(big.NewInt(1)) == 0 {
+			return new(big.Int), new(big.Int)
+		}
+		// (0, -1) is the point of order 2
+		return new(big.Int).Set(ed.t), new(big.Int)
+	}
+
+	// w = s*(1 + v)/(1 - v)
+	w := new(big.Int).Sub(big.NewInt(1), v)
+	w.ModInverse(w.Mod(w, p), p)
+	w.Mul(w, new(big.Int).Add(big.NewInt(1), v))
+	w.Mul(w, ed.s)
+	w.Mod(w, p)
+
+	// x = w + t, y = w/u
+	x = new(big.Int).Add(w, ed.t)
+	x.Mod(x, p)
+	y = new(big.Int).ModInverse(u, p)
+	y.Mul(y, w)
+	y.Mod(y, p)
+	return
+}
@@ -0,0 +1,70 @@
+package curve
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdwardsBasePoint(t *testing.T) {
+	for _, c := range []*CurveParams{GOST34102012256A, GOST34102012512C} {
+		u, v := c.ToEdwards(c.Gx, c.Gy)
+		assert.Equal(t, c.Edwards.U, u)
+		assert.Equal(t, c.Edwards.V, v)
+
+		x, y := c.FromEdwards(c.Edwards.U, c.Edwards.V)
+		assert.Equal(t, c.Gx, x)
+		assert.Equal(t, c.Gy, y)
+	}
+}
+
+func TestEdwardsSpecialPoints(t *testing.T) {
+	c := GOST34102012256A
+
+	// the point at infinity is the neutral element (0, 1)
+	u, v := c.ToEdwards(new(big.Int), new(big.Int))
+	assert.Equal(t, 0, u.Sign())
+	assert.Equal(t, int64(1), v.Int64())
+
+	// (t, 0) is the point of order 2, (0, -1)
+	x, y := c.FromEdwards(new(big.Int), new(big.Int).Sub(c.P, big.NewInt(1)))
+	assert.True(t, c.IsOnCurve(x, y))
+	assert.Equal(t, 0, y.Sign())
+	x2, y2 := c.Double(x, y)
+	assert.Equal(t, 0, x2.Sign())
+	assert.Equal(t, 0, y2.Sign())
+
+	// P + (-P) is the point at infinity
+	nx, ny := c.Add(c.Gx, c.Gy, c.Gx, new(big.Int).Sub(c.P, c.Gy))
+	assert.Equal(t, 0, nx.Sign())
+	assert.Equal(t, 0, ny.Sign())
+}
+
+func TestEdwardsMatchesWeierstrass(t *testing.T) {
+	for _, c := range []*CurveParams{GOST34102012256A, GOST34102012512C} {
+		weierstrass := *c
+		weierstrass.Edwards = nil
+
+		mode := c.BitSize / 8
+		k1 := utils.RandomBytes(mode)
+		k2 := utils.RandomBytes(mode)
+
+		x1, y1 := c.ScalarBaseMult(k1)
+		wx1, wy1 := weierstrass.ScalarBaseMult(k1)
+		assert.Equal(t, wx1, x1)
+		assert.Equal(t, wy1, y1)
+
+		x2, y2 := c.ScalarMult(x1, y1, k2)
+		wx2, wy2 := weierstrass.ScalarMult(wx1, wy1, k2)
+		assert.Equal(t, wx2, x2)
+		assert.Equal(t, wy2, y2)
+
+		x3, y3 := c.Add(x1, y1, x2, y2)
+		wx3, wy3 := weierstrass.Add(x1, y1, x2, y2)
+		assert.Equal(t, wx3, x3)
+		assert.Equal(t, wy3, y3)
+		assert.True(t, c.IsOnCurve(x3, y3))
+	}
+}
@@ -0,0 +1,316 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"reflect"
+	"sync"
+
+	"github.com/AllFi/go-gost3410"
+)
+
+/*
+This file implements scalar multiplication by points that are known in advance,
+such as the base point G and the generator H of Pedersen commitments. Their
+multiples are computed once and kept in homogeneous coordinates, so that both
+the variable-time and the constant-time code of secret.go can use them.
+
+FixedBaseG and FixedBaseH return tables that are built on first use and shared
+by all contexts with the same curve and hash algorithm.
+*/
+
+/*
+FixedBase is a point P with the precomputed multiples j*16^i*P, 0 <= j < 16, for
+every 4-bit window i of a scalar. A multiplication takes one addition per window
+and no doubling. The tables of a 256-bit curve take about 300KB, those of a
+512-bit curve twice as much. A FixedBase is never modified once built and may be
+used from several goroutines.
+*/
+type FixedBase struct {
+	ec    elliptic.Curve
+	point *Point
+	// windows is nil for curves that are not described by CurveParams.
+	curve   *CurveParams
+	windows [][]*ProjectivePoint
+}
+
+/*
+NewFixedBase computes the tables of p.
+*/
+func NewFixedBase(ec elliptic.Curve, p *Point) *FixedBase {
+	b := &FixedBase{ec: ec, point: p.Clone()}
+	curve, ok := ec.(*CurveParams)
+	if !ok {
+		return b
+	}
+
+	b.curve = curve
+	b.windows = make([][]*ProjectivePoint, 2*scalarLen(curve))
+	base := new(ProjectivePoint).toHomogeneous(NewProjectivePoint(curve, p))
+	for i := range b.windows {
+		b.windows[i] = homogeneousTable(base)
+		// base = 16^(i+1)*P
+		base = new(ProjectivePoint).Set(b.windows[i][1])
+		for j := 0; j < strausWindow; j++ {
+			base.completeDouble(base)
+		}
+	}
+	return b
+}
+
+/*
+Point returns a copy of the base point.
+*/
+func (b *FixedBase) Point() *Point {
+	return b.point.Clone()
+}
+
+/*
+ScalarMult returns k*P. Its running time depends on k, so it must only be used
+with public scalars.
+*/
+func (b *FixedBase) ScalarMult(k *big.Int) *Point {
+	if b.windows == nil {
+		return new(Point).ScalarMult(b.ec, b.point, k)
+	}
+	digits := secretScalar(b.curve, k)
+	result := homogeneousIdentity(b.curve, b.curve.arithmetic())
+	for i, table := range b.windows {
+		if digit := windowDigit(digits, i); digit != 0 {
+			result.completeAdd(result, table[digit])
+		}
+	}
+	return affinePoint(result)
+}
+
+/*
+ScalarMultSecret returns k*P in constant time with respect to k.
+*/
+func (b *FixedBase) ScalarMultSecret(k *big.Int) *Point {
+	if b.windows == nil {
+		return new(Point).ScalarMultSecret(b.ec, b.point, k)
+	}
+	digits := secretScalar(b.curve, k)
+	result := homogeneousIdentity(b.curve, b.curve.arithmetic())
+	entry := new(ProjectivePoint)
+	for i, table := range b.windows {
+		entry.lookup(table, windowDigit(digits, i))
+		result.completeAdd(result, entry)
+	}
+	return affinePoint(result)
+}
+
+/*
+FixedBases is a vector of points with their precomputed multiples j*P_i,
+0 <= j < 16, which saves building these tables in every multi-scalar
+multiplication. A FixedBases is never modified once built and may be used from
+several goroutines.
+*/
+type FixedBases struct {
+	ec     elliptic.Curve
+	points []*Point
+	// tables is nil for curves that are not described by CurveParams.
+	curve  *CurveParams
+	tables [][]*ProjectivePoint
+}
+
+/*
+NewFixedBases computes the tables of points.
+*/
+func NewFixedBases(ec elliptic.Curve, points []*Point) *FixedBases {
+	b := &FixedBases{ec: ec, points: make([]*Point, len(points))}
+	for i, p := range points {
+		b.points[i] = p.Clone()
+	}
+	curve, ok := ec.(*CurveParams)
+	if !ok {
+		return b
+	}
+
+	b.curve = curve
+	b.tables = make([][]*ProjectivePoint, len(points))
+	for i, p := range points {
+		b.tables[i] = secretTable(NewProjectivePoint(curve, p))
+	}
+	return b
+}
+
+/*
+Append returns the bases followed by points. The tables of b are shared, only
+those of points are computed, and b itself is left as it is.
+*/
+func (b *FixedBases) Append(points []*Point) *FixedBases {
+	extended := NewFixedBases(b.ec, points)
+	extended.points = append(b.points[:len(b.points):len(b.points)], extended.points...)
+	if b.tables != nil {
+		extended.tables = append(b.tables[:len(b.tables):len(b.tables)], extended.tables...)
+	}
+	return extended
+}
+
+/*
+Equal reports whether p is the i-th point, without copying it. It panics if i is
+out of range.
+*/
+func (b *FixedBases) Equal(i int, p *Point) bool {
+	return b.points[i].Equal(p)
+}
+
+/*
+Len returns the number of points.
+*/
+func (b *FixedBases) Len() int {
+	return len(b.points)
+}
+
+/*
+Points returns a copy of the points.
+*/
+func (b *FixedBases) Points() []*Point {
+	points := make([]*Point, len(b.points))
+	for i, p := range b.points {
+		points[i] = p.Clone()
+	}
+	return points
+}
+
+/*
+MultiScalarMult returns sum(scalars[i]*P_i) over the first len(scalars) points.
+Its running time depends on the scalars, so it must only be used with public
+ones. It panics if there are more scalars than points.
+*/
+func (b *FixedBases) MultiScalarMult(scalars []*big.Int) *Point {
+	if len(scalars) > len(b.points) {
+		panic("curve: FixedBases.MultiScalarMult with more scalars than points")
+	}
+	if b.tables == nil {
+		return MultiScalarMult(b.ec, b.points[:len(scalars)], scalars)
+	}
+
+	n := b.curve.N
+	reduced := make([]*big.Int, len(scalars))
+	maxBits := 0
+	for i, k := range scalars {
+		reduced[i] = new(big.Int)
+		if k != nil {
+			reduced[i].Mod(k, n)
+		}
+		if reduced[i].BitLen() > maxBits {
+			maxBits = reduced[i].BitLen()
+		}
+	}
+
+	result := homogeneousIdentity(b.curve, b.curve.arithmetic())
+	for w := (maxBits+strausWindow-1)/strausWindow - 1; w >= 0; w-- {
+		for j := 0; j < strausWindow; j++ {
+			result.completeDouble(result)
+		}
+		for i, k := range reduced {
+			if digit := window(k, w*strausWindow, strausWindow); digit != 0 {
+				result.completeAdd(result, b.tables[i][digit])
+			}
+		}
+	}
+	return affinePoint(result)
+}
+
+/*
+MultiScalarMultSecret returns sum(scalars[i]*P_i) over the first len(scalars)
+points in constant time with respect to the scalars. It panics if there are more
+scalars than points.
+*/
+func (b *FixedBases) MultiScalarMultSecret(scalars []*big.Int) *Point {
+	if len(scalars) > len(b.points) {
+		panic("curve: FixedBases.MultiScalarMultSecret with more scalars than points")
+	}
+	if b.tables == nil {
+		return MultiScalarMultSecret(b.ec, b.points[:len(scalars)], scalars)
+	}
+	return affinePoint(strausSecret(b.curve, b.tables[:len(scalars)], scalars))
+}
+
+/*
+FixedBaseG returns the tables of the base point G of the curve of context.
+*/
+func FixedBaseG(context *gost3410.Context) *FixedBase {
+	if curve, ok := context.Curve.(*CurveParams); ok {
+		if table := curve.baseTable(); table != nil {
+			return table
+		}
+	}
+	return NewFixedBase(context.Curve, GeneratorG(context).Point)
+}
+
+// hBases holds a *lazyFixedBase with the tables of GeneratorH per ContextKey.
+var hBases sync.Map
+
+/*
+FixedBaseH returns the tables of GeneratorH.
+*/
+func FixedBaseH(context *gost3410.Context) *FixedBase {
+	build := func() *FixedBase {
+		return NewFixedBase(context.Curve, NewGenerator(context, GeneratorG(context).Bytes(context.Curve)).Point)
+	}
+	key, ok := NewContextKey(context)
+	if !ok {
+		return build()
+	}
+	lazy, _ := hBases.LoadOrStore(key, new(lazyFixedBase))
+	return lazy.(*lazyFixedBase).get(build)
+}
+
+/*
+ContextKey identifies the curve and the hash algorithm of a context, it can be
+used as a map key for values derived from them.
+*/
+type ContextKey struct {
+	curve         elliptic.Curve
+	hashAlgorithm gost3410.HashAlgorithm
+}
+
+/*
+NewContextKey returns the key of context. It fails if the curve or the hash
+algorithm is of a type that cannot be compared.
+*/
+func NewContextKey(context *gost3410.Context) (key ContextKey, ok bool) {
+	for _, v := range []interface{}{context.Curve, context.HashAlgorithm} {
+		if v != nil && !reflect.TypeOf(v).Comparable() {
+			return
+		}
+	}
+	return ContextKey{context.Curve, context.HashAlgorithm}, true
+}
+
+// lazyFixedBase builds a FixedBase on first use.
+type lazyFixedBase struct {
+	once  sync.Once
+	table *FixedBase
+}
+
+func (l *lazyFixedBase) get(build func() *FixedBase) *FixedBase {
+	l.once.Do(func() {
+		l.table = build()
+	})
+	return l.table
+}
+
+// baseTable returns the tables of G, or nil for curves that were not built by
+// this package.
+func (curve *CurveParams) baseTable() *FixedBase {
+	if curve.base == nil {
+		return nil
+	}
+	return curve.base.get(func() *FixedBase {
+		return NewFixedBase(curve, &Point{X: curve.Gx, Y: curve.Gy})
+	})
+}
+
+// windowDigit returns the i-th 4-bit window of the big-endian digits, counted
+// from the least significant one.
+func windowDigit(digits []byte, i int) byte {
+	b := digits[len(digits)-1-i/2]
+	if i%2 == 1 {
+		return b >> 4
+	}
+	return b & 0xf
+}
@@ -0,0 +1,147 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedBase(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256()}
+	for _, c := range Curves() {
+		curves = append(curves, c)
+	}
+	for _, ec := range curves {
+		t.Run(ec.Params().Name, func(t *testing.T) {
+			context := gost3410.NewContext(ec, hash.GOST34112012256)
+			n := ec.Params().N
+			mode := ec.Params().BitSize / 8
+			scalars := []*big.Int{
+				new(big.Int),
+				big.NewInt(1),
+				big.NewInt(16),
+				new(big.Int).Sub(n, big.NewInt(1)),
+				n,
+				big.NewInt(-5),
+				new(big.Int).SetBytes(utils.RandomBytes(mode)),
+			}
+
+			points, _ := randomTerms(ec, 1)
+			bases := []*FixedBase{FixedBaseG(context), FixedBaseH(context), NewFixedBase(ec, points[0])}
+			assertSamePoint(t, GeneratorG(context).Point, bases[0].Point())
+			assertSamePoint(t, NewGenerator(context, GeneratorG(context).Bytes(ec)).Point, bases[1].Point())
+			for _, b := range bases {
+				for _, k := range scalars {
+					expected := new(Point).ScalarMult(ec, b.Point(), new(big.Int).Mod(k, n))
+					assertSamePoint(t, expected, b.ScalarMult(k), "k = %v", k)
+					assertSamePoint(t, expected, b.ScalarMultSecret(k), "k = %v", k)
+				}
+			}
+
+			// ScalarBaseMult goes through the tables of G
+			k := scalars[len(scalars)-1]
+			x, y := ec.ScalarBaseMult(k.Bytes())
+			assertSamePoint(t, bases[0].ScalarMult(k), &Point{X: x, Y: y})
+			assertSamePoint(t, bases[0].ScalarMult(k), new(Point).ScalarBaseMultSecret(ec, k))
+
+			identity := NewFixedBase(ec, new(Point).SetInfinity())
+			assert.True(t, identity.ScalarMult(k).IsZero())
+			assert.True(t, identity.ScalarMultSecret(k).IsZero())
+		})
+	}
+}
+
+func TestFixedBases(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256()}
+	for _, c := range Curves() {
+		curves = append(curves, c)
+	}
+	for _, ec := range curves {
+		t.Run(ec.Params().Name, func(t *testing.T) {
+			points, scalars := randomTerms(ec, 6)
+			scalars[1] = new(big.Int)
+			scalars[2] = new(big.Int).Neg(scalars[2])
+			points[3] = new(Point).SetInfinity()
+			bases := NewFixedBases(ec, points)
+			assert.Equal(t, len(points), bases.Len())
+
+			for _, n := range []int{0, 1, 4, 6} {
+				expected := naiveMultiScalarMult(ec, points[:n], scalars[:n])
+				assertSamePoint(t, expected, bases.MultiScalarMult(scalars[:n]), "n = %d", n)
+				assertSamePoint(t, expected, bases.MultiScalarMultSecret(scalars[:n]), "n = %d", n)
+			}
+
+			// the points are copies
+			bases.Points()[0].X.SetInt64(1)
+			assertSamePoint(t, points[0], bases.Points()[0])
+			assert.True(t, bases.Equal(0, points[0]))
+			assert.False(t, bases.Equal(1, points[0]))
+
+			// appending leaves the original bases alone
+			head := NewFixedBases(ec, points[:2])
+			appended := head.Append(points[2:])
+			assert.Equal(t, 2, head.Len())
+			assert.Equal(t, len(points), appended.Len())
+			for i := range points {
+				assert.True(t, appended.Equal(i, points[i]))
+			}
+			expected := naiveMultiScalarMult(ec, points, scalars)
+			assertSamePoint(t, expected, appended.MultiScalarMult(scalars))
+			assertSamePoint(t, expected, appended.MultiScalarMultSecret(scalars))
+
+			assert.Panics(t, func() {
+				bases.MultiScalarMult(make([]*big.Int, 7))
+			})
+		})
+	}
+}
+
+func TestFixedBaseConcurrent(t *testing.T) {
+	context := gost3410.NewContext(GOST34102012512B, hash.GOST34112012512)
+	k := new(big.Int).SetBytes(utils.RandomBytes(64))
+	expected := new(Point).ScalarMult(context.Curve, GeneratorH(context).Point, k)
+
+	var wg sync.WaitGroup
+	tables := make([]*FixedBase, 8)
+	for i := range tables {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every context with the same curve and hash shares the tables
+			tables[i] = FixedBaseH(gost3410.NewContext(GOST34102012512B, hash.GOST34112012512))
+			assertSamePoint(t, expected, tables[i].ScalarMultSecret(k))
+		}(i)
+	}
+	wg.Wait()
+	for _, table := range tables {
+		assert.True(t, table == tables[0])
+	}
+
+	key, ok := NewContextKey(context)
+	assert.True(t, ok)
+	other, _ := NewContextKey(gost3410.NewContext(GOST34102012512B, hash.GOST34112012256))
+	assert.NotEqual(t, key, other)
+}
+
+func BenchmarkFixedBase(b *testing.B) {
+	for _, c := range []*CurveParams{GOST34102001, GOST34102012256A, GOST34102012512A} {
+		table := c.baseTable()
+		k := new(big.Int).SetBytes(utils.RandomBytes(c.BitSize / 8))
+		b.Run(c.Name+"/public", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				table.ScalarMult(k)
+			}
+		})
+		b.Run(c.Name+"/secret", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				table.ScalarMultSecret(k)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package curve
 
 import (
-	"encoding/hex"
+	"math/big"
 
 	"github.com/AllFi/go-gost3410"
 )
@@ -10,15 +10,28 @@ type Generator struct {
 	*Point
 }
 
+/*
+GeneratorDST is the domain separation tag under which NewGenerator hashes its
+seed to the curve.
+*/
+const GeneratorDST = "GOST3410-V01-GENERATOR-XMD-SSWU-RO"
+
+/*
+NewGenerator returns the point HashToCurve(seed) with GeneratorDST and the hash
+algorithm of the context, nobody knows its discrete logarithm to G.
+*/
 func NewGenerator(context *gost3410.Context, seed []byte) (generator *Generator) {
-	point, _ := MapToGroup(context.Curve, context.HashAlgorithm, hex.EncodeToString(seed))
+	point, _ := HashToCurve(context.Curve, context.HashAlgorithm, seed, []byte(GeneratorDST))
 	return &Generator{point}
 }
 
 func GeneratorG(context *gost3410.Context) (generator *Generator) {
-	return &Generator{&Point{context.Curve.Params().Gx, context.Curve.Params().Gy}}
+	return &Generator{new(Point).setAffine(new(big.Int).Set(context.Curve.Params().Gx), new(big.Int).Set(context.Curve.Params().Gy))}
 }
 
+/*
+GeneratorH returns NewGenerator of the encoding X||Y of G.
+*/
 func GeneratorH(context *gost3410.Context) (generator *Generator) {
-	return NewGenerator(context, GeneratorG(context).Bytes(context.Curve))
+	return &Generator{FixedBaseH(context).Point()}
 }
@@ -0,0 +1,229 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"reflect"
+	"sync"
+
+	"github.com/AllFi/go-gost3410"
+	gghash "github.com/AllFi/go-gost3410/hash"
+	"github.com/pkg/errors"
+)
+
+/*
+HashToCurve is hash_to_curve of RFC 9380 with expand_message_xmd over hashAlg and
+the simplified SWU map. Every GOST R 34.10 parameter set has a, b != 0, so no
+isogeny is needed. The result is in the subgroup of order N; dst is the domain
+separation tag and must not be empty. With elliptic.P256() and SHA-256 this is
+the suite P256_XMD:SHA-256_SSWU_RO_.
+
+Unlike MapToGroup it takes no counter and no retries: every message maps to a
+point.
+*/
+func HashToCurve(ec elliptic.Curve, hashAlg gost3410.HashAlgorithm, msg, dst []byte) (p *Point, err error) {
+	z, err := sswuZ(ec)
+	if err != nil {
+		return
+	}
+	u, err := HashToField(ec, hashAlg, msg, dst, 2)
+	if err != nil {
+		return
+	}
+	p = new(Point).Add(ec, mapToCurveSSWU(ec, z, u[0]), mapToCurveSSWU(ec, z, u[1]))
+	if h := cofactor(ec); h.Cmp(big.NewInt(1)) != 0 {
+		p.ScalarMult(ec, p, h)
+	}
+	return
+}
+
+/*
+HashToField is hash_to_field of RFC 9380 for the prime field of the curve: it
+returns count elements of [0, P) derived from msg and dst with
+expand_message_xmd. Each element takes L = ceil((ceil(log2(P)) + k) / 8) bytes,
+with the security level k being half the bit size of the curve, so that the
+bias of the reduction is negligible.
+*/
+func HashToField(ec elliptic.Curve, hashAlg gost3410.HashAlgorithm, msg, dst []byte, count int) (u []*big.Int, err error) {
+	P := ec.Params().P
+	L := (P.BitLen() + ec.Params().BitSize/2 + 7) / 8
+	uniform, err := gghash.ExpandMessageXMD(hashAlg, msg, dst, count*L)
+	if err != nil {
+		err = errors.Wrap(err, "cannot ExpandMessageXMD")
+		return
+	}
+	u = make([]*big.Int, count)
+	for i := range u {
+		u[i] = new(big.Int).SetBytes(uniform[i*L : (i+1)*L])
+		u[i].Mod(u[i], P)
+	}
+	return
+}
+
+/*
+mapToCurveSSWU is map_to_curve_simple_swu of RFC 9380, section 6.6.2:
+
+	tv1 = inv0(Z^2 * u^4 + Z * u^2)
+	x1 = (-B / A) * (1 + tv1), or B / (Z * A) if tv1 = 0
+	x = x1 if g(x1) is square, Z * u^2 * x1 otherwise
+	y = sqrt(g(x)) with the parity of u
+*/
+func mapToCurveSSWU(ec elliptic.Curve, z, u *big.Int) *Point {
+	P, A, B := ec.Params().P, coefficientA(ec), ec.Params().B
+
+	zu2 := new(big.Int).Mul(u, u)
+	zu2.Mul(zu2, z).Mod(zu2, P)
+	tv1 := new(big.Int).Mul(zu2, zu2)
+	tv1.Add(tv1, zu2).Mod(tv1, P)
+
+	x1 := new(big.Int)
+	if tv1.Sign() == 0 {
+		x1.Mul(z, A).ModInverse(x1, P)
+		x1.Mul(x1, B)
+	} else {
+		tv1.ModInverse(tv1, P)
+		tv1.Add(tv1, big.NewInt(1))
+		x1.ModInverse(A, P)
+		x1.Mul(x1, B).Neg(x1)
+		x1.Mul(x1, tv1)
+	}
+	x1.Mod(x1, P)
+
+	x := x1
+	gx, _ := F(ec, x)
+	if big.Jacobi(gx, P) < 0 {
+		x = new(big.Int).Mul(zu2, x1)
+		x.Mod(x, P)
+		gx, _ = F(ec, x)
+	}
+	y := new(big.Int).ModSqrt(gx, P)
+	if y.Bit(0) != u.Bit(0) {
+		y.Sub(P, y).Mod(y, P)
+	}
+	return &Point{X: x, Y: y}
+}
+
+// sswuZs holds the Z of sswuZ per curve.
+var sswuZs sync.Map
+
+/*
+sswuZ returns the Z parameter of the simplified SWU map, found like the sage
+script of RFC 9380, appendix H.2: the first of 1, -1, 2, -2, ... that is not a
+square and not -1, such that g(x) - Z is irreducible and g(B / (Z * A)) is a
+square, where g(x) = x^3 + A * x + B. For P-256 it is -10 like in the RFC.
+*/
+func sswuZ(ec elliptic.Curve) (z *big.Int, err error) {
+	cacheable := reflect.TypeOf(ec).Comparable()
+	if cacheable {
+		if value, ok := sswuZs.Load(ec); ok {
+			return value.(*big.Int), nil
+		}
+	}
+
+	P, A, B := ec.Params().P, coefficientA(ec), ec.Params().B
+	if new(big.Int).Mod(A, P).Sign() == 0 || new(big.Int).Mod(B, P).Sign() == 0 {
+		return nil, errors.New("simplified SWU needs a curve with a, b != 0")
+	}
+	minusOne := new(big.Int).Sub(P, big.NewInt(1))
+	for ctr := int64(1); ctr < 1000; ctr++ {
+		for _, candidate := range []*big.Int{big.NewInt(ctr), big.NewInt(-ctr)} {
+			candidate.Mod(candidate, P)
+			if big.Jacobi(candidate, P) >= 0 || candidate.Cmp(minusOne) == 0 {
+				continue
+			}
+			// g(x) - Z is a cubic, it is irreducible if it has no root
+			if hasRoot(P, A, new(big.Int).Sub(B, candidate)) {
+				continue
+			}
+			x := new(big.Int).Mul(candidate, A)
+			x.ModInverse(x, P).Mul(x, B)
+			gx, _ := F(ec, x.Mod(x, P))
+			if big.Jacobi(gx, P) < 0 {
+				continue
+			}
+			if cacheable {
+				sswuZs.Store(ec, candidate)
+			}
+			return candidate, nil
+		}
+	}
+	return nil, errors.New("cannot find the Z of simplified SWU")
+}
+
+/*
+hasRoot reports whether x^3 + a*x + b has a root mod P, that is whether
+gcd(x^P - x, x^3 + a*x + b) is not constant.
+*/
+func hasRoot(P, a, b *big.Int) bool {
+	f := []*big.Int{new(big.Int).Mod(b, P), new(big.Int).Mod(a, P), new(big.Int), big.NewInt(1)}
+
+	// x^P mod f by square-and-multiply
+	r := []*big.Int{big.NewInt(1)}
+	for i := P.BitLen() - 1; i >= 0; i-- {
+		r = polyMod(polyMul(r, r, P), f, P)
+		if P.Bit(i) == 1 {
+			r = polyMod(polyMul(r, []*big.Int{new(big.Int), big.NewInt(1)}, P), f, P)
+		}
+	}
+	for len(r) < 2 {
+		r = append(r, new(big.Int))
+	}
+	r[1].Sub(r[1], big.NewInt(1)).Mod(r[1], P)
+
+	// Euclid
+	a0, b0 := f, polyTrim(r)
+	for len(b0) > 0 {
+		a0, b0 = b0, polyMod(a0, b0, P)
+	}
+	return len(a0) > 1
+}
+
+// polyMul returns a*b mod P, the coefficients are listed from the constant one.
+func polyMul(a, b []*big.Int, P *big.Int) []*big.Int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	c := make([]*big.Int, len(a)+len(b)-1)
+	for i := range c {
+		c[i] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i := range a {
+		for j := range b {
+			c[i+j].Add(c[i+j], t.Mul(a[i], b[j]))
+		}
+	}
+	for i := range c {
+		c[i].Mod(c[i], P)
+	}
+	return polyTrim(c)
+}
+
+// polyMod returns a mod b for a non-zero b.
+func polyMod(a, b []*big.Int, P *big.Int) []*big.Int {
+	r := make([]*big.Int, len(a))
+	for i := range a {
+		r[i] = new(big.Int).Set(a[i])
+	}
+	r = polyTrim(r)
+	lead := new(big.Int).ModInverse(b[len(b)-1], P)
+	t := new(big.Int)
+	for len(r) >= len(b) {
+		// subtract q * x^shift * b, cancelling the leading coefficient of r
+		q := new(big.Int).Mul(r[len(r)-1], lead)
+		shift := len(r) - len(b)
+		for i := range b {
+			r[shift+i].Sub(r[shift+i], t.Mul(q, b[i])).Mod(r[shift+i], P)
+		}
+		r = polyTrim(r)
+	}
+	return r
+}
+
+// polyTrim drops the zero leading coefficients.
+func polyTrim(a []*big.Int) []*big.Int {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
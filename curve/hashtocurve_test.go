@@ -0,0 +1,76 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashToCurve(t *testing.T) {
+	dst := []byte("GOST3410-V01-CS01-test")
+	for _, c := range Curves() {
+		t.Run(c.Name, func(t *testing.T) {
+			p, err := HashToCurve(c, hash.GOST34112012256, []byte("abc"), dst)
+			require.NoError(t, err)
+			assert.NoError(t, p.Validate(c))
+
+			// the message, the tag and the hash are all bound to the point
+			for _, other := range []struct {
+				ha       gost3410.HashAlgorithm
+				msg, dst string
+			}{
+				{hash.GOST34112012256, "abd", string(dst)},
+				{hash.GOST34112012256, "abc", "GOST3410-V01-CS02-test"},
+				{hash.GOST34112012512, "abc", string(dst)},
+			} {
+				q, err := HashToCurve(c, other.ha, []byte(other.msg), []byte(other.dst))
+				require.NoError(t, err)
+				assert.NoError(t, q.Validate(c))
+				assert.False(t, p.Equal(q))
+			}
+		})
+	}
+
+	_, err := HashToCurve(GOST34102012256A, hash.GOST34112012256, []byte("abc"), nil)
+	assert.Error(t, err)
+
+	// simplified SWU needs a, b != 0
+	c := *GOST34102012256B
+	c.A = new(big.Int)
+	_, err = HashToCurve(&c, hash.GOST34112012256, []byte("abc"), dst)
+	assert.Error(t, err)
+}
+
+func TestSSWUZ(t *testing.T) {
+	// RFC 9380, section 8.2
+	z, err := sswuZ(elliptic.P256())
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Sub(elliptic.P256().Params().P, big.NewInt(10)), z)
+
+	for _, c := range Curves() {
+		z, err := sswuZ(c)
+		require.NoError(t, err, c.Name)
+		assert.Equal(t, -1, big.Jacobi(z, c.P), c.Name)
+		assert.False(t, hasRoot(c.P, c.A, new(big.Int).Sub(c.B, z)), c.Name)
+	}
+
+	// x^3 + x + 1 has no root mod 7, x^3 + 6x has 0
+	p := big.NewInt(7)
+	assert.False(t, hasRoot(p, big.NewInt(1), big.NewInt(1)))
+	assert.True(t, hasRoot(p, big.NewInt(6), big.NewInt(0)))
+	assert.True(t, hasRoot(p, big.NewInt(0), big.NewInt(1)))
+}
+
+func TestGeneratorH(t *testing.T) {
+	context := gost3410.NewContext(GOST34102012512C, hash.GOST34112012512)
+	g := GeneratorG(context)
+	expected, err := HashToCurve(context.Curve, context.HashAlgorithm, g.Bytes(context.Curve), []byte(GeneratorDST))
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(GeneratorH(context).Point))
+	assert.True(t, expected.Equal(NewGenerator(context, g.Bytes(context.Curve)).Point))
+}
@@ -0,0 +1,298 @@
+/*
+Package field implements arithmetic modulo the primes of the GOST R 34.10 curves
+in Montgomery form over fixed 64-bit limbs. A Field works for any odd modulus of
+up to 512 bits; 256-bit primes take four limbs and 512-bit primes eight.
+
+Elements are kept as x*R mod p with R = 2^(64*limbs). Add, Sub, Neg and Mul do
+not branch on the values of their operands.
+*/
+package field
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// MaxLimbs is the number of 64-bit limbs of the largest supported modulus.
+const MaxLimbs = 8
+
+/*
+Element is a field element in Montgomery form. Only the first Field.Limbs()
+limbs are used, least significant first. The zero value is 0.
+*/
+type Element [MaxLimbs]uint64
+
+/*
+Field is the field of integers modulo an odd prime p.
+*/
+type Field struct {
+	n       int
+	p       Element
+	pInv    uint64 // -p^-1 mod 2^64
+	r2      Element
+	one     Element
+	pMinus2 *big.Int
+	modulus *big.Int
+}
+
+/*
+New returns the field modulo p, which must be an odd number of at most 512 bits.
+*/
+func New(p *big.Int) (f *Field, err error) {
+	if p.Sign() <= 0 || p.Bit(0) == 0 || p.Cmp(big.NewInt(3)) < 0 {
+		err = errors.New("modulus must be an odd number greater than 2")
+		return
+	}
+	if p.BitLen() > 64*MaxLimbs {
+		err = errors.Errorf("modulus must be at most %d bits long", 64*MaxLimbs)
+		return
+	}
+
+	f = &Field{
+		n:       (p.BitLen() + 63) / 64,
+		modulus: new(big.Int).Set(p),
+		pMinus2: new(big.Int).Sub(p, big.NewInt(2)),
+	}
+	f.p = f.limbs(p)
+
+	// -p^-1 mod 2^64 by Newton iteration, every step doubles the correct bits
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.p[0]*inv
+	}
+	f.pInv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*f.n))
+	f.one = f.limbs(new(big.Int).Mod(r, p))
+	f.r2 = f.limbs(new(big.Int).Mod(new(big.Int).Mul(r, r), p))
+	return f, nil
+}
+
+/*
+Modulus returns p.
+*/
+func (f *Field) Modulus() *big.Int {
+	return new(big.Int).Set(f.modulus)
+}
+
+/*
+Limbs returns the number of limbs of the elements of f.
+*/
+func (f *Field) Limbs() int {
+	return f.n
+}
+
+// limbs splits 0 <= x < 2^(64*n) into limbs.
+func (f *Field) limbs(x *big.Int) (z Element) {
+	b := make([]byte, 8*f.n)
+	x.FillBytes(b)
+	for i := 0; i < f.n; i++ {
+		for _, c := range b[len(b)-8*(i+1) : len(b)-8*i] {
+			z[i] = z[i]<<8 | uint64(c)
+		}
+	}
+	return
+}
+
+/*
+SetBig sets z to x mod p.
+*/
+func (f *Field) SetBig(z *Element, x *big.Int) {
+	raw := f.limbs(new(big.Int).Mod(x, f.modulus))
+	f.Mul(z, &raw, &f.r2)
+}
+
+/*
+Big returns x as an integer in [0, p).
+*/
+func (f *Field) Big(x *Element) *big.Int {
+	var one, raw Element
+	one[0] = 1
+	f.Mul(&raw, x, &one)
+
+	b := make([]byte, 8*f.n)
+	for i := 0; i < f.n; i++ {
+		for j := 0; j < 8; j++ {
+			b[len(b)-8*i-1-j] = byte(raw[i] >> (8 * j))
+		}
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+/*
+SetOne sets z to 1.
+*/
+func (f *Field) SetOne(z *Element) {
+	*z = f.one
+}
+
+/*
+IsZero reports whether x is 0.
+*/
+func (f *Field) IsZero(x *Element) bool {
+	var acc uint64
+	for i := 0; i < f.n; i++ {
+		acc |= x[i]
+	}
+	return acc == 0
+}
+
+/*
+Equal reports whether x and y are equal.
+*/
+func (f *Field) Equal(x, y *Element) bool {
+	var acc uint64
+	for i := 0; i < f.n; i++ {
+		acc |= x[i] ^ y[i]
+	}
+	return acc == 0
+}
+
+/*
+Add sets z to x + y.
+*/
+func (f *Field) Add(z, x, y *Element) {
+	var sum, reduced Element
+	var carry, borrow uint64
+	for i := 0; i < f.n; i++ {
+		sum[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+	for i := 0; i < f.n; i++ {
+		reduced[i], borrow = bits.Sub64(sum[i], f.p[i], borrow)
+	}
+	// keep sum - p unless it borrowed without the carry of the addition
+	f.choose(z, &reduced, &sum, carry|(borrow^1))
+}
+
+/*
+Sub sets z to x - y.
+*/
+func (f *Field) Sub(z, x, y *Element) {
+	var diff Element
+	var borrow, carry uint64
+	for i := 0; i < f.n; i++ {
+		diff[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	// add p back if it borrowed
+	mask := -borrow
+	for i := 0; i < f.n; i++ {
+		diff[i], carry = bits.Add64(diff[i], f.p[i]&mask, carry)
+	}
+	*z = diff
+}
+
+/*
+Neg sets z to -x.
+*/
+func (f *Field) Neg(z, x *Element) {
+	var zero Element
+	f.Sub(z, &zero, x)
+}
+
+/*
+Double sets z to 2*x.
+*/
+func (f *Field) Double(z, x *Element) {
+	f.Add(z, x, x)
+}
+
+/*
+Mul sets z to x*y.
+*/
+func (f *Field) Mul(z, x, y *Element) {
+	switch f.n {
+	case 4:
+		f.mul4(z, x, y)
+		return
+	case 8:
+		f.mul8(z, x, y)
+		return
+	}
+
+	// Coarsely integrated operand scanning (CIOS), Koc et al. The slices let
+	// the compiler drop the bounds checks.
+	n := f.n
+	var buf [MaxLimbs + 2]uint64
+	t := buf[:n+2]
+	xs, ys, ps := x[:n], y[:n], f.p[:n]
+	for i := range ys {
+		// t += x*y_i
+		var c uint64
+		yi := ys[i]
+		for j, xj := range xs {
+			c, t[j] = madd(xj, yi, t[j], c)
+		}
+		t[n], c = bits.Add64(t[n], c, 0)
+		t[n+1] = c
+
+		// t = (t + m*p)/2^64, where m makes the lowest limb zero
+		m := t[0] * f.pInv
+		c, _ = madd(m, ps[0], t[0], 0)
+		for j := 1; j < n; j++ {
+			c, t[j-1] = madd(m, ps[j], t[j], c)
+		}
+		t[n-1], c = bits.Add64(t[n], c, 0)
+		t[n] = t[n+1] + c
+	}
+
+	// t < 2p, subtract p once if needed
+	var reduced Element
+	var borrow uint64
+	for i, pi := range ps {
+		reduced[i], borrow = bits.Sub64(t[i], pi, borrow)
+	}
+	mask := -(t[n] | (borrow ^ 1))
+	for i := range ps {
+		z[i] = reduced[i]&mask | t[i]&^mask
+	}
+}
+
+/*
+Square sets z to x^2.
+*/
+func (f *Field) Square(z, x *Element) {
+	f.Mul(z, x, x)
+}
+
+/*
+Inverse sets z to 1/x, or 0 if x is 0. It computes x^(p-2), so p must be prime.
+*/
+func (f *Field) Inverse(z, x *Element) {
+	// the exponent is public, square and multiply leaks nothing about x
+	result := f.one
+	base := *x
+	for i := f.pMinus2.BitLen() - 1; i >= 0; i-- {
+		f.Square(&result, &result)
+		if f.pMinus2.Bit(i) == 1 {
+			f.Mul(&result, &result, &base)
+		}
+	}
+	*z = result
+}
+
+/*
+Select sets z to x if cond is 1 and to y if cond is 0, in constant time.
+*/
+func (f *Field) Select(z, x, y *Element, cond uint64) {
+	f.choose(z, x, y, cond)
+}
+
+func (f *Field) choose(z, x, y *Element, cond uint64) {
+	mask := -cond
+	for i := 0; i < f.n; i++ {
+		z[i] = x[i]&mask | y[i]&^mask
+	}
+}
+
+// madd returns a*b + c + d as (hi, lo).
+func madd(a, b, c, d uint64) (hi, lo uint64) {
+	var carry uint64
+	hi, lo = bits.Mul64(a, b)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	lo, carry = bits.Add64(lo, d, 0)
+	hi += carry
+	return
+}
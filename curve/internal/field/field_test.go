@@ -0,0 +1,109 @@
+package field
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var primes = []string{
+	// CryptoPro-A, TC26 256-A and 512-A/C
+	"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97",
+	"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97",
+	"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffdc7",
+	// CryptoPro-B, where the top limb is not all ones
+	"8000000000000000000000000000000000000000000000000000000000000c99",
+	// P-256, a modulus that does not fill the limbs differently
+	"ffffffff00000001000000000000000000000000ffffffffffffffffffffffff",
+	// a small odd prime
+	"65537",
+}
+
+func assertInt(t *testing.T, expected, actual *big.Int) {
+	assert.Zero(t, expected.Cmp(actual), "expected %s, actual %s", expected, actual)
+}
+
+func TestField(t *testing.T) {
+	for _, hex := range primes {
+		p, ok := new(big.Int).SetString(hex, 16)
+		require.True(t, ok)
+		f, err := New(p)
+		require.NoError(t, err)
+		assert.Equal(t, p, f.Modulus())
+
+		values := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(p, big.NewInt(1))}
+		for i := 0; i < 20; i++ {
+			v, err := rand.Int(rand.Reader, p)
+			require.NoError(t, err)
+			values = append(values, v)
+		}
+
+		for _, a := range values {
+			var x Element
+			f.SetBig(&x, a)
+			assertInt(t, a, f.Big(&x))
+
+			var z Element
+			f.Neg(&z, &x)
+			assertInt(t, new(big.Int).Mod(new(big.Int).Neg(a), p), f.Big(&z))
+
+			f.Inverse(&z, &x)
+			if a.Sign() == 0 {
+				assert.True(t, f.IsZero(&z))
+			} else {
+				assertInt(t, new(big.Int).ModInverse(a, p), f.Big(&z))
+			}
+
+			for _, b := range values[len(values)-4:] {
+				var y Element
+				f.SetBig(&y, b)
+
+				f.Add(&z, &x, &y)
+				assertInt(t, new(big.Int).Mod(new(big.Int).Add(a, b), p), f.Big(&z))
+				f.Sub(&z, &x, &y)
+				assertInt(t, new(big.Int).Mod(new(big.Int).Sub(a, b), p), f.Big(&z))
+				f.Mul(&z, &x, &y)
+				assertInt(t, new(big.Int).Mod(new(big.Int).Mul(a, b), p), f.Big(&z))
+				assert.Equal(t, a.Cmp(b) == 0, f.Equal(&x, &y))
+			}
+
+			// the output may alias the inputs
+			z = x
+			f.Square(&z, &z)
+			assertInt(t, new(big.Int).Mod(new(big.Int).Mul(a, a), p), f.Big(&z))
+		}
+
+		var one, zero Element
+		f.SetOne(&one)
+		assertInt(t, big.NewInt(1), f.Big(&one))
+		f.Select(&zero, &one, &zero, 0)
+		assert.True(t, f.IsZero(&zero))
+		f.Select(&zero, &one, &zero, 1)
+		assert.True(t, f.Equal(&zero, &one))
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	_, err := New(big.NewInt(10))
+	assert.Error(t, err)
+	_, err = New(new(big.Int).Lsh(big.NewInt(1), 600))
+	assert.Error(t, err)
+}
+
+func BenchmarkMul(b *testing.B) {
+	for _, hex := range primes[:3] {
+		p, _ := new(big.Int).SetString(hex, 16)
+		f, _ := New(p)
+		var x Element
+		f.SetBig(&x, new(big.Int).Sub(p, big.NewInt(5)))
+		b.Run(fmt.Sprint(p.BitLen()), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				f.Mul(&x, &x, &x)
+			}
+		})
+	}
+}
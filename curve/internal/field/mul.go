@@ -0,0 +1,68 @@
+package field
+
+import "math/bits"
+
+// The Montgomery multiplication of Mul unrolled for the sizes of the GOST
+// primes.
+
+// mul4 is Mul for four limbs, with constant bounds so that the loops unroll.
+func (f *Field) mul4(z, x, y *Element) {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			c, t[j] = madd(x[j], y[i], t[j], c)
+		}
+		t[4], c = bits.Add64(t[4], c, 0)
+		t[5] = c
+
+		m := t[0] * f.pInv
+		c, _ = madd(m, f.p[0], t[0], 0)
+		for j := 1; j < 4; j++ {
+			c, t[j-1] = madd(m, f.p[j], t[j], c)
+		}
+		t[3], c = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + c
+	}
+
+	var reduced [4]uint64
+	var borrow uint64
+	for i := 0; i < 4; i++ {
+		reduced[i], borrow = bits.Sub64(t[i], f.p[i], borrow)
+	}
+	mask := -(t[4] | (borrow ^ 1))
+	for i := 0; i < 4; i++ {
+		z[i] = reduced[i]&mask | t[i]&^mask
+	}
+}
+
+// mul8 is Mul for eight limbs, with constant bounds so that the loops unroll.
+func (f *Field) mul8(z, x, y *Element) {
+	var t [10]uint64
+	for i := 0; i < 8; i++ {
+		var c uint64
+		for j := 0; j < 8; j++ {
+			c, t[j] = madd(x[j], y[i], t[j], c)
+		}
+		t[8], c = bits.Add64(t[8], c, 0)
+		t[9] = c
+
+		m := t[0] * f.pInv
+		c, _ = madd(m, f.p[0], t[0], 0)
+		for j := 1; j < 8; j++ {
+			c, t[j-1] = madd(m, f.p[j], t[j], c)
+		}
+		t[7], c = bits.Add64(t[8], c, 0)
+		t[8] = t[9] + c
+	}
+
+	var reduced [8]uint64
+	var borrow uint64
+	for i := 0; i < 8; i++ {
+		reduced[i], borrow = bits.Sub64(t[i], f.p[i], borrow)
+	}
+	mask := -(t[8] | (borrow ^ 1))
+	for i := 0; i < 8; i++ {
+		z[i] = reduced[i]&mask | t[i]&^mask
+	}
+}
@@ -0,0 +1,163 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"math/bits"
+)
+
+/*
+This file implements multi-scalar multiplication, sum(scalars[i]*points[i]). The
+doublings are shared between all the terms and the sums are kept in
+ProjectivePoint, so only the result is converted back to affine coordinates.
+Small inputs use Straus' interleaved windows, large ones Pippenger's bucket
+method.
+*/
+
+// pippengerThreshold is the number of terms from which MultiScalarMult switches
+// from Straus to Pippenger.
+const pippengerThreshold = 32
+
+// strausWindow is the window width of Straus' method.
+const strausWindow = 4
+
+/*
+MultiScalarMult returns sum(scalars[i]*points[i]). Scalars are reduced modulo the
+order of the curve and may be negative; points at infinity and zero scalars are
+skipped. The reduction is only valid for points of the subgroup of order N, a
+point with a small subgroup component gives a wrong result, so points from
+untrusted sources must be checked with Validate first. It panics if points and
+scalars are of different lengths.
+*/
+func MultiScalarMult(ec elliptic.Curve, points []*Point, scalars []*big.Int) *Point {
+	if len(points) != len(scalars) {
+		panic("curve: MultiScalarMult with different numbers of points and scalars")
+	}
+
+	n := ec.Params().N
+	curve, ok := ec.(*CurveParams)
+	if !ok {
+		sum := new(Point).SetInfinity()
+		for i := range points {
+			if points[i].IsZero() || scalars[i] == nil {
+				continue
+			}
+			sum.Add(ec, sum, new(Point).ScalarMult(ec, points[i], new(big.Int).Mod(scalars[i], n)))
+		}
+		return sum
+	}
+
+	ar := curve.arithmetic()
+	terms := make([]*ProjectivePoint, 0, len(points))
+	reduced := make([]*big.Int, 0, len(points))
+	maxBits := 0
+	for i := range points {
+		if points[i].IsZero() || scalars[i] == nil {
+			continue
+		}
+		k := new(big.Int).Mod(scalars[i], n)
+		if k.Sign() == 0 {
+			continue
+		}
+		term := newIdentity(curve, ar)
+		term.setAffine(points[i].X, points[i].Y)
+		terms = append(terms, term)
+		reduced = append(reduced, k)
+		if k.BitLen() > maxBits {
+			maxBits = k.BitLen()
+		}
+	}
+
+	var result *ProjectivePoint
+	switch {
+	case len(terms) == 0:
+		return new(Point).SetInfinity()
+	case len(terms) < pippengerThreshold:
+		result = straus(curve, terms, reduced, maxBits)
+	default:
+		result = pippenger(curve, terms, reduced, maxBits)
+	}
+	return result.Point()
+}
+
+// straus computes the sum with 4-bit windows over per-point tables of j*P.
+func straus(curve *CurveParams, points []*ProjectivePoint, scalars []*big.Int, maxBits int) *ProjectivePoint {
+	tables := make([][]*ProjectivePoint, len(points))
+	for i, p := range points {
+		table := make([]*ProjectivePoint, 1<<strausWindow)
+		table[1] = p
+		for j := 2; j < len(table); j++ {
+			table[j] = new(ProjectivePoint).Add(table[j-1], p)
+		}
+		tables[i] = table
+	}
+
+	result := newIdentity(curve, curve.arithmetic())
+	for w := (maxBits+strausWindow-1)/strausWindow - 1; w >= 0; w-- {
+		for j := 0; j < strausWindow; j++ {
+			result.Double(result)
+		}
+		for i := range points {
+			if digit := window(scalars[i], w*strausWindow, strausWindow); digit != 0 {
+				result.Add(result, tables[i][digit])
+			}
+		}
+	}
+	return result
+}
+
+// pippenger computes the sum by sorting the points of every window into buckets
+// by their digit.
+func pippenger(curve *CurveParams, points []*ProjectivePoint, scalars []*big.Int, maxBits int) *ProjectivePoint {
+	c := bits.Len(uint(len(points))) - 2
+	if c < strausWindow {
+		c = strausWindow
+	}
+	if c > 16 {
+		c = 16
+	}
+
+	ar := curve.arithmetic()
+	buckets := make([]*ProjectivePoint, 1<<c)
+	result := newIdentity(curve, ar)
+	for w := (maxBits+c-1)/c - 1; w >= 0; w-- {
+		for j := 0; j < c; j++ {
+			result.Double(result)
+		}
+
+		for j := range buckets {
+			buckets[j] = nil
+		}
+		for i := range points {
+			digit := window(scalars[i], w*c, c)
+			if digit == 0 {
+				continue
+			}
+			if buckets[digit] == nil {
+				buckets[digit] = points[i]
+			} else {
+				buckets[digit] = new(ProjectivePoint).Add(buckets[digit], points[i])
+			}
+		}
+
+		// sum(j*B_j) = B_top + (B_top + B_top-1) + ... with running sums
+		running, sum := newIdentity(curve, ar), newIdentity(curve, ar)
+		for j := len(buckets) - 1; j > 0; j-- {
+			if buckets[j] != nil {
+				running.Add(running, buckets[j])
+			}
+			sum.Add(sum, running)
+		}
+		result.Add(result, sum)
+	}
+	return result
+}
+
+// window returns the width bits of k starting at bit offset.
+func window(k *big.Int, offset, width int) int {
+	digit := 0
+	for b := width - 1; b >= 0; b-- {
+		digit = digit<<1 | int(k.Bit(offset+b))
+	}
+	return digit
+}
@@ -0,0 +1,110 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveMultiScalarMult is the reference: one scalar multiplication per term.
+func naiveMultiScalarMult(ec elliptic.Curve, points []*Point, scalars []*big.Int) *Point {
+	result := new(Point).SetInfinity()
+	for i := range points {
+		k := new(big.Int).Mod(scalars[i], ec.Params().N)
+		result = new(Point).Add(ec, result, new(Point).ScalarMult(ec, points[i], k))
+	}
+	return result
+}
+
+func randomTerms(ec elliptic.Curve, n int) ([]*Point, []*big.Int) {
+	mode := ec.Params().BitSize / 8
+	points := make([]*Point, n)
+	scalars := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		k := new(big.Int).SetBytes(utils.RandomBytes(mode))
+		points[i] = new(Point).ScalarBaseMult(ec, k)
+		scalars[i] = new(big.Int).SetBytes(utils.RandomBytes(mode))
+	}
+	return points, scalars
+}
+
+func assertSamePoint(t *testing.T, expected, actual *Point, msgAndArgs ...interface{}) {
+	if expected.IsZero() {
+		assert.True(t, actual.IsZero(), msgAndArgs...)
+		return
+	}
+	// compare the values, a zero big.Int may have either representation
+	assert.Equal(t, expected.X.String(), actual.X.String(), msgAndArgs...)
+	assert.Equal(t, expected.Y.String(), actual.Y.String(), msgAndArgs...)
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256()}
+	for _, c := range Curves() {
+		curves = append(curves, c)
+	}
+	for _, ec := range curves {
+		t.Run(ec.Params().Name, func(t *testing.T) {
+			// both sides of the Straus/Pippenger threshold
+			for _, n := range []int{0, 1, 5, pippengerThreshold} {
+				points, scalars := randomTerms(ec, n)
+				expected := naiveMultiScalarMult(ec, points, scalars)
+				assertSamePoint(t, expected, MultiScalarMult(ec, points, scalars), n)
+			}
+		})
+	}
+}
+
+func TestMultiScalarMultPippenger(t *testing.T) {
+	// wider buckets from 64 terms on
+	for _, ec := range []elliptic.Curve{GOST34102001, GOST34102012256A} {
+		points, scalars := randomTerms(ec, 130)
+		expected := naiveMultiScalarMult(ec, points, scalars)
+		assertSamePoint(t, expected, MultiScalarMult(ec, points, scalars), ec.Params().Name)
+	}
+}
+
+func TestMultiScalarMultSpecialTerms(t *testing.T) {
+	for _, ec := range []elliptic.Curve{GOST34102001, GOST34102012256A} {
+		for _, n := range []int{8, 40} {
+			points, scalars := randomTerms(ec, n)
+			order := ec.Params().N
+
+			// zero, negative and unreduced scalars and the point at infinity
+			scalars[0] = new(big.Int)
+			scalars[1] = new(big.Int).Neg(scalars[1])
+			scalars[2] = new(big.Int).Add(scalars[2], order)
+			points[3] = new(Point).SetInfinity()
+			points[4] = points[5]
+			expected := naiveMultiScalarMult(ec, points, scalars)
+			assertSamePoint(t, expected, MultiScalarMult(ec, points, scalars))
+
+			// terms that cancel out give the point at infinity
+			points[6] = points[7]
+			scalars[6] = new(big.Int).Sub(order, scalars[7])
+			points, scalars = points[6:8], scalars[6:8]
+			assert.True(t, MultiScalarMult(ec, points, scalars).IsZero())
+		}
+	}
+
+	assert.Panics(t, func() {
+		MultiScalarMult(GOST34102001, []*Point{new(Point).SetInfinity()}, nil)
+	})
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	points, scalars := randomTerms(GOST34102001, 64)
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveMultiScalarMult(GOST34102001, points, scalars)
+		}
+	})
+	b.Run("msm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			MultiScalarMult(GOST34102001, points, scalars)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package curve
+
+import (
+	"encoding/asn1"
+
+	"github.com/pkg/errors"
+)
+
+// Parameter sets of GOST R 34.10-2001 (RFC 4357).
+var (
+	GOST34102001Test          *CurveParams
+	GOST34102001CryptoProA    *CurveParams
+	GOST34102001CryptoProB    *CurveParams
+	GOST34102001CryptoProC    *CurveParams
+	GOST34102001CryptoProXchA *CurveParams
+	GOST34102001CryptoProXchB *CurveParams
+)
+
+// Parameter sets of GOST R 34.10-2012 (R 1323565.1.024-2019, RFC 7836).
+var (
+	GOST34102012256A    *CurveParams
+	GOST34102012256B    *CurveParams
+	GOST34102012256C    *CurveParams
+	GOST34102012256D    *CurveParams
+	GOST34102012512Test *CurveParams
+	GOST34102012512A    *CurveParams
+	GOST34102012512B    *CurveParams
+	GOST34102012512C    *CurveParams
+)
+
+var curves []*CurveParams
+
+func initCurves() {
+	const (
+		cryptoProAP  = "fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97"
+		cryptoProAN  = "ffffffffffffffffffffffffffffffff6c611070995ad10045841b09b761b893"
+		cryptoProAA  = "fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd94"
+		cryptoProAB  = "00000000000000000000000000000000000000000000000000000000000000a6"
+		cryptoProAGx = "0000000000000000000000000000000000000000000000000000000000000001"
+		cryptoProAGy = "8d91e471e0989cda27df505a453f2b7635294f2ddf23e3b122acc99c9e9f1e14"
+
+		cryptoProBP  = "8000000000000000000000000000000000000000000000000000000000000c99"
+		cryptoProBN  = "800000000000000000000000000000015f700cfff1a624e5e497161bcc8a198f"
+		cryptoProBA  = "8000000000000000000000000000000000000000000000000000000000000c96"
+		cryptoProBB  = "3e1af419a269a5f866a7d3c25c3df80ae979259373ff2b182f49d4ce7e1bbc8b"
+		cryptoProBGx = "0000000000000000000000000000000000000000000000000000000000000001"
+		cryptoProBGy = "3fa8124359f96680b83d1c3eb2c070e5c545c9858d03ecfb744bf8d717717efc"
+
+		cryptoProCP  = "9b9f605f5a858107ab1ec85e6b41c8aacf846e86789051d37998f7b9022d759b"
+		cryptoProCN  = "9b9f605f5a858107ab1ec85e6b41c8aa582ca3511eddfb74f02f3a6598980bb9"
+		cryptoProCA  = "9b9f605f5a858107ab1ec85e6b41c8aacf846e86789051d37998f7b9022d7598"
+		cryptoProCB  = "000000000000000000000000000000000000000000000000000000000000805a"
+		cryptoProCGx = "0000000000000000000000000000000000000000000000000000000000000000"
+		cryptoProCGy = "41ece55743711a8c3cbf3783cd08c0ee4d4dc440d4641a8f366e550dfdb3bb67"
+	)
+
+	GOST34102001Test = newCurve(
+		"id-GostR3410-2001-TestParamSet",
+		asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 0},
+		256,
+		"8000000000000000000000000000000000000000000000000000000000000431",
+		"8000000000000000000000000000000150fe8a1892976154c59cfc193accf5b3",
+		"0000000000000000000000000000000000000000000000000000000000000007",
+		"5fbff498aa938ce739b8e022fbafef40563f6e6a3472fc2a514c0ce9dae23b7e",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"08e2a8a0e65147d4bd6316030e16d19c85c97f0a9ca267122b96abbcea7e8fc8",
+		1,
+	)
+	GOST34102001CryptoProA = newCurve(
+		"id-GostR3410-2001-CryptoPro-A-ParamSet",
+		asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 1},
+		256, cryptoProAP, cryptoProAN, cryptoProAA, cryptoProAB, cryptoProAGx, cryptoProAGy, 1,
+	)
+	GOST34102001CryptoProB = newCurve(
+		"id-GostR3410-2001-CryptoPro-B-ParamSet",
+		asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 2},
+		256, cryptoProBP, cryptoProBN, cryptoProBA, cryptoProBB, cryptoProBGx, cryptoProBGy, 1,
+	)
+	GOST34102001CryptoProC = newCurve(
+		"id-GostR3410-2001-CryptoPro-C-ParamSet",
+		asn1.ObjectIdentifier{1, 2, 643, 2, 2, 35, 3},
+		256, cryptoProCP, cryptoProCN, cryptoProCA, cryptoProCB, cryptoProCGx, cryptoProCGy, 1,
+	)
+	GOST34102001CryptoProXchA = newCurve(
+		"id-GostR3410-2001-CryptoPro-XchA-ParamSet",
+		asn1.ObjectIdentifier{1, 2, 643, 2, 2, 36, 0},
+		256, cryptoProAP, cryptoProAN, cryptoProAA, cryptoProAB, cryptoProAGx, cryptoProAGy, 1,
+	)
+	GOST34102001CryptoProXchB = newCurve(
+		"id-GostR3410-2001-CryptoPro-XchB-ParamSet",
+		asn1.ObjectIdentifier{1, 2, 643, 2, 2, 36, 1},
+		256, cryptoProCP, cryptoProCN, cryptoProCA, cryptoProCB, cryptoProCGx, cryptoProCGy, 1,
+	)
+
+	GOST34102012256A = newCurve(
+		"id-tc26-gost-3410-2012-256-paramSetA",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 1},
+		256,
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd97",
+		"400000000000000000000000000000000fd8cddfc87b6635c115af556c360c67",
+		"c2173f1513981673af4892c23035a27ce25e2013bf95aa33b22c656f277e7335",
+		"295f9bae7428ed9ccc20e7c359a9d41a22fccd9108e17bf7ba9337a6f8ae9513",
+		"91e38443a5e82c0d880923425712b2bb658b9196932e02c78b2582fe742daa28",
+		"32879423ab1a0375895786c4bb46e9565fde0b5344766740af268adb32322e5c",
+		4,
+	).withEdwards(
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0605f6b7c183fa81578bc39cfad518132b9df62897009af7e522c32d6dc7bffb",
+		"000000000000000000000000000000000000000000000000000000000000000d",
+		"60ca1e32aa475b348488c38fab07649ce7ef8dbe87f22e81f92b2592dba300e7",
+	)
+	GOST34102012256B = newCurve(
+		"id-tc26-gost-3410-2012-256-paramSetB",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 2},
+		256, cryptoProAP, cryptoProAN, cryptoProAA, cryptoProAB, cryptoProAGx, cryptoProAGy, 1,
+	)
+	GOST34102012256C = newCurve(
+		"id-tc26-gost-3410-2012-256-paramSetC",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 3},
+		256, cryptoProBP, cryptoProBN, cryptoProBA, cryptoProBB, cryptoProBGx, cryptoProBGy, 1,
+	)
+	GOST34102012256D = newCurve(
+		"id-tc26-gost-3410-2012-256-paramSetD",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 1, 4},
+		256, cryptoProCP, cryptoProCN, cryptoProCA, cryptoProCB, cryptoProCGx, cryptoProCGy, 1,
+	)
+
+	GOST34102012512Test = newCurve(
+		"id-tc26-gost-3410-2012-512-paramSetTest",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 2, 0},
+		512,
+		"4531acd1fe0023c7550d267b6b2fee80922b14b2ffb90f04d4eb7c09b5d2d15df1d852741af4704a0458047e80e4546d35b8336fac224dd81664bbf528be6373",
+		"4531acd1fe0023c7550d267b6b2fee80922b14b2ffb90f04d4eb7c09b5d2d15da82f2d7ecb1dbac719905c5eecc423f1d86e25edbe23c595d644aaf187e6e6df",
+		"0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000007",
+		"1cff0806a31116da29d8cfa54e57eb748bc5f377e49400fdd788b649eca1ac4361834013b2ad7322480a89ca58e0cf74bc9e540c2add6897fad0a3084f302adc",
+		"24d19cc64572ee30f396bf6ebbfd7a6c5213b3b3d7057cc825f91093a68cd762fd60611262cd838dc6b60aa7eee804e28bc849977fac33b4b530f1b120248a9a",
+		"2bb312a43bd2ce6e0d020613c857acddcfbf061e91e5f2c3f32447c259f39b2c83ab156d77f1496bf7eb3351e1ee4e43dc1a18b91b24640b6dbb92cb1add371e",
+		1,
+	)
+	GOST34102012512A = newCurve(
+		"id-tc26-gost-3410-2012-512-paramSetA",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 2, 1},
+		512,
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffdc7",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff27e69532f48d89116ff22b8d4e0560609b4b38abfad2b85dcacdb1411f10b275",
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffdc4",
+		"e8c2505dedfc86ddc1bd0b2b6667f1da34b82574761cb0e879bd081cfd0b6265ee3cb090f30d27614cb4574010da90dd862ef9d4ebee4761503190785a71c760",
+		"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003",
+		"7503cfe87a836ae3a61b8816e25450e6ce5e1c93acf1abc1778064fdcbefa921df1626be4fd036e93d75e6a50e3a41e98028fe5fc235f5b889a589cb5215f2a4",
+		1,
+	)
+	GOST34102012512B = newCurve(
+		"id-tc26-gost-3410-2012-512-paramSetB",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 2, 2},
+		512,
+		"8000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000006f",
+		"800000000000000000000000000000000000000000000000000000000000000149a1ec142565a545acfdb77bd9d40cfa8b996712101bea0ec6346c54374f25bd",
+		"8000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000006c",
+		"687d1b459dc841457e3e06cf6f5e2517b97c7d614af138bcbf85dc806c4b289f3e965d2db1416d217f8b276fad1ab69c50f78bee1fa3106efb8ccbc7c5140116",
+		"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002",
+		"1a8f7eda389b094c2c071e3647a8940f3c123b697578c213be6dd9e6c8ec7335dcb228fd1edf4a39152cbcaaf8c0398828041055f94ceeec7e21340780fe41bd",
+		1,
+	)
+	GOST34102012512C = newCurve(
+		"id-tc26-gost-3410-2012-512-paramSetC",
+		asn1.ObjectIdentifier{1, 2, 643, 7, 1, 2, 1, 2, 3},
+		512,
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffdc7",
+		"3fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffc98cdba46506ab004c33a9ff5147502cc8eda9e7a769a12694623cef47f023ed",
+		"dc9203e514a721875485a529d2c722fb187bc8980eb866644de41c68e143064546e861c0e2c9edd92ade71f46fcf50ff2ad97f951fda9f2a2eb6546f39689bd3",
+		"b4c4ee28cebc6c2c8ac12952cf37f16ac7efb6a9f69f4b57ffda2e4f0de5ade038cbc2fff719d2c18de0284b8bfef3b52b8cc7a5f5bf0a3c8d2319a5312557e1",
+		"e2e31edfc23de7bdebe241ce593ef5de2295b7a9cbaef021d385f7074cea043aa27272a7ae602bf2a7b9033db9ed3610c6fb85487eae97aac5bc7928c1950148",
+		"f5ce40d95b5eb899abbccff5911cb8577939804d6527378b8c108c3d2090ff9be18e2d33e3021ed2ef32d85822423b6304f726aa854bae07d0396e9a9addc40f",
+		4,
+	).withEdwards(
+		"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001",
+		"9e4f5d8c017d8d9f13a5cf3cdf5bfe4dab402d54198e31ebde28a0621050439ca6b39e0a515c06b304e2ce43e79e369e91a0cfc2bc2a22b4ca302dbb33ee7550",
+		"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000012",
+		"469af79d1fb1f5e16b99592b77a01e2a0fdfb0d01794368d9a56117f7b38669522dd4b650cf789eebf068c5d139732f0905622c04b2baae7600303ee73001a3d",
+	)
+
+	curves = []*CurveParams{
+		GOST34102001Test,
+		GOST34102001CryptoProA,
+		GOST34102001CryptoProB,
+		GOST34102001CryptoProC,
+		GOST34102001CryptoProXchA,
+		GOST34102001CryptoProXchB,
+		GOST34102012256A,
+		GOST34102012256B,
+		GOST34102012256C,
+		GOST34102012256D,
+		GOST34102012512Test,
+		GOST34102012512A,
+		GOST34102012512B,
+		GOST34102012512C,
+	}
+}
+
+/*
+Curves returns every registered parameter set.
+*/
+func Curves() []*CurveParams {
+	result := make([]*CurveParams, len(curves))
+	copy(result, curves)
+	return result
+}
+
+/*
+ByOID returns the parameter set identified by oid.
+*/
+func ByOID(oid asn1.ObjectIdentifier) (c *CurveParams, err error) {
+	for _, c = range curves {
+		if c.OID.Equal(oid) {
+			return
+		}
+	}
+	return nil, errors.Errorf("unknown curve OID %s", oid)
+}
+
+/*
+ByName returns the parameter set with the given name, e.g.
+"id-tc26-gost-3410-2012-512-paramSetA".
+*/
+func ByName(name string) (c *CurveParams, err error) {
+	for _, c = range curves {
+		if c.Name == name {
+			return
+		}
+	}
+	return nil, errors.Errorf("unknown curve name %q", name)
+}
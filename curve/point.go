@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/elliptic"
 	"encoding/hex"
+	"encoding/json"
 	"math/big"
 	"strconv"
 
@@ -14,34 +15,110 @@ import (
 	"github.com/pkg/errors"
 )
 
+/*
+Point is a point of an elliptic curve in affine coordinates. The point at
+infinity has no affine coordinates and is marked by its own flag, with X and Y
+nil. The zero Point and a nil *Point are the point at infinity as well, so that
+it survives encodings such as JSON.
+
+The methods computing a point set the receiver to the result and return it. The
+receiver may be one of the operands, and the result never shares its
+coordinates with them: changing the coordinates of one point never changes
+another.
+*/
 type Point struct {
 	X *big.Int
 	Y *big.Int
+
+	infinity bool
 }
 
+/*
+SetInfinity sets p to the point at infinity.
+*/
 func (p *Point) SetInfinity() *Point {
 	p.X = nil
 	p.Y = nil
+	p.infinity = true
 	return p
 }
 
+/*
+IsZero reports whether p is the point at infinity.
+*/
 func (p *Point) IsZero() bool {
-	c1 := p.X == nil || p.Y == nil
-	if !c1 {
-		z := new(big.Int).SetInt64(0)
-		return p.X.Cmp(z) == 0 && p.Y.Cmp(z) == 0
+	return p == nil || p.infinity || p.X == nil || p.Y == nil
+}
+
+// setAffine sets p to (x, y), which it takes over, or to the point at infinity
+// for the (0, 0) returned by the elliptic.Curve methods.
+func (p *Point) setAffine(x, y *big.Int) *Point {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return p.SetInfinity()
 	}
-	return true
+	p.X, p.Y, p.infinity = x, y, false
+	return p
 }
 
+/*
+UnmarshalJSON decodes the X and Y of p, with null coordinates or (0, 0) as the
+point at infinity.
+*/
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var coordinates struct {
+		X, Y *big.Int
+	}
+	if err := json.Unmarshal(data, &coordinates); err != nil {
+		return err
+	}
+	if coordinates.X == nil || coordinates.Y == nil {
+		p.SetInfinity()
+		return nil
+	}
+	p.setAffine(coordinates.X, coordinates.Y)
+	return nil
+}
+
+/*
+Set sets p to a copy of a.
+*/
+func (p *Point) Set(a *Point) *Point {
+	if a.IsZero() {
+		return p.SetInfinity()
+	}
+	return p.setAffine(new(big.Int).Set(a.X), new(big.Int).Set(a.Y))
+}
+
+/*
+Clone returns a copy of p.
+*/
+func (p *Point) Clone() *Point {
+	return new(Point).Set(p)
+}
+
+/*
+Equal reports whether p and a are the same point.
+*/
+func (p *Point) Equal(a *Point) bool {
+	if p.IsZero() || a.IsZero() {
+		return p.IsZero() && a.IsZero()
+	}
+	return p.X.Cmp(a.X) == 0 && p.Y.Cmp(a.Y) == 0
+}
+
+/*
+Neg sets p to -a, which is (X, P - Y).
+*/
 func (p *Point) Neg(ec elliptic.Curve, a *Point) *Point {
-	// (X, Y) -> (X, X + Y)
 	if a.IsZero() {
 		return p.SetInfinity()
 	}
-	one := new(big.Int).SetInt64(1)
-	mone := new(big.Int).Sub(ec.Params().N, one)
-	p.ScalarMult(ec, p, mone)
+	P := ec.Params().P
+	y := new(big.Int).Mod(a.Y, P)
+	if y.Sign() != 0 {
+		y.Sub(P, y)
+	}
+	p.X, p.Y, p.infinity = new(big.Int).Set(a.X), y, false
 	return p
 }
 
@@ -55,10 +132,7 @@ func (p *Point) ScalarBaseMult(ec elliptic.Curve, n *big.Int) *Point {
 	}
 	n = bn.Mod(n, ec.Params().N)
 	bns := n.Bytes()
-	resx, resy := ec.ScalarBaseMult(bns)
-	p.X = resx
-	p.Y = resy
-	return p
+	return p.setAffine(ec.ScalarBaseMult(bns))
 }
 
 func (p *Point) ScalarMult(ec elliptic.Curve, a *Point, n *big.Int) *Point {
@@ -71,32 +145,36 @@ func (p *Point) ScalarMult(ec elliptic.Curve, a *Point, n *big.Int) *Point {
 	}
 	n = bn.Mod(n, ec.Params().N)
 	bns := n.Bytes()
-	resx, resy := ec.ScalarMult(a.X, a.Y, bns)
-	p.X = resx
-	p.Y = resy
-	return p
+	return p.setAffine(ec.ScalarMult(a.X, a.Y, bns))
 }
 
 func (p *Point) Add(ec elliptic.Curve, a, b *Point) *Point {
 	if a.IsZero() {
-		p.X = b.X
-		p.Y = b.Y
-		return p
+		return p.Set(b)
 	} else if b.IsZero() {
-		p.X = a.X
-		p.Y = a.Y
-		return p
-	}
-	if a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0 {
-		resx, resy := ec.Double(a.X, a.Y)
-		p.X = resx
-		p.Y = resy
-		return p
-	}
-	resx, resy := ec.Add(a.X, a.Y, b.X, b.Y)
-	p.X = resx
-	p.Y = resy
-	return p
+		return p.Set(a)
+	}
+	if a.Equal(b) {
+		return p.Double(ec, a)
+	}
+	return p.setAffine(ec.Add(a.X, a.Y, b.X, b.Y))
+}
+
+/*
+Sub sets p to a - b.
+*/
+func (p *Point) Sub(ec elliptic.Curve, a, b *Point) *Point {
+	return p.Add(ec, a, new(Point).Neg(ec, b))
+}
+
+/*
+Double sets p to 2a.
+*/
+func (p *Point) Double(ec elliptic.Curve, a *Point) *Point {
+	if a.IsZero() {
+		return p.SetInfinity()
+	}
+	return p.setAffine(ec.Double(a.X, a.Y))
 }
 
 /*
@@ -108,6 +186,8 @@ This implementation is based on the paper:
 Short signatures from the Weil pairing
 Boneh, Lynn and Shacham
 Journal of Cryptology, September 2004, Volume 17, Issue 4, pp 297–319
+
+Deprecated: MapToGroup is not a standard and can fail, use HashToCurve.
 */
 func MapToGroup(ec elliptic.Curve, ha gost3410.HashAlgorithm, m string) (*Point, error) {
 	var (
@@ -126,8 +206,14 @@ func MapToGroup(ec elliptic.Curve, ha gost3410.HashAlgorithm, m string) (*Point,
 		y := fx.ModSqrt(fx, ec.Params().P)
 		if y != nil {
 			p := &Point{X: x, Y: y}
-			if p.IsOnCurve(ec) && !p.IsZero() {
-				return p, nil
+			if p.IsOnCurve(ec) {
+				// move the point into the subgroup of order N
+				if h := cofactor(ec); h.Cmp(big.NewInt(1)) != 0 {
+					p.ScalarMult(ec, p, h)
+				}
+				if !p.IsZero() {
+					return p, nil
+				}
 			}
 		}
 		i = i + 1
@@ -136,10 +222,10 @@ func MapToGroup(ec elliptic.Curve, ha gost3410.HashAlgorithm, m string) (*Point,
 }
 
 /*
-F receives a big integer x as input and return x^3 + 7 mod ORDER.
+F receives a big integer x as input and return x^3 + a*x + b mod P.
 */
 func F(ec elliptic.Curve, x *big.Int) (*big.Int, error) {
-	a := new(big.Int).Sub(ec.Params().P, big.NewInt(3))
+	a := coefficientA(ec)
 	b := ec.Params().B
 	p := ec.Params().P
 
@@ -150,8 +236,14 @@ func F(ec elliptic.Curve, x *big.Int) (*big.Int, error) {
 	return y2, nil
 }
 
+/*
+Bytes returns X||Y, with zeros for the point at infinity.
+*/
 func (p *Point) Bytes(curve elliptic.Curve) []byte {
 	mode := curve.Params().BitSize / 8
+	if p.IsZero() {
+		return make([]byte, 2*mode)
+	}
 	raw := append(
 		utils.Pad(p.X.Bytes(), mode),
 		utils.Pad(p.Y.Bytes(), mode)...,
@@ -159,14 +251,135 @@ func (p *Point) Bytes(curve elliptic.Curve) []byte {
 	return raw
 }
 
+/*
+BytesCompressed returns the SEC1 compressed encoding of p: 0x02 or 0x03 for an
+even or odd Y, followed by X. The point at infinity is the single byte 0x00.
+*/
+func (p *Point) BytesCompressed(curve elliptic.Curve) []byte {
+	mode := curve.Params().BitSize / 8
+	if p.IsZero() {
+		return []byte{0x00}
+	}
+	prefix := byte(0x02) | byte(p.Y.Bit(0))
+	return append([]byte{prefix}, utils.Pad(p.X.Bytes(), mode)...)
+}
+
+/*
+PointError is the error type of the point decoding functions.
+*/
+type PointError string
+
+func (e PointError) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrInvalidLength is returned for an encoding of the wrong length.
+	ErrInvalidLength = PointError("invalid point length")
+	// ErrInvalidPrefix is returned for an unknown prefix byte or a compressed
+	// encoding of an odd Y = 0.
+	ErrInvalidPrefix = PointError("invalid point prefix")
+	// ErrInvalidCoordinate is returned for a coordinate that is not less than P.
+	ErrInvalidCoordinate = PointError("point coordinate out of range")
+	// ErrNotOnCurve is returned for a point that does not satisfy the curve
+	// equation, or an X without a square root of X^3 + a*X + b.
+	ErrNotOnCurve = PointError("point is not on the curve")
+	// ErrIdentity is returned for the point at infinity.
+	ErrIdentity = PointError("point is the point at infinity")
+	// ErrNotInSubgroup is returned for a point outside the subgroup of order N.
+	ErrNotInSubgroup = PointError("point is not in the prime-order subgroup")
+)
+
+/*
+PointFromBytes decodes a point given as X||Y, as written by Bytes, as SEC1
+uncompressed 0x04||X||Y or as SEC1 compressed 0x02||X or 0x03||X. It only
+accepts points of the subgroup of order N other than the point at infinity and
+fails with a PointError otherwise. The encodings of the point at infinity by
+Bytes and BytesCompressed fail with ErrIdentity.
+*/
 func PointFromBytes(curve elliptic.Curve, b []byte) (p *Point, err error) {
 	mode := curve.Params().BitSize / 8
-	if len(b) != mode*2 {
-		err = errors.New("invalid length")
-		return
+	switch {
+	case len(b) == 1 && b[0] == 0x00:
+		return nil, ErrIdentity
+	case len(b) == 2*mode:
+		p = new(Point).setAffine(new(big.Int).SetBytes(b[:mode]), new(big.Int).SetBytes(b[mode:]))
+	case len(b) == 2*mode+1 && b[0] == 0x04:
+		p = new(Point).setAffine(new(big.Int).SetBytes(b[1:mode+1]), new(big.Int).SetBytes(b[mode+1:]))
+	case len(b) == mode+1 && (b[0] == 0x02 || b[0] == 0x03):
+		if p, err = decompress(curve, new(big.Int).SetBytes(b[1:]), uint(b[0]&1)); err != nil {
+			return nil, err
+		}
+	case len(b) == 2*mode+1 || len(b) == mode+1:
+		return nil, ErrInvalidPrefix
+	default:
+		return nil, ErrInvalidLength
+	}
+
+	if err = p.Validate(curve); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// decompress returns the point with the given X and parity of Y.
+func decompress(curve elliptic.Curve, x *big.Int, odd uint) (p *Point, err error) {
+	P := curve.Params().P
+	if x.Cmp(P) >= 0 {
+		return nil, ErrInvalidCoordinate
+	}
+	y2, _ := F(curve, x)
+	y := new(big.Int).ModSqrt(y2, P)
+	if y == nil {
+		return nil, ErrNotOnCurve
+	}
+	if y.Bit(0) != odd {
+		if y.Sign() == 0 {
+			return nil, ErrInvalidPrefix
+		}
+		y.Sub(P, y)
+	}
+	return &Point{X: x, Y: y}, nil
+}
+
+/*
+Validate checks that p is a point of the subgroup of order N other than the
+point at infinity, and returns the PointError of the first check that fails.
+*/
+func (p *Point) Validate(curve elliptic.Curve) error {
+	if p.IsZero() {
+		return ErrIdentity
+	}
+	P := curve.Params().P
+	if p.X.Sign() < 0 || p.X.Cmp(P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(P) >= 0 {
+		return ErrInvalidCoordinate
+	}
+	if !curve.IsOnCurve(p.X, p.Y) {
+		return ErrNotOnCurve
+	}
+	if !p.IsInSubgroup(curve) {
+		return ErrNotInSubgroup
+	}
+	return nil
+}
+
+/*
+IsInSubgroup reports whether p is in the subgroup of order N, that is whether
+N*p is the point at infinity. It does not check that p is on the curve. Curves
+with a cofactor of 1, which includes those not described by CurveParams, need no
+multiplication.
+*/
+func (p *Point) IsInSubgroup(ec elliptic.Curve) bool {
+	if p.IsZero() {
+		return true
+	}
+	curve, ok := ec.(*CurveParams)
+	if !ok || cofactor(ec).Cmp(big.NewInt(1)) == 0 {
+		return true
 	}
-	x, y := new(big.Int).SetBytes(b[:mode]), new(big.Int).SetBytes(b[mode:])
-	return &Point{x, y}, nil
+	// ScalarMult of Point would reduce N to zero
+	a := NewProjectivePoint(curve, p)
+	return new(ProjectivePoint).ScalarMult(a, curve.N).IsIdentity()
 }
 
 func (p *Point) Hex(curve elliptic.Curve) string {
@@ -185,8 +398,12 @@ func PointFromHex(curve elliptic.Curve, s string) (p *Point, err error) {
 
 /*
 IsOnCurve returns TRUE if and only if p has coordinates X and Y that satisfy the
-Elliptic Curve equation: y^2 = x^3 + 7.
+Elliptic Curve equation: y^2 = x^3 + a*x + b. The point at infinity has no
+coordinates and is reported as not on the curve, as in crypto/elliptic.
 */
 func (p *Point) IsOnCurve(ec elliptic.Curve) bool {
+	if p.IsZero() {
+		return false
+	}
 	return ec.IsOnCurve(p.X, p.Y)
 }
@@ -0,0 +1,185 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointEncoding(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256()}
+	for _, c := range Curves() {
+		curves = append(curves, c)
+	}
+	for _, ec := range curves {
+		t.Run(ec.Params().Name, func(t *testing.T) {
+			mode := ec.Params().BitSize / 8
+			for i := 0; i < 8; i++ {
+				k := new(big.Int).SetBytes(utils.RandomBytes(mode))
+				p := new(Point).ScalarBaseMult(ec, k)
+
+				compressed := p.BytesCompressed(ec)
+				assert.Len(t, compressed, mode+1)
+				assert.Equal(t, byte(0x02+p.Y.Bit(0)), compressed[0])
+
+				uncompressed := append([]byte{0x04}, p.Bytes(ec)...)
+				for _, b := range [][]byte{p.Bytes(ec), uncompressed, compressed} {
+					decoded, err := PointFromBytes(ec, b)
+					require.NoError(t, err)
+					assertSamePoint(t, p, decoded)
+				}
+
+				decoded, err := PointFromHex(ec, hex.EncodeToString(compressed))
+				require.NoError(t, err)
+				assertSamePoint(t, p, decoded)
+			}
+		})
+	}
+}
+
+func TestPointFromBytesErrors(t *testing.T) {
+	ec := GOST34102012256A
+	mode := ec.BitSize / 8
+	g := &Point{X: ec.Gx, Y: ec.Gy}
+	pad := func(x *big.Int) []byte {
+		return utils.Pad(x.Bytes(), mode)
+	}
+
+	// the point of order 2 and its sum with G are on the curve, but not in the
+	// subgroup of order N
+	x2, y2 := ec.FromEdwards(new(big.Int), new(big.Int).Sub(ec.P, big.NewInt(1)))
+	order2 := &Point{X: x2, Y: y2}
+	mixed := new(Point).Add(ec, g, order2)
+	assert.True(t, mixed.IsOnCurve(ec))
+
+	// the smallest x for which x^3 + a*x + b is not a square
+	var offX *big.Int
+	for x := int64(1); offX == nil; x++ {
+		rhs, _ := F(ec, big.NewInt(x))
+		if new(big.Int).ModSqrt(rhs, ec.P) == nil {
+			offX = big.NewInt(x)
+		}
+	}
+
+	cases := []struct {
+		name string
+		b    []byte
+		err  error
+	}{
+		{"empty", nil, ErrInvalidLength},
+		{"short", g.Bytes(ec)[1:], ErrInvalidLength},
+		{"prefix", append([]byte{0x05}, g.Bytes(ec)...), ErrInvalidPrefix},
+		{"compressed prefix", append([]byte{0x04}, pad(ec.Gx)...), ErrInvalidPrefix},
+		{"identity", make([]byte, 2*mode), ErrIdentity},
+		{"compressed identity", new(Point).SetInfinity().BytesCompressed(ec), ErrIdentity},
+		{"x out of range", append(pad(ec.P), pad(ec.Gy)...), ErrInvalidCoordinate},
+		{"y out of range", append(pad(ec.Gx), pad(ec.P)...), ErrInvalidCoordinate},
+		{"not on curve", append(pad(ec.Gx), pad(new(big.Int).Add(ec.Gy, big.NewInt(1)))...), ErrNotOnCurve},
+		{"compressed not on curve", append([]byte{0x02}, pad(offX)...), ErrNotOnCurve},
+		{"compressed x out of range", append([]byte{0x02}, pad(ec.P)...), ErrInvalidCoordinate},
+		{"compressed odd zero y", append([]byte{0x03}, pad(x2)...), ErrInvalidPrefix},
+		{"order 2", order2.Bytes(ec), ErrNotInSubgroup},
+		{"small subgroup component", mixed.BytesCompressed(ec), ErrNotInSubgroup},
+	}
+	for _, c := range cases {
+		p, err := PointFromBytes(ec, c.b)
+		assert.Nil(t, p, c.name)
+		assert.Equal(t, c.err, err, c.name)
+	}
+
+	_, err := PointFromHex(ec, "zz")
+	assert.Error(t, err)
+}
+
+func TestPointArithmetic(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256()}
+	for _, c := range Curves() {
+		curves = append(curves, c)
+	}
+	for _, ec := range curves {
+		t.Run(ec.Params().Name, func(t *testing.T) {
+			mode := ec.Params().BitSize / 8
+			identity := new(Point).SetInfinity()
+			a := new(Point).ScalarBaseMult(ec, new(big.Int).SetBytes(utils.RandomBytes(mode)))
+			b := new(Point).ScalarBaseMult(ec, new(big.Int).SetBytes(utils.RandomBytes(mode)))
+
+			assert.True(t, identity.IsZero())
+			assert.True(t, new(Point).IsZero())
+			assert.True(t, (*Point)(nil).IsZero())
+			assert.True(t, identity.Equal(nil))
+			assert.False(t, a.Equal(identity))
+			assert.False(t, a.Equal(b))
+
+			// -a = (N - 1)*a has the same X and P - Y
+			neg := new(Point).Neg(ec, a)
+			assert.True(t, neg.Equal(new(Point).ScalarMult(ec, a, new(big.Int).Sub(ec.Params().N, big.NewInt(1)))))
+			assert.Equal(t, a.X.String(), neg.X.String())
+			assert.Equal(t, new(big.Int).Sub(ec.Params().P, a.Y).String(), neg.Y.String())
+			assert.True(t, new(Point).Neg(ec, identity).IsZero())
+
+			assert.True(t, new(Point).Add(ec, a, neg).IsZero())
+			assert.True(t, new(Point).Sub(ec, a, a).IsZero())
+			assert.True(t, new(Point).Sub(ec, identity, a).Equal(neg))
+			assert.True(t, new(Point).Sub(ec, new(Point).Add(ec, a, b), b).Equal(a))
+			assert.True(t, new(Point).Add(ec, a, identity).Equal(a))
+			assert.True(t, new(Point).Double(ec, a).Equal(new(Point).ScalarMult(ec, a, big.NewInt(2))))
+			assert.True(t, new(Point).Double(ec, identity).IsZero())
+			assert.True(t, new(Point).ScalarMult(ec, a, ec.Params().N).IsZero())
+
+			// the receiver may be an operand
+			sum := new(Point).Add(ec, a, b)
+			c := a.Clone()
+			c.Add(ec, c, b)
+			assert.True(t, c.Equal(sum))
+			c.Sub(ec, c, c)
+			assert.True(t, c.IsZero())
+			c.Set(a)
+			c.Neg(ec, c)
+			assert.True(t, c.Equal(neg))
+
+			// results never share coordinates with the operands
+			c = new(Point).Add(ec, a, identity)
+			c.X.SetInt64(1)
+			assert.False(t, c.Equal(a))
+			c = a.Clone()
+			c.Y.SetInt64(1)
+			assert.True(t, a.IsOnCurve(ec))
+
+			assert.True(t, a.IsInSubgroup(ec))
+			assert.True(t, identity.IsInSubgroup(ec))
+
+			// the point at infinity has fixed encodings and no coordinates
+			assert.Equal(t, []byte{0x00}, identity.BytesCompressed(ec))
+			assert.Equal(t, []byte{0x00}, (*Point)(nil).BytesCompressed(ec))
+			assert.Equal(t, make([]byte, 2*mode), new(Point).Bytes(ec))
+			assert.False(t, identity.IsOnCurve(ec))
+			assert.False(t, new(Point).IsOnCurve(ec))
+		})
+	}
+
+	// the point of order 2 is on the curve but not in the subgroup
+	ec := GOST34102012256A
+	x2, y2 := ec.FromEdwards(new(big.Int), new(big.Int).Sub(ec.P, big.NewInt(1)))
+	order2 := &Point{X: x2, Y: y2}
+	assert.False(t, order2.IsInSubgroup(ec))
+	assert.True(t, new(Point).Neg(ec, order2).Equal(order2))
+	assert.True(t, new(Point).Double(ec, order2).IsZero())
+	// the point at infinity survives JSON, older encodings wrote it as (0, 0)
+	for _, data := range []string{`{"X":null,"Y":null}`, `{"X":0,"Y":0}`} {
+		p := new(Point)
+		require.NoError(t, json.Unmarshal([]byte(data), p))
+		assert.Equal(t, new(Point).SetInfinity(), p)
+	}
+	g := &Point{X: ec.Gx, Y: ec.Gy}
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	p := new(Point)
+	require.NoError(t, json.Unmarshal(data, p))
+	assert.True(t, p.Equal(g))
+}
@@ -0,0 +1,444 @@
+package curve
+
+import (
+	"math/big"
+
+	"github.com/AllFi/go-gost3410/curve/internal/field"
+)
+
+/*
+This file implements the group law of CurveParams on ProjectivePoint, over the
+Montgomery field of internal/field. Weierstrass curves use Jacobian coordinates
+with the formulas of crypto/elliptic extended to any coefficient a. Curves with a
+twisted Edwards form use extended Edwards coordinates and complete formulas.
+Only the conversion to affine coordinates takes an inversion.
+*/
+
+// arithmetic holds the field of a curve and its coefficients in Montgomery form.
+type arithmetic struct {
+	f *field.Field
+	// a is the Weierstrass coefficient, b3 = 3*b is used by the complete
+	// formulas of secret.go.
+	a, b3 field.Element
+	// e and d are the Edwards coefficients, s and t the constants of the
+	// birational map, if the curve has an Edwards form.
+	e, d, s, t field.Element
+}
+
+func newArithmetic(curve *CurveParams) *arithmetic {
+	f, err := field.New(curve.P)
+	if err != nil {
+		panic("curve: " + err.Error())
+	}
+	ar := &arithmetic{f: f}
+	f.SetBig(&ar.a, curve.A)
+	f.SetBig(&ar.b3, new(big.Int).Mul(curve.B, big.NewInt(3)))
+	if ed := curve.Edwards; ed != nil {
+		f.SetBig(&ar.e, ed.E)
+		f.SetBig(&ar.d, ed.D)
+		f.SetBig(&ar.s, ed.s)
+		f.SetBig(&ar.t, ed.t)
+	}
+	return ar
+}
+
+// arithmetic returns the precomputed arithmetic of the curve, or computes it
+// for curves that were not built by this package.
+func (curve *CurveParams) arithmetic() *arithmetic {
+	if curve.arith != nil {
+		return curve.arith
+	}
+	return newArithmetic(curve)
+}
+
+/*
+ProjectivePoint is a point of a CurveParams in projective coordinates: Jacobian
+(X/Z^2, Y/Z^3) on Weierstrass curves, extended (X/Z, Y/Z) with T = X*Y/Z in the
+twisted Edwards form otherwise. Sums of projective points need no inversion, so
+long computations should stay in this type and convert with Point at the end.
+*/
+type ProjectivePoint struct {
+	curve      *CurveParams
+	ar         *arithmetic
+	x, y, z, t field.Element
+}
+
+/*
+NewProjectivePoint returns a on the curve in projective coordinates. A nil a or
+the point at infinity gives the neutral element.
+*/
+func NewProjectivePoint(curve *CurveParams, a *Point) *ProjectivePoint {
+	p := newIdentity(curve, curve.arithmetic())
+	if a.IsZero() {
+		return p
+	}
+	p.setAffine(a.X, a.Y)
+	return p
+}
+
+func newIdentity(curve *CurveParams, ar *arithmetic) *ProjectivePoint {
+	p := &ProjectivePoint{curve: curve, ar: ar}
+	if curve.Edwards != nil {
+		// (0 : 1 : 1 : 0)
+		ar.f.SetOne(&p.y)
+		ar.f.SetOne(&p.z)
+	}
+	// Z = 0 on the Weierstrass form
+	return p
+}
+
+func (p *ProjectivePoint) setAffine(x, y *big.Int) {
+	f := p.ar.f
+	var xm, ym field.Element
+	f.SetBig(&xm, x)
+	f.SetBig(&ym, y)
+	if p.curve.Edwards == nil {
+		p.x, p.y = xm, ym
+		f.SetOne(&p.z)
+		return
+	}
+
+	if f.IsZero(&ym) {
+		// (t, 0) is the point of order 2, (0, -1)
+		p.x, p.t = field.Element{}, field.Element{}
+		f.SetOne(&p.z)
+		f.Neg(&p.y, &p.z)
+		return
+	}
+
+	// u = (x - t)/y, v = (x - t - s)/(x - t + s), scaled by Z = y*(x - t + s)
+	var xt, num, den field.Element
+	f.Sub(&xt, &xm, &p.ar.t)
+	f.Sub(&num, &xt, &p.ar.s)
+	f.Add(&den, &xt, &p.ar.s)
+	f.Mul(&p.x, &xt, &den)
+	f.Mul(&p.y, &num, &ym)
+	f.Mul(&p.z, &ym, &den)
+	f.Mul(&p.t, &xt, &num)
+}
+
+/*
+Point returns p in affine Weierstrass coordinates.
+*/
+func (p *ProjectivePoint) Point() *Point {
+	if p.IsIdentity() {
+		return new(Point).SetInfinity()
+	}
+	x, y := p.affine()
+	return &Point{X: x, Y: y}
+}
+
+func (p *ProjectivePoint) affine() (x, y *big.Int) {
+	f := p.ar.f
+	if p.IsIdentity() {
+		return new(big.Int), new(big.Int)
+	}
+
+	if p.curve.Edwards == nil {
+		// x = X/Z^2, y = Y/Z^3
+		var zinv, zinv2, xm, ym field.Element
+		f.Inverse(&zinv, &p.z)
+		f.Square(&zinv2, &zinv)
+		f.Mul(&xm, &p.x, &zinv2)
+		f.Mul(&zinv2, &zinv2, &zinv)
+		f.Mul(&ym, &p.y, &zinv2)
+		return f.Big(&xm), f.Big(&ym)
+	}
+
+	if f.IsZero(&p.x) {
+		// (0, -1) is the point of order 2, (t, 0)
+		return f.Big(&p.ar.t), new(big.Int)
+	}
+
+	// w = s*(Z + Y)/(Z - Y), x = w + t, y = w/u = s*(Z + Y)*Z/((Z - Y)*X)
+	var w, den, inv, xm, ym field.Element
+	f.Add(&w, &p.z, &p.y)
+	f.Mul(&w, &w, &p.ar.s)
+	f.Sub(&den, &p.z, &p.y)
+	f.Mul(&inv, &den, &p.x)
+	f.Inverse(&inv, &inv)
+	f.Mul(&xm, &w, &p.x)
+	f.Mul(&xm, &xm, &inv)
+	f.Add(&xm, &xm, &p.ar.t)
+	f.Mul(&ym, &w, &p.z)
+	f.Mul(&ym, &ym, &inv)
+	return f.Big(&xm), f.Big(&ym)
+}
+
+/*
+Set sets p to a and returns p.
+*/
+func (p *ProjectivePoint) Set(a *ProjectivePoint) *ProjectivePoint {
+	*p = *a
+	return p
+}
+
+/*
+IsIdentity reports whether p is the neutral element.
+*/
+func (p *ProjectivePoint) IsIdentity() bool {
+	f := p.ar.f
+	if p.curve.Edwards == nil {
+		return f.IsZero(&p.z)
+	}
+	// (0 : Y : Y : 0)
+	return f.IsZero(&p.x) && f.Equal(&p.y, &p.z)
+}
+
+/*
+Equal reports whether p and a are the same point.
+*/
+func (p *ProjectivePoint) Equal(a *ProjectivePoint) bool {
+	f := p.ar.f
+	var l, r field.Element
+	if p.curve.Edwards != nil {
+		// X1*Z2 == X2*Z1, Y1*Z2 == Y2*Z1
+		f.Mul(&l, &p.x, &a.z)
+		f.Mul(&r, &a.x, &p.z)
+		if !f.Equal(&l, &r) {
+			return false
+		}
+		f.Mul(&l, &p.y, &a.z)
+		f.Mul(&r, &a.y, &p.z)
+		return f.Equal(&l, &r)
+	}
+
+	if p.IsIdentity() || a.IsIdentity() {
+		return p.IsIdentity() && a.IsIdentity()
+	}
+	// X1*Z2^2 == X2*Z1^2, Y1*Z2^3 == Y2*Z1^3
+	var z1z1, z2z2 field.Element
+	f.Square(&z1z1, &p.z)
+	f.Square(&z2z2, &a.z)
+	f.Mul(&l, &p.x, &z2z2)
+	f.Mul(&r, &a.x, &z1z1)
+	if !f.Equal(&l, &r) {
+		return false
+	}
+	f.Mul(&z1z1, &z1z1, &p.z)
+	f.Mul(&z2z2, &z2z2, &a.z)
+	f.Mul(&l, &p.y, &z2z2)
+	f.Mul(&r, &a.y, &z1z1)
+	return f.Equal(&l, &r)
+}
+
+/*
+Neg sets p to -a and returns p.
+*/
+func (p *ProjectivePoint) Neg(a *ProjectivePoint) *ProjectivePoint {
+	*p = *a
+	if p.curve.Edwards != nil {
+		// -(u, v) = (-u, v)
+		p.ar.f.Neg(&p.x, &a.x)
+		p.ar.f.Neg(&p.t, &a.t)
+		return p
+	}
+	p.ar.f.Neg(&p.y, &a.y)
+	return p
+}
+
+/*
+Add sets p to a + b and returns p.
+*/
+func (p *ProjectivePoint) Add(a, b *ProjectivePoint) *ProjectivePoint {
+	if a.curve.Edwards != nil {
+		return p.edwardsAdd(a, b)
+	}
+	return p.jacobianAdd(a, b)
+}
+
+/*
+Double sets p to 2*a and returns p.
+*/
+func (p *ProjectivePoint) Double(a *ProjectivePoint) *ProjectivePoint {
+	if a.curve.Edwards != nil {
+		return p.edwardsDouble(a)
+	}
+	return p.jacobianDouble(a)
+}
+
+/*
+ScalarMult sets p to k*a and returns p. k must not be negative.
+*/
+func (p *ProjectivePoint) ScalarMult(a *ProjectivePoint, k *big.Int) *ProjectivePoint {
+	// table[j] = j*a
+	var table [1 << strausWindow]*ProjectivePoint
+	table[0] = newIdentity(a.curve, a.ar)
+	table[1] = new(ProjectivePoint).Set(a)
+	for j := 2; j < len(table); j++ {
+		table[j] = new(ProjectivePoint).Add(table[j-1], a)
+	}
+
+	result := newIdentity(a.curve, a.ar)
+	for w := (k.BitLen()+strausWindow-1)/strausWindow - 1; w >= 0; w-- {
+		for j := 0; j < strausWindow; j++ {
+			result.Double(result)
+		}
+		if digit := window(k, w*strausWindow, strausWindow); digit != 0 {
+			result.Add(result, table[digit])
+		}
+	}
+	return p.Set(result)
+}
+
+func (p *ProjectivePoint) jacobianAdd(a, b *ProjectivePoint) *ProjectivePoint {
+	// See https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#addition-add-2007-bl
+	f := a.ar.f
+	if a.IsIdentity() {
+		return p.Set(b)
+	}
+	if b.IsIdentity() {
+		return p.Set(a)
+	}
+
+	var z1z1, z2z2, u1, u2, s1, s2, h, i, j, r, v field.Element
+	f.Square(&z1z1, &a.z)
+	f.Square(&z2z2, &b.z)
+	f.Mul(&u1, &a.x, &z2z2)
+	f.Mul(&u2, &b.x, &z1z1)
+	f.Mul(&s1, &a.y, &b.z)
+	f.Mul(&s1, &s1, &z2z2)
+	f.Mul(&s2, &b.y, &a.z)
+	f.Mul(&s2, &s2, &z1z1)
+	f.Sub(&h, &u2, &u1)
+	f.Sub(&r, &s2, &s1)
+	if f.IsZero(&h) {
+		if f.IsZero(&r) {
+			return p.jacobianDouble(a)
+		}
+		// a = -b, the sum is the point at infinity
+		return p.Set(newIdentity(a.curve, a.ar))
+	}
+
+	// i = (2*h)^2, j = h*i, r = 2*(s2 - s1), v = u1*i
+	f.Double(&i, &h)
+	f.Square(&i, &i)
+	f.Mul(&j, &h, &i)
+	f.Double(&r, &r)
+	f.Mul(&v, &u1, &i)
+
+	var x3, y3, z3 field.Element
+	// x3 = r^2 - j - 2*v
+	f.Square(&x3, &r)
+	f.Sub(&x3, &x3, &j)
+	f.Sub(&x3, &x3, &v)
+	f.Sub(&x3, &x3, &v)
+	// y3 = r*(v - x3) - 2*s1*j
+	f.Sub(&y3, &v, &x3)
+	f.Mul(&y3, &y3, &r)
+	f.Mul(&s1, &s1, &j)
+	f.Double(&s1, &s1)
+	f.Sub(&y3, &y3, &s1)
+	// z3 = ((z1 + z2)^2 - z1z1 - z2z2)*h
+	f.Add(&z3, &a.z, &b.z)
+	f.Square(&z3, &z3)
+	f.Sub(&z3, &z3, &z1z1)
+	f.Sub(&z3, &z3, &z2z2)
+	f.Mul(&z3, &z3, &h)
+
+	p.curve, p.ar = a.curve, a.ar
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+func (p *ProjectivePoint) jacobianDouble(a *ProjectivePoint) *ProjectivePoint {
+	// See https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#doubling-dbl-2007-bl
+	// The identity (Z = 0) and the points of order 2 (Y = 0) give Z3 = 0.
+	f := a.ar.f
+	var xx, yy, yyyy, zz, s, m, tmp field.Element
+	f.Square(&xx, &a.x)
+	f.Square(&yy, &a.y)
+	f.Square(&yyyy, &yy)
+	f.Square(&zz, &a.z)
+
+	// s = 2*((x + yy)^2 - xx - yyyy)
+	f.Add(&s, &a.x, &yy)
+	f.Square(&s, &s)
+	f.Sub(&s, &s, &xx)
+	f.Sub(&s, &s, &yyyy)
+	f.Double(&s, &s)
+
+	// m = 3*xx + a*zz^2
+	f.Square(&m, &zz)
+	f.Mul(&m, &m, &a.ar.a)
+	f.Add(&m, &m, &xx)
+	f.Add(&m, &m, &xx)
+	f.Add(&m, &m, &xx)
+
+	var x3, y3, z3 field.Element
+	// x3 = m^2 - 2*s
+	f.Square(&x3, &m)
+	f.Sub(&x3, &x3, &s)
+	f.Sub(&x3, &x3, &s)
+	// y3 = m*(s - x3) - 8*yyyy
+	f.Sub(&y3, &s, &x3)
+	f.Mul(&y3, &y3, &m)
+	f.Double(&tmp, &yyyy)
+	f.Double(&tmp, &tmp)
+	f.Double(&tmp, &tmp)
+	f.Sub(&y3, &y3, &tmp)
+	// z3 = (y + z)^2 - yy - zz
+	f.Add(&z3, &a.y, &a.z)
+	f.Square(&z3, &z3)
+	f.Sub(&z3, &z3, &yy)
+	f.Sub(&z3, &z3, &zz)
+
+	p.curve, p.ar = a.curve, a.ar
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+func (p *ProjectivePoint) edwardsAdd(a, b *ProjectivePoint) *ProjectivePoint {
+	// See https://hyperelliptic.org/EFD/g1p/auto-twisted-extended.html#addition-add-2008-hwcd
+	// The formula is complete, so it also handles doubling and the neutral element.
+	f := a.ar.f
+	var A, B, C, D, E, F, G, H, tmp field.Element
+	f.Mul(&A, &a.x, &b.x)
+	f.Mul(&B, &a.y, &b.y)
+	f.Mul(&C, &a.t, &b.t)
+	f.Mul(&C, &C, &a.ar.d)
+	f.Mul(&D, &a.z, &b.z)
+	f.Add(&E, &a.x, &a.y)
+	f.Add(&tmp, &b.x, &b.y)
+	f.Mul(&E, &E, &tmp)
+	f.Sub(&E, &E, &A)
+	f.Sub(&E, &E, &B)
+	f.Sub(&F, &D, &C)
+	f.Add(&G, &D, &C)
+	f.Mul(&H, &a.ar.e, &A)
+	f.Sub(&H, &B, &H)
+
+	p.curve, p.ar = a.curve, a.ar
+	p.edwardsFinish(&E, &F, &G, &H)
+	return p
+}
+
+func (p *ProjectivePoint) edwardsDouble(a *ProjectivePoint) *ProjectivePoint {
+	// See https://hyperelliptic.org/EFD/g1p/auto-twisted-extended.html#doubling-dbl-2008-hwcd
+	f := a.ar.f
+	var A, B, C, D, E, F, G, H field.Element
+	f.Square(&A, &a.x)
+	f.Square(&B, &a.y)
+	f.Square(&C, &a.z)
+	f.Double(&C, &C)
+	f.Mul(&D, &a.ar.e, &A)
+	f.Add(&E, &a.x, &a.y)
+	f.Square(&E, &E)
+	f.Sub(&E, &E, &A)
+	f.Sub(&E, &E, &B)
+	f.Add(&G, &D, &B)
+	f.Sub(&F, &G, &C)
+	f.Sub(&H, &D, &B)
+
+	p.curve, p.ar = a.curve, a.ar
+	p.edwardsFinish(&E, &F, &G, &H)
+	return p
+}
+
+func (p *ProjectivePoint) edwardsFinish(E, F, G, H *field.Element) {
+	f := p.ar.f
+	f.Mul(&p.x, E, F)
+	f.Mul(&p.y, G, H)
+	f.Mul(&p.t, E, H)
+	f.Mul(&p.z, F, G)
+}
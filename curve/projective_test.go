@@ -0,0 +1,78 @@
+package curve
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectivePoint(t *testing.T) {
+	for _, c := range Curves() {
+		t.Run(c.Name, func(t *testing.T) {
+			mode := c.BitSize / 8
+			g := NewProjectivePoint(c, &Point{X: c.Gx, Y: c.Gy})
+			assert.Equal(t, c.Gx, g.Point().X)
+			assert.Equal(t, c.Gy, g.Point().Y)
+
+			identity := NewProjectivePoint(c, new(Point).SetInfinity())
+			assert.True(t, identity.IsIdentity())
+			assert.True(t, identity.Point().IsZero())
+			assert.False(t, g.IsIdentity())
+
+			k1 := new(big.Int).SetBytes(utils.RandomBytes(mode))
+			k2 := new(big.Int).SetBytes(utils.RandomBytes(mode))
+			p1 := new(ProjectivePoint).ScalarMult(g, k1)
+			p2 := new(ProjectivePoint).ScalarMult(g, k2)
+
+			// (k1 + k2)*G == k1*G + k2*G, also after a round trip through Point
+			sum := new(ProjectivePoint).Add(p1, p2)
+			expected := new(ProjectivePoint).ScalarMult(g, new(big.Int).Add(k1, k2))
+			assert.True(t, sum.Equal(expected))
+			sum = new(ProjectivePoint).Add(NewProjectivePoint(c, p1.Point()), NewProjectivePoint(c, p2.Point()))
+			assert.True(t, sum.Equal(expected))
+			assert.True(t, c.IsOnCurve(sum.Point().X, sum.Point().Y))
+
+			// 2*P == P + P, P - P == O, P + O == P
+			assert.True(t, new(ProjectivePoint).Double(p1).Equal(new(ProjectivePoint).Add(p1, p1)))
+			neg := new(ProjectivePoint).Neg(p1)
+			assert.True(t, new(ProjectivePoint).Add(p1, neg).IsIdentity())
+			assert.True(t, new(ProjectivePoint).Add(p1, identity).Equal(p1))
+			assert.True(t, new(ProjectivePoint).Add(identity, p1).Equal(p1))
+			assert.False(t, p1.Equal(p2))
+			assert.False(t, p1.Equal(identity))
+
+			// -P is (x, p - y)
+			negPoint := neg.Point()
+			assert.Equal(t, p1.Point().X, negPoint.X)
+			assert.Equal(t, new(big.Int).Sub(c.P, p1.Point().Y), negPoint.Y)
+
+			// N*G == O
+			assert.True(t, new(ProjectivePoint).ScalarMult(g, c.N).IsIdentity())
+		})
+	}
+}
+
+func TestProjectivePointOrderTwo(t *testing.T) {
+	// the point of order 2 of the curves with a cofactor
+	for _, c := range []*CurveParams{GOST34102012256A, GOST34102012512C} {
+		x, y := c.FromEdwards(new(big.Int), new(big.Int).Sub(c.P, big.NewInt(1)))
+		p := NewProjectivePoint(c, &Point{X: x, Y: y})
+		assert.False(t, p.IsIdentity())
+		assert.Equal(t, x, p.Point().X)
+		assert.Equal(t, 0, p.Point().Y.Sign())
+		assert.True(t, new(ProjectivePoint).Double(p).IsIdentity())
+	}
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	for _, c := range []*CurveParams{GOST34102001, GOST34102012256A, GOST34102012512A} {
+		k := utils.RandomBytes(c.BitSize / 8)
+		b.Run(c.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c.ScalarBaseMult(k)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"crypto/subtle"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410/curve/internal/field"
+)
+
+/*
+This file implements scalar multiplication for secret scalars: private keys,
+nonces and blinding factors. The scalar is reduced modulo N and always processed
+over the full bit length of N, the table lookups scan every entry and the group
+law is given by complete formulas, so neither the running time nor the memory
+accesses depend on its value.
+
+Weierstrass curves use the complete formulas of Renes, Costello and Batina in
+homogeneous coordinates (X/Z, Y/Z), which hold for every pair of points of a
+curve of odd order. The extended Edwards formulas are complete already.
+
+ScalarMult and MultiScalarMult skip zero digits and branch on the neutral element
+and must only be given public scalars, as verifiers do.
+*/
+
+/*
+ScalarMultSecret sets p to k*a and returns p in constant time with respect to k,
+using fixed 4-bit windows. k is reduced modulo the order N and may be negative.
+*/
+func (p *ProjectivePoint) ScalarMultSecret(a *ProjectivePoint, k *big.Int) *ProjectivePoint {
+	table := secretTable(a)
+	result := homogeneousIdentity(a.curve, a.ar)
+	entry := new(ProjectivePoint)
+	for _, b := range secretScalar(a.curve, k) {
+		for _, digit := range [2]byte{b >> 4, b & 0xf} {
+			for j := 0; j < strausWindow; j++ {
+				result.completeDouble(result)
+			}
+			entry.lookup(table, digit)
+			result.completeAdd(result, entry)
+		}
+	}
+	return p.fromHomogeneous(result)
+}
+
+/*
+ScalarMultLadder sets p to k*a and returns p in constant time with respect to k,
+with the Montgomery ladder. It needs no table but is slower than
+ScalarMultSecret. k is reduced modulo the order N and may be negative.
+*/
+func (p *ProjectivePoint) ScalarMultLadder(a *ProjectivePoint, k *big.Int) *ProjectivePoint {
+	// r1 - r0 = a throughout, the bits select which of them is doubled
+	r0 := homogeneousIdentity(a.curve, a.ar)
+	r1 := new(ProjectivePoint).toHomogeneous(a)
+	var swapped uint64
+	for _, b := range secretScalar(a.curve, k) {
+		for i := 7; i >= 0; i-- {
+			bit := uint64(b>>uint(i)) & 1
+			conditionalSwap(r0, r1, bit^swapped)
+			swapped = bit
+			r1.completeAdd(r0, r1)
+			r0.completeDouble(r0)
+		}
+	}
+	conditionalSwap(r0, r1, swapped)
+	return p.fromHomogeneous(r0)
+}
+
+/*
+ScalarMultSecret returns k*a in constant time with respect to k. Curves that are
+not described by CurveParams are left to their own ScalarMult.
+*/
+func (p *Point) ScalarMultSecret(ec elliptic.Curve, a *Point, k *big.Int) *Point {
+	if a.IsZero() {
+		return p.SetInfinity()
+	}
+	curve, ok := ec.(*CurveParams)
+	if !ok {
+		return p.ScalarMult(ec, a, k)
+	}
+	result := new(ProjectivePoint).ScalarMultSecret(NewProjectivePoint(curve, a), k)
+	*p = *result.Point()
+	return p
+}
+
+/*
+ScalarBaseMultSecret returns k*G in constant time with respect to k.
+*/
+func (p *Point) ScalarBaseMultSecret(ec elliptic.Curve, k *big.Int) *Point {
+	if curve, ok := ec.(*CurveParams); ok {
+		if table := curve.baseTable(); table != nil {
+			*p = *table.ScalarMultSecret(k)
+			return p
+		}
+	}
+	params := ec.Params()
+	return p.ScalarMultSecret(ec, &Point{X: params.Gx, Y: params.Gy}, k)
+}
+
+/*
+MultiScalarMultSecret returns sum(scalars[i]*points[i]) like MultiScalarMult, but
+in constant time with respect to the scalars. The points are public. Like
+MultiScalarMult it reduces the scalars modulo N, which is only valid for points
+of the subgroup of order N. It panics if points and scalars are of different
+lengths.
+*/
+func MultiScalarMultSecret(ec elliptic.Curve, points []*Point, scalars []*big.Int) *Point {
+	if len(points) != len(scalars) {
+		panic("curve: MultiScalarMultSecret with different numbers of points and scalars")
+	}
+	curve, ok := ec.(*CurveParams)
+	if !ok {
+		sum := new(Point).SetInfinity()
+		for i := range points {
+			sum.Add(ec, sum, new(Point).ScalarMultSecret(ec, points[i], scalars[i]))
+		}
+		return sum
+	}
+
+	tables := make([][]*ProjectivePoint, len(points))
+	for i := range points {
+		tables[i] = secretTable(NewProjectivePoint(curve, points[i]))
+	}
+	return affinePoint(strausSecret(curve, tables, scalars))
+}
+
+// strausSecret returns sum(scalars[i]*P_i) in homogeneous coordinates, where
+// tables[i] is the secretTable of P_i.
+func strausSecret(curve *CurveParams, tables [][]*ProjectivePoint, scalars []*big.Int) *ProjectivePoint {
+	digits := make([][]byte, len(scalars))
+	for i := range scalars {
+		digits[i] = secretScalar(curve, scalars[i])
+	}
+
+	result := homogeneousIdentity(curve, curve.arithmetic())
+	entry := new(ProjectivePoint)
+	for w := 0; w < 2*scalarLen(curve); w++ {
+		for j := 0; j < strausWindow; j++ {
+			result.completeDouble(result)
+		}
+		for i := range digits {
+			digit := digits[i][w/2] >> 4
+			if w%2 == 1 {
+				digit = digits[i][w/2] & 0xf
+			}
+			entry.lookup(tables[i], digit)
+			result.completeAdd(result, entry)
+		}
+	}
+	return result
+}
+
+// affinePoint converts the homogeneous a to affine coordinates.
+func affinePoint(a *ProjectivePoint) *Point {
+	return new(ProjectivePoint).fromHomogeneous(a).Point()
+}
+
+// scalarLen returns the byte length of N.
+func scalarLen(curve *CurveParams) int {
+	return (curve.N.BitLen() + 7) / 8
+}
+
+// secretScalar returns k mod N as a big-endian number of scalarLen bytes.
+func secretScalar(curve *CurveParams, k *big.Int) []byte {
+	b := make([]byte, scalarLen(curve))
+	new(big.Int).Mod(k, curve.N).FillBytes(b)
+	return b
+}
+
+// secretTable returns j*a for 0 <= j < 16 in homogeneous coordinates.
+func secretTable(a *ProjectivePoint) []*ProjectivePoint {
+	return homogeneousTable(new(ProjectivePoint).toHomogeneous(a))
+}
+
+// homogeneousTable returns j*a for 0 <= j < 16, a in homogeneous coordinates.
+func homogeneousTable(a *ProjectivePoint) []*ProjectivePoint {
+	table := make([]*ProjectivePoint, 1<<strausWindow)
+	table[0] = homogeneousIdentity(a.curve, a.ar)
+	table[1] = new(ProjectivePoint).Set(a)
+	for j := 2; j < len(table); j++ {
+		table[j] = new(ProjectivePoint).completeAdd(table[j-1], table[1])
+	}
+	return table
+}
+
+// lookup sets p to table[digit], reading every entry.
+func (p *ProjectivePoint) lookup(table []*ProjectivePoint, digit byte) {
+	f := table[0].ar.f
+	*p = *table[0]
+	for j := 1; j < len(table); j++ {
+		cond := uint64(subtle.ConstantTimeByteEq(digit, byte(j)))
+		f.Select(&p.x, &table[j].x, &p.x, cond)
+		f.Select(&p.y, &table[j].y, &p.y, cond)
+		f.Select(&p.z, &table[j].z, &p.z, cond)
+		f.Select(&p.t, &table[j].t, &p.t, cond)
+	}
+}
+
+// conditionalSwap exchanges a and b if cond is 1.
+func conditionalSwap(a, b *ProjectivePoint, cond uint64) {
+	f := a.ar.f
+	ta := *a
+	f.Select(&a.x, &b.x, &a.x, cond)
+	f.Select(&a.y, &b.y, &a.y, cond)
+	f.Select(&a.z, &b.z, &a.z, cond)
+	f.Select(&a.t, &b.t, &a.t, cond)
+	f.Select(&b.x, &ta.x, &b.x, cond)
+	f.Select(&b.y, &ta.y, &b.y, cond)
+	f.Select(&b.z, &ta.z, &b.z, cond)
+	f.Select(&b.t, &ta.t, &b.t, cond)
+}
+
+// homogeneousIdentity returns the neutral element, (0 : 1 : 0) on the
+// Weierstrass form.
+func homogeneousIdentity(curve *CurveParams, ar *arithmetic) *ProjectivePoint {
+	p := newIdentity(curve, ar)
+	if curve.Edwards == nil {
+		ar.f.SetOne(&p.y)
+	}
+	return p
+}
+
+// toHomogeneous sets p to a with Jacobian coordinates (X, Y, Z) changed to the
+// homogeneous (X*Z, Y, Z^3). Edwards points are copied.
+func (p *ProjectivePoint) toHomogeneous(a *ProjectivePoint) *ProjectivePoint {
+	*p = *a
+	if a.curve.Edwards != nil {
+		return p
+	}
+	f := a.ar.f
+	var z3, one field.Element
+	f.Mul(&p.x, &a.x, &a.z)
+	f.Square(&z3, &a.z)
+	f.Mul(&p.z, &z3, &a.z)
+	// Z = 0 is the neutral element whatever X and Y, make it (0 : 1 : 0)
+	f.SetOne(&one)
+	f.Select(&p.y, &one, &a.y, boolToUint(f.IsZero(&a.z)))
+	return p
+}
+
+// fromHomogeneous sets p to the homogeneous a in Jacobian coordinates
+// (X*Z, Y*Z^2, Z). Edwards points are copied.
+func (p *ProjectivePoint) fromHomogeneous(a *ProjectivePoint) *ProjectivePoint {
+	*p = *a
+	if a.curve.Edwards != nil {
+		return p
+	}
+	f := a.ar.f
+	var zz field.Element
+	f.Mul(&p.x, &a.x, &a.z)
+	f.Square(&zz, &a.z)
+	f.Mul(&p.y, &a.y, &zz)
+	return p
+}
+
+// completeAdd sets p to a + b for homogeneous or Edwards points, without
+// exceptional cases.
+func (p *ProjectivePoint) completeAdd(a, b *ProjectivePoint) *ProjectivePoint {
+	if a.curve.Edwards != nil {
+		return p.edwardsAdd(a, b)
+	}
+
+	// See https://hyperelliptic.org/EFD/g1p/auto-shortw-projective.html#addition-add-2015-rcb
+	f := a.ar.f
+	var t0, t1, t2, t3, t4, t5, x3, y3, z3 field.Element
+	f.Mul(&t0, &a.x, &b.x)
+	f.Mul(&t1, &a.y, &b.y)
+	f.Mul(&t2, &a.z, &b.z)
+	f.Add(&t3, &a.x, &a.y)
+	f.Add(&t4, &b.x, &b.y)
+	f.Mul(&t3, &t3, &t4)
+	f.Add(&t4, &t0, &t1)
+	f.Sub(&t3, &t3, &t4)
+	f.Add(&t4, &a.x, &a.z)
+	f.Add(&t5, &b.x, &b.z)
+	f.Mul(&t4, &t4, &t5)
+	f.Add(&t5, &t0, &t2)
+	f.Sub(&t4, &t4, &t5)
+	f.Add(&t5, &a.y, &a.z)
+	f.Add(&x3, &b.y, &b.z)
+	f.Mul(&t5, &t5, &x3)
+	f.Add(&x3, &t1, &t2)
+	f.Sub(&t5, &t5, &x3)
+	f.Mul(&z3, &a.ar.a, &t4)
+	f.Mul(&x3, &a.ar.b3, &t2)
+	f.Add(&z3, &x3, &z3)
+	f.Sub(&x3, &t1, &z3)
+	f.Add(&z3, &t1, &z3)
+	f.Mul(&y3, &x3, &z3)
+	f.Add(&t1, &t0, &t0)
+	f.Add(&t1, &t1, &t0)
+	f.Mul(&t2, &a.ar.a, &t2)
+	f.Mul(&t4, &a.ar.b3, &t4)
+	f.Add(&t1, &t1, &t2)
+	f.Sub(&t2, &t0, &t2)
+	f.Mul(&t2, &a.ar.a, &t2)
+	f.Add(&t4, &t4, &t2)
+	f.Mul(&t0, &t1, &t4)
+	f.Add(&y3, &y3, &t0)
+	f.Mul(&t0, &t5, &t4)
+	f.Mul(&x3, &t3, &x3)
+	f.Sub(&x3, &x3, &t0)
+	f.Mul(&t0, &t3, &t1)
+	f.Mul(&z3, &t5, &z3)
+	f.Add(&z3, &z3, &t0)
+
+	p.curve, p.ar = a.curve, a.ar
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// completeDouble sets p to 2*a for homogeneous or Edwards points.
+func (p *ProjectivePoint) completeDouble(a *ProjectivePoint) *ProjectivePoint {
+	if a.curve.Edwards != nil {
+		return p.edwardsDouble(a)
+	}
+	return p.completeAdd(a, a)
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
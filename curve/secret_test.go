@@ -0,0 +1,85 @@
+package curve
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalarMultSecret(t *testing.T) {
+	for _, c := range Curves() {
+		t.Run(c.Name, func(t *testing.T) {
+			mode := c.BitSize / 8
+			one := big.NewInt(1)
+			scalars := []*big.Int{
+				new(big.Int),
+				one,
+				big.NewInt(15),
+				big.NewInt(16),
+				new(big.Int).Sub(c.N, one),
+				c.N,
+				new(big.Int).Add(c.N, one),
+				big.NewInt(-1),
+				new(big.Int).SetBytes(utils.RandomBytes(mode)),
+				new(big.Int).SetBytes(utils.RandomBytes(2 * mode)),
+			}
+
+			g := NewProjectivePoint(c, &Point{X: c.Gx, Y: c.Gy})
+			a := new(ProjectivePoint).ScalarMult(g, new(big.Int).SetBytes(utils.RandomBytes(mode)))
+			for _, k := range scalars {
+				expected := new(ProjectivePoint).ScalarMult(a, new(big.Int).Mod(k, c.N))
+				assert.True(t, new(ProjectivePoint).ScalarMultSecret(a, k).Equal(expected), "k = %v", k)
+				assert.True(t, new(ProjectivePoint).ScalarMultLadder(a, k).Equal(expected), "k = %v", k)
+
+				expectedPoint := new(Point).ScalarBaseMult(c, new(big.Int).Mod(k, c.N))
+				assertSamePoint(t, expectedPoint, new(Point).ScalarBaseMultSecret(c, k), "k = %v", k)
+			}
+
+			// the neutral element stays where it is
+			identity := NewProjectivePoint(c, nil)
+			assert.True(t, new(ProjectivePoint).ScalarMultSecret(identity, scalars[8]).IsIdentity())
+			assert.True(t, new(ProjectivePoint).ScalarMultLadder(identity, scalars[8]).IsIdentity())
+			assert.True(t, new(Point).ScalarMultSecret(c, new(Point).SetInfinity(), one).IsZero())
+		})
+	}
+}
+
+func TestMultiScalarMultSecret(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256()}
+	for _, c := range Curves() {
+		curves = append(curves, c)
+	}
+	for _, ec := range curves {
+		t.Run(ec.Params().Name, func(t *testing.T) {
+			for _, n := range []int{0, 1, 5} {
+				points, scalars := randomTerms(ec, n)
+				assertSamePoint(t, naiveMultiScalarMult(ec, points, scalars), MultiScalarMultSecret(ec, points, scalars), "n = %d", n)
+			}
+
+			// zero and negative scalars, P - P
+			points, scalars := randomTerms(ec, 3)
+			points = append(points, points[0], new(Point).SetInfinity())
+			scalars = append(scalars, new(big.Int).Neg(scalars[0]), big.NewInt(7))
+			scalars[1] = new(big.Int)
+			assertSamePoint(t, naiveMultiScalarMult(ec, points, scalars), MultiScalarMultSecret(ec, points, scalars))
+		})
+	}
+
+	assert.Panics(t, func() {
+		MultiScalarMultSecret(GOST34102001, make([]*Point, 2), make([]*big.Int, 1))
+	})
+}
+
+func BenchmarkScalarBaseMultSecret(b *testing.B) {
+	for _, c := range []*CurveParams{GOST34102001, GOST34102012256A, GOST34102012512A} {
+		b.Run(c.Name, func(b *testing.B) {
+			k := new(big.Int).SetBytes(utils.RandomBytes(c.BitSize / 8))
+			for i := 0; i < b.N; i++ {
+				new(Point).ScalarBaseMultSecret(c, k)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package curve
+
+import (
+	"math/big"
+)
+
+/*
+This file implements elliptic.Curve for CurveParams. The group operations are
+computed on ProjectivePoint and converted back to affine coordinates, where, as
+in crypto/elliptic, the point at infinity is represented by (0, 0).
+*/
+
+// IsOnCurve reports whether the given (x, y) lies on the curve.
+func (curve *CurveParams) IsOnCurve(x, y *big.Int) bool {
+	p := curve.P
+	if x.Sign() < 0 || x.Cmp(p) >= 0 || y.Sign() < 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+
+	// y^2 = x^3 + a*x + b
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, p)
+	return curve.polynomial(x).Cmp(y2) == 0
+}
+
+// polynomial returns x^3 + a*x + b mod p.
+func (curve *CurveParams) polynomial(x *big.Int) *big.Int {
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+
+	ax := new(big.Int).Mul(curve.A, x)
+
+	x3.Add(x3, ax)
+	x3.Add(x3, curve.B)
+	x3.Mod(x3, curve.P)
+	return x3
+}
+
+// Add returns the sum of (x1, y1) and (x2, y2).
+func (curve *CurveParams) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	p := curve.projective(x1, y1)
+	return p.Add(p, curve.projective(x2, y2)).affine()
+}
+
+// Double returns 2*(x, y).
+func (curve *CurveParams) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	p := curve.projective(x1, y1)
+	return p.Double(p).affine()
+}
+
+// ScalarMult returns k*(Bx, By) where k is a number in big-endian form.
+func (curve *CurveParams) ScalarMult(Bx, By *big.Int, k []byte) (*big.Int, *big.Int) {
+	p := curve.projective(Bx, By)
+	return p.ScalarMult(p, new(big.Int).SetBytes(k)).affine()
+}
+
+// ScalarBaseMult returns k*G, where G is the base point of the group and k is
+// an integer in big-endian form.
+func (curve *CurveParams) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	table := curve.baseTable()
+	if table == nil {
+		return curve.ScalarMult(curve.Gx, curve.Gy, k)
+	}
+	p := table.ScalarMult(new(big.Int).SetBytes(k))
+	if p.IsZero() {
+		return new(big.Int), new(big.Int)
+	}
+	return p.X, p.Y
+}
+
+// projective converts the affine (x, y), with (0, 0) as the point at infinity.
+func (curve *CurveParams) projective(x, y *big.Int) *ProjectivePoint {
+	p := newIdentity(curve, curve.arithmetic())
+	if x.Sign() != 0 || y.Sign() != 0 {
+		p.setAffine(x, y)
+	}
+	return p
+}
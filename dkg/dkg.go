@@ -0,0 +1,699 @@
+/*
+Package dkg implements distributed key generation for aggsig threshold keys
+after Gennaro, Jarecki, Krawczyk and Rabin ("Secure Distributed Key Generation
+for Discrete-Log Based Cryptosystems"). Each participant i = 1, ..., n runs a
+Participant through three phases:
+
+ 1. Sharing. Deal produces the Pedersen Commitments to broadcast and one Share
+    for every other participant. ReceiveShare checks a share against the
+    commitments of its dealer and returns a Complaint if it does not match;
+    MissingShares complains about shares that never arrived. A dealer answers
+    every complaint with Justify.
+ 2. Extraction. PublishCoefficients fixes the set of qualified dealers and
+    returns the Feldman PublicCoefficients to broadcast. ReceivePublicCoefficients
+    returns a Complaint with evidence if the share of a dealer does not match its
+    coefficients; RevealShare then lets the others reconstruct that dealer's
+    contribution.
+ 3. Finalize returns the Shamir share of the participant and the group public key.
+
+All messages are plain structs; the caller delivers a Share only to its Receiver
+and broadcasts everything else. Messages from a participant to itself are
+handled internally and must not be delivered back. Messages of the sharing phase
+may arrive before the participant has dealt.
+*/
+package dkg
+
+import (
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+type phase int
+
+const (
+	phaseDeal phase = iota
+	phaseSharing
+	phaseExtraction
+	phaseDone
+)
+
+/*
+Result is the outcome of a successful run.
+*/
+type Result struct {
+	// Share is the Shamir share of the group private key held by the participant.
+	Share *aggsig.Share
+	// PublicKey is the group public key.
+	PublicKey *aggsig.PublicKey
+	// VerificationKeys[i-1] is the public key of the share of participant i.
+	VerificationKeys []*aggsig.PublicKey
+	// Qualified are the indices of the dealers that contributed to the key.
+	Qualified []int
+}
+
+/*
+Participant is the state of one participant of a run. It is not safe for
+concurrent use.
+*/
+type Participant struct {
+	context *gost3410.Context
+	index   int
+	t, n    int
+	phase   phase
+
+	// the polynomials f and f' of this participant as a dealer
+	coefficients []*big.Int
+	blindings    []*big.Int
+
+	commitments  map[int][]*curve.Point
+	shares       map[int]*Share
+	complaints   map[int]map[int]bool
+	disqualified map[int]bool
+	qualified    []int
+
+	publicCoefficients map[int][]*curve.Point
+	reconstruct        map[int]bool
+	revealed           map[int]map[int]*big.Int
+}
+
+/*
+NewParticipant creates participant index of a run with threshold t out of n. The
+polynomials are drawn from rand.
+*/
+func NewParticipant(context *gost3410.Context, index, t, n int, rand io.Reader) (participant *Participant, err error) {
+	if t < 1 || t > n {
+		err = errors.New("threshold must be between 1 and n")
+		return
+	}
+	if index < 1 || index > n {
+		err = errors.New("index must be between 1 and n")
+		return
+	}
+
+	coefficients := make([]*big.Int, t)
+	blindings := make([]*big.Int, t)
+	for k := 0; k < t; k++ {
+		if coefficients[k], err = randomScalar(context, rand); err != nil {
+			return nil, errors.Wrap(err, "cannot randomScalar")
+		}
+		if blindings[k], err = randomScalar(context, rand); err != nil {
+			return nil, errors.Wrap(err, "cannot randomScalar")
+		}
+	}
+
+	return &Participant{
+		context:            context,
+		index:              index,
+		t:                  t,
+		n:                  n,
+		coefficients:       coefficients,
+		blindings:          blindings,
+		commitments:        make(map[int][]*curve.Point),
+		shares:             make(map[int]*Share),
+		complaints:         make(map[int]map[int]bool),
+		disqualified:       make(map[int]bool),
+		publicCoefficients: make(map[int][]*curve.Point),
+		reconstruct:        make(map[int]bool),
+		revealed:           make(map[int]map[int]*big.Int),
+	}, nil
+}
+
+func randomScalar(context *gost3410.Context, rand io.Reader) (*big.Int, error) {
+	q := context.Curve.Params().N
+	raw := make([]byte, context.Curve.Params().BitSize/8+8)
+	if _, err := io.ReadFull(rand, raw); err != nil {
+		return nil, errors.Wrap(err, "cannot read")
+	}
+	return new(big.Int).Mod(utils.BytesToBigInt(raw), q), nil
+}
+
+/*
+Deal starts the sharing phase. commitments must be broadcast and shares[j] must
+be sent privately to its Receiver.
+*/
+func (p *Participant) Deal() (commitments *Commitments, shares []*Share, err error) {
+	if p.phase != phaseDeal {
+		err = errors.New("Deal called out of order")
+		return
+	}
+
+	// C_k = a_k*G + b_k*H
+	points := make([]*curve.Point, p.t)
+	for k := 0; k < p.t; k++ {
+		points[k] = p.commit(p.coefficients[k], p.blindings[k])
+	}
+	p.commitments[p.index] = points
+
+	for j := 1; j <= p.n; j++ {
+		share := p.share(j)
+		if j == p.index {
+			p.shares[p.index] = share
+			continue
+		}
+		shares = append(shares, share)
+	}
+
+	p.phase = phaseSharing
+	return &Commitments{Dealer: p.index, Points: points}, shares, nil
+}
+
+func (p *Participant) share(receiver int) *Share {
+	mode := p.context.Curve.Params().BitSize / 8
+	x := big.NewInt(int64(receiver))
+	value := aggsig.EvaluatePolynomial(p.context, p.coefficients, x)
+	blinding := aggsig.EvaluatePolynomial(p.context, p.blindings, x)
+	return &Share{
+		Dealer:   p.index,
+		Receiver: receiver,
+		Value:    utils.Pad(value.Bytes(), mode),
+		Blinding: utils.Pad(blinding.Bytes(), mode),
+	}
+}
+
+/*
+ReceiveCommitments records the broadcast commitments of a dealer.
+*/
+func (p *Participant) ReceiveCommitments(commitments *Commitments) error {
+	if p.phase > phaseSharing {
+		return errors.New("ReceiveCommitments called out of order")
+	}
+	if err := p.checkPeer(commitments.Dealer); err != nil {
+		return err
+	}
+	if _, ok := p.commitments[commitments.Dealer]; ok {
+		return errors.Errorf("commitments of dealer %d are already known", commitments.Dealer)
+	}
+	if len(commitments.Points) != p.t {
+		return errors.Errorf("dealer %d sent %d commitments, want %d", commitments.Dealer, len(commitments.Points), p.t)
+	}
+	for _, point := range commitments.Points {
+		if !validPoint(p.context, point) {
+			return errors.Errorf("invalid commitment of dealer %d", commitments.Dealer)
+		}
+	}
+
+	p.commitments[commitments.Dealer] = commitments.Points
+	return nil
+}
+
+/*
+ReceiveShare checks the private share from a dealer against its commitments. If
+it does not match, the share is dropped and the returned complaint must be
+broadcast.
+*/
+func (p *Participant) ReceiveShare(share *Share) (complaint *Complaint, err error) {
+	if p.phase > phaseSharing {
+		err = errors.New("ReceiveShare called out of order")
+		return
+	}
+	if err = p.checkPeer(share.Dealer); err != nil {
+		return
+	}
+	if share.Receiver != p.index {
+		err = errors.New("share is addressed to another participant")
+		return
+	}
+	if _, ok := p.commitments[share.Dealer]; !ok {
+		err = errors.Errorf("commitments of dealer %d are not known", share.Dealer)
+		return
+	}
+	if _, ok := p.shares[share.Dealer]; ok {
+		err = errors.Errorf("share of dealer %d is already known", share.Dealer)
+		return
+	}
+
+	if !p.verifyShare(share) {
+		return p.complain(share.Dealer), nil
+	}
+	p.shares[share.Dealer] = share
+	return nil, nil
+}
+
+/*
+MissingShares returns a complaint, to be broadcast, against every dealer whose
+commitments are known but whose share has not arrived and has not been complained
+about yet.
+*/
+func (p *Participant) MissingShares() (complaints []*Complaint, err error) {
+	if p.phase != phaseSharing {
+		err = errors.New("MissingShares called out of order")
+		return
+	}
+	for _, dealer := range p.sortedDealers() {
+		if _, ok := p.shares[dealer]; ok || p.complaints[dealer][p.index] {
+			continue
+		}
+		complaints = append(complaints, p.complain(dealer))
+	}
+	return
+}
+
+func (p *Participant) complain(dealer int) *Complaint {
+	p.addComplaint(dealer, p.index)
+	return &Complaint{Accuser: p.index, Dealer: dealer}
+}
+
+func (p *Participant) addComplaint(dealer, accuser int) {
+	if p.complaints[dealer] == nil {
+		p.complaints[dealer] = make(map[int]bool)
+	}
+	p.complaints[dealer][accuser] = true
+}
+
+/*
+ReceiveComplaint records a complaint broadcast by another participant.
+*/
+func (p *Participant) ReceiveComplaint(complaint *Complaint) error {
+	if err := p.checkPeer(complaint.Accuser); err != nil {
+		return err
+	}
+	if complaint.Dealer < 1 || complaint.Dealer > p.n {
+		return errors.New("dealer out of range")
+	}
+
+	switch p.phase {
+	case phaseDeal, phaseSharing:
+		if complaint.Share != nil {
+			return errors.New("complaint with evidence during the sharing phase")
+		}
+		p.addComplaint(complaint.Dealer, complaint.Accuser)
+		return nil
+	case phaseExtraction:
+		return p.receiveExtractionComplaint(complaint)
+	default:
+		return errors.New("ReceiveComplaint called out of order")
+	}
+}
+
+/*
+Justify answers a complaint against this participant by revealing the share of
+the accuser. The returned share must be broadcast.
+*/
+func (p *Participant) Justify(complaint *Complaint) (share *Share, err error) {
+	if p.phase != phaseSharing {
+		err = errors.New("Justify called out of order")
+		return
+	}
+	if complaint.Dealer != p.index {
+		err = errors.New("complaint against another dealer")
+		return
+	}
+	if !p.complaints[p.index][complaint.Accuser] {
+		err = errors.New("unknown complaint")
+		return
+	}
+
+	delete(p.complaints[p.index], complaint.Accuser)
+	return p.share(complaint.Accuser), nil
+}
+
+/*
+ReceiveJustification checks a share broadcast by a dealer in answer to a
+complaint. A dealer whose justification does not match its commitments is
+disqualified.
+*/
+func (p *Participant) ReceiveJustification(share *Share) error {
+	if p.phase > phaseSharing {
+		return errors.New("ReceiveJustification called out of order")
+	}
+	if err := p.checkPeer(share.Dealer); err != nil {
+		return err
+	}
+	if !p.complaints[share.Dealer][share.Receiver] {
+		return errors.Errorf("no complaint of participant %d against dealer %d", share.Receiver, share.Dealer)
+	}
+	if _, ok := p.commitments[share.Dealer]; !ok {
+		return errors.Errorf("commitments of dealer %d are not known", share.Dealer)
+	}
+
+	if !p.verifyShare(share) {
+		p.disqualified[share.Dealer] = true
+		return nil
+	}
+	delete(p.complaints[share.Dealer], share.Receiver)
+	if share.Receiver == p.index {
+		p.shares[share.Dealer] = share
+	}
+	return nil
+}
+
+/*
+PublishCoefficients ends the sharing phase. It fixes the set of qualified
+dealers: those whose commitments are known and whose complaints have all been
+justified. If this participant is qualified the returned coefficients must be
+broadcast, otherwise they are nil.
+*/
+func (p *Participant) PublishCoefficients() (publicCoefficients *PublicCoefficients, err error) {
+	if p.phase != phaseSharing {
+		err = errors.New("PublishCoefficients called out of order")
+		return
+	}
+
+	p.qualified = nil
+	for _, dealer := range p.sortedDealers() {
+		if p.disqualified[dealer] || len(p.complaints[dealer]) > 0 {
+			continue
+		}
+		if _, ok := p.shares[dealer]; !ok {
+			err = errors.Errorf("share of qualified dealer %d is missing", dealer)
+			return
+		}
+		p.qualified = append(p.qualified, dealer)
+	}
+	if len(p.qualified) == 0 {
+		err = errors.New("no qualified dealers")
+		return
+	}
+
+	p.phase = phaseExtraction
+	if !p.isQualified(p.index) {
+		return nil, nil
+	}
+
+	// A_k = a_k*G
+	points := make([]*curve.Point, p.t)
+	for k := 0; k < p.t; k++ {
+		points[k] = new(curve.Point).ScalarBaseMultSecret(p.context.Curve, p.coefficients[k])
+	}
+	p.publicCoefficients[p.index] = points
+	return &PublicCoefficients{Dealer: p.index, Points: points}, nil
+}
+
+/*
+ReceivePublicCoefficients records the broadcast coefficients of a qualified
+dealer. If the share of this participant does not match them, the returned
+complaint must be broadcast.
+*/
+func (p *Participant) ReceivePublicCoefficients(publicCoefficients *PublicCoefficients) (complaint *Complaint, err error) {
+	if p.phase != phaseExtraction {
+		err = errors.New("ReceivePublicCoefficients called out of order")
+		return
+	}
+	dealer := publicCoefficients.Dealer
+	if err = p.checkPeer(dealer); err != nil {
+		return
+	}
+	if !p.isQualified(dealer) {
+		err = errors.Errorf("dealer %d is not qualified", dealer)
+		return
+	}
+	if _, ok := p.publicCoefficients[dealer]; ok {
+		err = errors.Errorf("coefficients of dealer %d are already known", dealer)
+		return
+	}
+	if len(publicCoefficients.Points) != p.t {
+		err = errors.Errorf("dealer %d sent %d coefficients, want %d", dealer, len(publicCoefficients.Points), p.t)
+		return
+	}
+	for _, point := range publicCoefficients.Points {
+		if !validPoint(p.context, point) {
+			err = errors.Errorf("invalid coefficient of dealer %d", dealer)
+			return
+		}
+	}
+
+	p.publicCoefficients[dealer] = publicCoefficients.Points
+	if p.verifyCoefficients(p.shares[dealer]) {
+		return nil, nil
+	}
+	p.reconstruct[dealer] = true
+	return &Complaint{Accuser: p.index, Dealer: dealer, Share: p.shares[dealer]}, nil
+}
+
+func (p *Participant) receiveExtractionComplaint(complaint *Complaint) error {
+	share := complaint.Share
+	if share == nil || share.Dealer != complaint.Dealer || share.Receiver != complaint.Accuser {
+		return errors.New("complaint without matching evidence")
+	}
+	if !p.isQualified(complaint.Dealer) {
+		return errors.Errorf("dealer %d is not qualified", complaint.Dealer)
+	}
+	if _, ok := p.publicCoefficients[complaint.Dealer]; !ok {
+		return errors.Errorf("coefficients of dealer %d are not known", complaint.Dealer)
+	}
+
+	// the evidence must be a share the dealer committed to that does not match
+	// its coefficients, otherwise the accuser is lying
+	if !p.verifyShare(share) || p.verifyCoefficients(share) {
+		return errors.Errorf("false complaint of participant %d", complaint.Accuser)
+	}
+	p.reconstruct[complaint.Dealer] = true
+	return nil
+}
+
+/*
+RevealShare returns the share this participant holds from dealer, to be
+broadcast so that the contribution of a dealer that failed the extraction phase
+can be reconstructed.
+*/
+func (p *Participant) RevealShare(dealer int) (share *Share, err error) {
+	if p.phase != phaseExtraction {
+		err = errors.New("RevealShare called out of order")
+		return
+	}
+	if !p.isQualified(dealer) || dealer == p.index {
+		err = errors.Errorf("cannot reveal the share of dealer %d", dealer)
+		return
+	}
+	if _, ok := p.publicCoefficients[dealer]; ok && !p.reconstruct[dealer] {
+		err = errors.Errorf("dealer %d does not need reconstruction", dealer)
+		return
+	}
+
+	p.reconstruct[dealer] = true
+	p.addRevealed(p.shares[dealer])
+	return p.shares[dealer], nil
+}
+
+/*
+ReceiveRevealedShare records a share revealed by another participant. Shares are
+only accepted for dealers that need reconstruction, that is dealers that did not
+publish their coefficients or against which an extraction complaint was upheld,
+so that nobody can expose the shares of an honest dealer.
+*/
+func (p *Participant) ReceiveRevealedShare(share *Share) error {
+	if p.phase != phaseExtraction {
+		return errors.New("ReceiveRevealedShare called out of order")
+	}
+	if err := p.checkPeer(share.Receiver); err != nil {
+		return err
+	}
+	if !p.isQualified(share.Dealer) {
+		return errors.Errorf("dealer %d is not qualified", share.Dealer)
+	}
+	if _, ok := p.publicCoefficients[share.Dealer]; ok && !p.reconstruct[share.Dealer] {
+		return errors.Errorf("dealer %d does not need reconstruction", share.Dealer)
+	}
+	if !p.verifyShare(share) {
+		return errors.Errorf("revealed share of participant %d does not match the commitments", share.Receiver)
+	}
+
+	p.reconstruct[share.Dealer] = true
+	p.addRevealed(share)
+	return nil
+}
+
+func (p *Participant) addRevealed(share *Share) {
+	if p.revealed[share.Dealer] == nil {
+		p.revealed[share.Dealer] = make(map[int]*big.Int)
+	}
+	p.revealed[share.Dealer][share.Receiver] = utils.BytesToBigInt(share.Value)
+}
+
+/*
+Finalize ends the run. Dealers that failed the extraction phase contribute the
+coefficients reconstructed from at least t revealed shares.
+*/
+func (p *Participant) Finalize() (result *Result, err error) {
+	if p.phase != phaseExtraction {
+		err = errors.New("Finalize called out of order")
+		return
+	}
+
+	q := p.context.Curve.Params().N
+	mode := p.context.Curve.Params().BitSize / 8
+	value := new(big.Int)
+	coefficients := make([]*curve.Point, p.t)
+	for k := range coefficients {
+		coefficients[k] = new(curve.Point).SetInfinity()
+	}
+
+	for _, dealer := range p.qualified {
+		points, ok := p.publicCoefficients[dealer]
+		if !ok || p.reconstruct[dealer] {
+			if points, err = p.reconstructCoefficients(dealer); err != nil {
+				return nil, errors.Wrapf(err, "cannot reconstruct dealer %d", dealer)
+			}
+		}
+		for k := range coefficients {
+			coefficients[k].Add(p.context.Curve, coefficients[k], points[k])
+		}
+		value.Add(value, utils.BytesToBigInt(p.shares[dealer].Value))
+	}
+	value.Mod(value, q)
+
+	if coefficients[0].IsZero() {
+		err = errors.New("group public key is the point at infinity")
+		return
+	}
+
+	verificationKeys := make([]*aggsig.PublicKey, p.n)
+	for i := 1; i <= p.n; i++ {
+		verificationKeys[i-1] = &aggsig.PublicKey{Point: evaluatePoints(p.context, coefficients, big.NewInt(int64(i)))}
+	}
+
+	p.phase = phaseDone
+	return &Result{
+		Share:            &aggsig.Share{Index: p.index, PrivateKey: utils.Pad(value.Bytes(), mode)},
+		PublicKey:        &aggsig.PublicKey{Point: coefficients[0]},
+		VerificationKeys: verificationKeys,
+		Qualified:        append([]int{}, p.qualified...),
+	}, nil
+}
+
+// reconstructCoefficients interpolates the secret polynomial of dealer from the
+// revealed shares and returns its public coefficients.
+func (p *Participant) reconstructCoefficients(dealer int) ([]*curve.Point, error) {
+	revealed := p.revealed[dealer]
+	if revealed == nil {
+		revealed = make(map[int]*big.Int)
+	}
+	if _, ok := revealed[p.index]; !ok {
+		revealed[p.index] = utils.BytesToBigInt(p.shares[dealer].Value)
+	}
+	if len(revealed) < p.t {
+		return nil, errors.Errorf("%d shares revealed, need %d", len(revealed), p.t)
+	}
+
+	indices := make([]int, 0, len(revealed))
+	for index := range revealed {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	indices = indices[:p.t]
+
+	coefficients, err := interpolate(p.context, indices, revealed)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot interpolate")
+	}
+	points := make([]*curve.Point, p.t)
+	for k := 0; k < p.t; k++ {
+		points[k] = new(curve.Point).ScalarBaseMultSecret(p.context.Curve, coefficients[k])
+	}
+	return points, nil
+}
+
+// interpolate returns the coefficients of the polynomial of degree
+// len(indices) - 1 that takes values[i] at i.
+func interpolate(context *gost3410.Context, indices []int, values map[int]*big.Int) ([]*big.Int, error) {
+	q := context.Curve.Params().N
+	coefficients := make([]*big.Int, len(indices))
+	for k := range coefficients {
+		coefficients[k] = new(big.Int)
+	}
+
+	for _, i := range indices {
+		// the Lagrange basis polynomial l_i(x) = prod((x - j)/(i - j))
+		basis := []*big.Int{big.NewInt(1)}
+		den := big.NewInt(1)
+		for _, j := range indices {
+			if j == i {
+				continue
+			}
+			next := make([]*big.Int, len(basis)+1)
+			for k := range next {
+				next[k] = new(big.Int)
+			}
+			for k, c := range basis {
+				next[k+1].Add(next[k+1], c)
+				next[k].Sub(next[k], new(big.Int).Mul(c, big.NewInt(int64(j))))
+			}
+			basis = next
+			den.Mul(den, big.NewInt(int64(i-j)))
+		}
+		den.Mod(den, q)
+		scale := new(big.Int).ModInverse(den, q)
+		if scale == nil {
+			return nil, errors.New("indices are not distinct modulo q")
+		}
+		scale.Mul(scale, values[i])
+		for k, c := range basis {
+			coefficients[k].Add(coefficients[k], new(big.Int).Mul(c, scale))
+			coefficients[k].Mod(coefficients[k], q)
+		}
+	}
+	return coefficients, nil
+}
+
+// commit returns a*G + b*H in constant time, a and b are secret.
+func (p *Participant) commit(a, b *big.Int) *curve.Point {
+	point := curve.FixedBaseG(p.context).ScalarMultSecret(a)
+	return point.Add(p.context.Curve, point, curve.FixedBaseH(p.context).ScalarMultSecret(b))
+}
+
+// verifyShare checks f(i)*G + f'(i)*H == sum(C_k * i^k).
+func (p *Participant) verifyShare(share *Share) bool {
+	mode := p.context.Curve.Params().BitSize / 8
+	if len(share.Value) != mode || len(share.Blinding) != mode {
+		return false
+	}
+	expected := evaluatePoints(p.context, p.commitments[share.Dealer], big.NewInt(int64(share.Receiver)))
+	actual := p.commit(utils.BytesToBigInt(share.Value), utils.BytesToBigInt(share.Blinding))
+	return expected.Equal(actual)
+}
+
+// verifyCoefficients checks f(i)*G == sum(A_k * i^k).
+func (p *Participant) verifyCoefficients(share *Share) bool {
+	expected := evaluatePoints(p.context, p.publicCoefficients[share.Dealer], big.NewInt(int64(share.Receiver)))
+	actual := new(curve.Point).ScalarBaseMultSecret(p.context.Curve, utils.BytesToBigInt(share.Value))
+	return expected.Equal(actual)
+}
+
+// evaluatePoints returns sum(points[k] * x^k).
+func evaluatePoints(context *gost3410.Context, points []*curve.Point, x *big.Int) *curve.Point {
+	// Horner's rule
+	result := new(curve.Point).SetInfinity()
+	for k := len(points) - 1; k >= 0; k-- {
+		result = new(curve.Point).ScalarMult(context.Curve, result, x)
+		result.Add(context.Curve, result, points[k])
+	}
+	return result
+}
+
+// validPoint reports whether point is a point of the subgroup of order q other
+// than the point at infinity, so that no small-order component can be smuggled
+// into the commitments or coefficients on curves with a cofactor.
+func validPoint(context *gost3410.Context, point *curve.Point) bool {
+	return point.Validate(context.Curve) == nil
+}
+
+func (p *Participant) checkPeer(index int) error {
+	if index < 1 || index > p.n {
+		return errors.New("index out of range")
+	}
+	if index == p.index {
+		return errors.New("index of this participant")
+	}
+	return nil
+}
+
+func (p *Participant) isQualified(dealer int) bool {
+	i := sort.SearchInts(p.qualified, dealer)
+	return i < len(p.qualified) && p.qualified[i] == dealer
+}
+
+func (p *Participant) sortedDealers() []int {
+	dealers := make([]int, 0, len(p.commitments))
+	for dealer := range p.commitments {
+		dealers = append(dealers, dealer)
+	}
+	sort.Ints(dealers)
+	return dealers
+}
@@ -0,0 +1,383 @@
+package dkg
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testContext = gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+
+// run holds the participants of a test run, participants[i-1] has index i.
+type run struct {
+	t            *testing.T
+	participants []*Participant
+}
+
+func newRun(t *testing.T, threshold, n int) *run {
+	participants := make([]*Participant, n)
+	for i := 1; i <= n; i++ {
+		participant, err := NewParticipant(testContext, i, threshold, n, rand.Reader)
+		require.NoError(t, err)
+		participants[i-1] = participant
+	}
+	return &run{t: t, participants: participants}
+}
+
+// broadcastComplaint delivers a complaint to everybody but its accuser.
+func (r *run) broadcastComplaint(complaint *Complaint) {
+	for _, p := range r.participants {
+		if p.index != complaint.Accuser {
+			require.NoError(r.t, p.ReceiveComplaint(complaint))
+		}
+	}
+}
+
+// share runs the sharing phase; tamper may modify a share before delivery.
+func (r *run) share(tamper func(share *Share)) {
+	shares := make([][]*Share, len(r.participants))
+	for i, p := range r.participants {
+		commitments, dealt, err := p.Deal()
+		require.NoError(r.t, err)
+		shares[i] = dealt
+		for _, q := range r.participants {
+			if q != p {
+				require.NoError(r.t, q.ReceiveCommitments(commitments))
+			}
+		}
+	}
+
+	var complaints []*Complaint
+	for _, dealt := range shares {
+		for _, share := range dealt {
+			if tamper != nil {
+				tamper(share)
+			}
+			complaint, err := r.participants[share.Receiver-1].ReceiveShare(share)
+			require.NoError(r.t, err)
+			if complaint != nil {
+				complaints = append(complaints, complaint)
+			}
+		}
+	}
+	for _, p := range r.participants {
+		missing, err := p.MissingShares()
+		require.NoError(r.t, err)
+		assert.Empty(r.t, missing)
+	}
+
+	for _, complaint := range complaints {
+		r.broadcastComplaint(complaint)
+	}
+	for _, complaint := range complaints {
+		justification, err := r.participants[complaint.Dealer-1].Justify(complaint)
+		require.NoError(r.t, err)
+		r.justify(justification)
+	}
+}
+
+func (r *run) justify(justification *Share) {
+	for _, p := range r.participants {
+		if p.index != justification.Dealer {
+			require.NoError(r.t, p.ReceiveJustification(justification))
+		}
+	}
+}
+
+// extract runs the extraction phase; tamper may modify published coefficients.
+func (r *run) extract(tamper func(publicCoefficients *PublicCoefficients)) []*Result {
+	published := make([]*PublicCoefficients, 0, len(r.participants))
+	for _, p := range r.participants {
+		publicCoefficients, err := p.PublishCoefficients()
+		require.NoError(r.t, err)
+		if publicCoefficients != nil {
+			if tamper != nil {
+				tamper(publicCoefficients)
+			}
+			published = append(published, publicCoefficients)
+		}
+	}
+
+	var complaints []*Complaint
+	for _, publicCoefficients := range published {
+		for _, p := range r.participants {
+			if p.index == publicCoefficients.Dealer {
+				continue
+			}
+			if !p.isQualified(publicCoefficients.Dealer) {
+				// a disqualified dealer may still think it is qualified
+				_, err := p.ReceivePublicCoefficients(publicCoefficients)
+				assert.Error(r.t, err)
+				continue
+			}
+			complaint, err := p.ReceivePublicCoefficients(publicCoefficients)
+			require.NoError(r.t, err)
+			if complaint != nil {
+				complaints = append(complaints, complaint)
+			}
+		}
+	}
+
+	faulty := make(map[int]bool)
+	for _, complaint := range complaints {
+		r.broadcastComplaint(complaint)
+		faulty[complaint.Dealer] = true
+	}
+	for dealer := range faulty {
+		for _, p := range r.participants {
+			if p.index == dealer {
+				continue
+			}
+			share, err := p.RevealShare(dealer)
+			require.NoError(r.t, err)
+			for _, q := range r.participants {
+				if q != p {
+					require.NoError(r.t, q.ReceiveRevealedShare(share))
+				}
+			}
+		}
+	}
+
+	results := make([]*Result, len(r.participants))
+	for i, p := range r.participants {
+		result, err := p.Finalize()
+		require.NoError(r.t, err)
+		results[i] = result
+	}
+	return results
+}
+
+func checkResults(t *testing.T, threshold int, results []*Result, qualified []int) {
+	publicKey := results[0].PublicKey
+	for _, result := range results {
+		assert.Equal(t, publicKey.Hex(testContext.Curve), result.PublicKey.Hex(testContext.Curve))
+		assert.Equal(t, qualified, result.Qualified)
+
+		sharePublicKey, err := result.Share.PublicKey(testContext)
+		require.NoError(t, err)
+		assert.Equal(t, sharePublicKey.Hex(testContext.Curve), result.VerificationKeys[result.Share.Index-1].Hex(testContext.Curve))
+	}
+
+	// any t participants can sign under the group public key
+	signers := make([]int, threshold)
+	for i := range signers {
+		signers[i] = results[len(results)-1-i].Share.Index
+	}
+	mode := testContext.Curve.Params().BitSize / 8
+	nonces := make([][]byte, threshold)
+	publicNonces := make([]*aggsig.PublicKey, threshold)
+	for i := range signers {
+		nonces[i] = utils.RandomBytes(mode)
+		publicNonce, err := aggsig.NewPublicKey(testContext, nonces[i])
+		require.NoError(t, err)
+		publicNonces[i] = publicNonce
+	}
+	sumPublicNonces, err := aggsig.SumPublicKeys(testContext, publicNonces)
+	require.NoError(t, err)
+
+	msg := []byte("Hello world!")
+	partialSignatures := make([][]byte, threshold)
+	for i, index := range signers {
+		partialSignatures[i], err = aggsig.SignPartial(testContext, results[index-1].Share.PrivateKey, nonces[i], sumPublicNonces, msg, aggsig.WithSigners(index, signers))
+		require.NoError(t, err)
+	}
+	signature, err := aggsig.AggregatePartialSignatures(testContext, partialSignatures, sumPublicNonces)
+	require.NoError(t, err)
+
+	correct, err := aggsig.Verify(testContext, signature, publicKey, msg)
+	assert.NoError(t, err)
+	assert.True(t, correct)
+}
+
+func TestDKG(t *testing.T) {
+	r := newRun(t, 3, 5)
+	r.share(nil)
+	results := r.extract(nil)
+	checkResults(t, 3, results, []int{1, 2, 3, 4, 5})
+}
+
+func TestDKGJustifiedComplaint(t *testing.T) {
+	r := newRun(t, 2, 4)
+
+	// dealer 2 sends a bad share to participant 3, and answers the complaint
+	r.share(func(share *Share) {
+		if share.Dealer == 2 && share.Receiver == 3 {
+			share.Value[0] ^= 0x01
+		}
+	})
+	results := r.extract(nil)
+	checkResults(t, 2, results, []int{1, 2, 3, 4})
+}
+
+func TestDKGDisqualifiedDealer(t *testing.T) {
+	r := newRun(t, 2, 4)
+
+	for _, p := range r.participants {
+		_, _, err := p.Deal()
+		require.NoError(t, err)
+	}
+	commitments := make([]*Commitments, len(r.participants))
+	for i, p := range r.participants {
+		commitments[i] = &Commitments{Dealer: p.index, Points: p.commitments[p.index]}
+	}
+	for _, p := range r.participants {
+		for _, c := range commitments {
+			if c.Dealer != p.index {
+				require.NoError(t, p.ReceiveCommitments(c))
+			}
+		}
+	}
+	for _, p := range r.participants {
+		for _, q := range r.participants {
+			// dealer 4 never sends its share to participant 1
+			if p == q || (q.index == 4 && p.index == 1) {
+				continue
+			}
+			_, err := p.ReceiveShare(q.share(p.index))
+			require.NoError(t, err)
+		}
+	}
+
+	complaints, err := r.participants[0].MissingShares()
+	require.NoError(t, err)
+	require.Len(t, complaints, 1)
+	assert.Equal(t, 4, complaints[0].Dealer)
+	r.broadcastComplaint(complaints[0])
+
+	// dealer 4 answers with a share that does not match its commitments
+	justification, err := r.participants[3].Justify(complaints[0])
+	require.NoError(t, err)
+	justification.Value = utils.RandomBytes(len(justification.Value))
+	r.justify(justification)
+
+	// dealer 4 still counts itself in, the honest participants agree without it
+	results := r.extract(nil)
+	checkResults(t, 2, results[:3], []int{1, 2, 3})
+}
+
+func TestDKGReconstruction(t *testing.T) {
+	r := newRun(t, 3, 5)
+	r.share(nil)
+
+	// dealer 5 publishes coefficients that do not match its shares
+	results := r.extract(func(publicCoefficients *PublicCoefficients) {
+		if publicCoefficients.Dealer == 5 {
+			publicCoefficients.Points[1] = new(curve.Point).ScalarBaseMult(testContext.Curve, big.NewInt(5))
+		}
+	})
+	checkResults(t, 3, results, []int{1, 2, 3, 4, 5})
+
+	// the reconstructed contribution is the one dealer 5 committed to
+	sum := new(curve.Point).SetInfinity()
+	for _, p := range r.participants {
+		sum.Add(testContext.Curve, sum, new(curve.Point).ScalarBaseMult(testContext.Curve, p.coefficients[0]))
+	}
+	assert.True(t, sum.Equal(results[0].PublicKey.Point))
+}
+
+func TestDKGUnsolicitedReveal(t *testing.T) {
+	r := newRun(t, 3, 5)
+	r.share(nil)
+
+	// all dealers are honest, so nobody complains
+	published := make([]*PublicCoefficients, len(r.participants))
+	for i, p := range r.participants {
+		publicCoefficients, err := p.PublishCoefficients()
+		require.NoError(t, err)
+		published[i] = publicCoefficients
+	}
+	for i, publicCoefficients := range published {
+		for j, q := range r.participants {
+			if i != j {
+				complaint, err := q.ReceivePublicCoefficients(publicCoefficients)
+				require.NoError(t, err)
+				require.Nil(t, complaint)
+			}
+		}
+	}
+
+	// participant 5 reveals its genuine share of dealer 1 anyway, which must
+	// neither be accepted nor make the others reveal theirs
+	share := r.participants[4].shares[1]
+	for _, p := range r.participants[1:4] {
+		assert.Error(t, p.ReceiveRevealedShare(share))
+		_, err := p.RevealShare(1)
+		assert.Error(t, err)
+	}
+
+	results := make([]*Result, len(r.participants))
+	for i, p := range r.participants {
+		result, err := p.Finalize()
+		require.NoError(t, err)
+		results[i] = result
+	}
+	checkResults(t, 3, results, []int{1, 2, 3, 4, 5})
+}
+
+// smallOrder returns the point of order 2 of the test curve, which has a
+// cofactor of 4.
+func smallOrder(t *testing.T) *curve.Point {
+	c := testContext.Curve.(*curve.CurveParams)
+	x, y := c.FromEdwards(new(big.Int), new(big.Int).Sub(c.P, big.NewInt(1)))
+	point := &curve.Point{X: x, Y: y}
+	require.True(t, point.IsOnCurve(c))
+	require.False(t, point.IsInSubgroup(c))
+	return point
+}
+
+func TestDKGSmallOrderPoints(t *testing.T) {
+	torsion := smallOrder(t)
+	r := newRun(t, 2, 3)
+
+	// commitments with a small-order component are refused
+	commitments, _, err := r.participants[0].Deal()
+	require.NoError(t, err)
+	tampered := &Commitments{Dealer: 1, Points: append([]*curve.Point(nil), commitments.Points...)}
+	tampered.Points[1] = new(curve.Point).Add(testContext.Curve, tampered.Points[1], torsion)
+	require.True(t, tampered.Points[1].IsOnCurve(testContext.Curve))
+	assert.Error(t, r.participants[1].ReceiveCommitments(tampered))
+
+	// and so are coefficients
+	r = newRun(t, 2, 3)
+	r.share(nil)
+	publicCoefficients, err := r.participants[0].PublishCoefficients()
+	require.NoError(t, err)
+	publicCoefficients.Points[1] = new(curve.Point).Add(testContext.Curve, publicCoefficients.Points[1], torsion)
+	_, err = r.participants[1].PublishCoefficients()
+	require.NoError(t, err)
+	_, err = r.participants[1].ReceivePublicCoefficients(publicCoefficients)
+	assert.Error(t, err)
+}
+
+func TestDKGOutOfOrder(t *testing.T) {
+	p, err := NewParticipant(testContext, 1, 2, 3, rand.Reader)
+	require.NoError(t, err)
+
+	_, err = p.PublishCoefficients()
+	assert.Error(t, err)
+	_, err = p.Finalize()
+	assert.Error(t, err)
+	_, _, err = p.Deal()
+	require.NoError(t, err)
+	_, _, err = p.Deal()
+	assert.Error(t, err)
+	_, err = p.Finalize()
+	assert.Error(t, err)
+
+	// a share from a dealer without commitments is refused
+	_, err = p.ReceiveShare(&Share{Dealer: 2, Receiver: 1})
+	assert.Error(t, err)
+
+	_, err = NewParticipant(testContext, 4, 2, 3, rand.Reader)
+	assert.Error(t, err)
+	_, err = NewParticipant(testContext, 1, 4, 3, rand.Reader)
+	assert.Error(t, err)
+}
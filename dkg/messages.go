@@ -0,0 +1,56 @@
+package dkg
+
+import (
+	"github.com/AllFi/go-gost3410/curve"
+)
+
+/*
+Commitments is broadcast by a dealer in the first round. Points are the Pedersen
+commitments C_k = a_k*G + b_k*H to the coefficients of the secret polynomial f and
+of the blinding polynomial f'.
+*/
+type Commitments struct {
+	Dealer int
+	Points []*curve.Point
+}
+
+/*
+Share is sent privately by a dealer to every receiver in the first round. It is
+also broadcast by the dealer to answer a complaint, and by the receiver as
+evidence or to help reconstruct the secret of a faulty dealer.
+*/
+type Share struct {
+	Dealer   int
+	Receiver int
+	// Value is f(Receiver), Blinding is f'(Receiver).
+	Value    []byte
+	Blinding []byte
+}
+
+/*
+Complaint is broadcast by Accuser against Dealer.
+
+During the sharing phase a complaint says that the share from Dealer is missing
+or does not match its Commitments, and Share is nil. The dealer answers with
+Justify.
+
+During the extraction phase a complaint says that the share from Dealer does not
+match its PublicCoefficients, and Share is the offending share, which must match
+the Commitments of Dealer. The secret of Dealer is then reconstructed from the
+shares published with RevealShare.
+*/
+type Complaint struct {
+	Accuser int
+	Dealer  int
+	Share   *Share
+}
+
+/*
+PublicCoefficients is broadcast by a qualified dealer in the extraction phase.
+Points are A_k = a_k*G, and Points[0] is the contribution of the dealer to the
+group public key.
+*/
+type PublicCoefficients struct {
+	Dealer int
+	Points []*curve.Point
+}
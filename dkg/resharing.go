@@ -0,0 +1,353 @@
+package dkg
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+Resharing moves a group private key d, Shamir shared with threshold t among the
+old holders, to a new (t', n') sharing without ever reconstructing d and without
+changing the group public key (Desmedt and Jajodia, Herzberg et al.). Running it
+with the same membership refreshes the shares proactively: old shares become
+useless to an attacker once the new ones are in place.
+
+Every old holder i in a set S of at least t old indices deals its weighted share
+lambda_i*x_i, where lambda_i is the Lagrange coefficient of i over S, with a
+fresh polynomial g_i of degree t' - 1. It broadcasts the Feldman coefficients
+A_ik = g_ik*G and sends g_i(j) privately to every new holder j. The new share of j
+is x'_j = sum(g_i(j)).
+
+The broadcast coefficients are the public proof that the group key is unchanged:
+A_i0 = lambda_i*Y_i, where Y_i is the verification key of the old share of i, so
+sum(A_i0) = sum(lambda_i*Y_i) is the old group public key. See VerifyResharing.
+*/
+
+/*
+ResharedShare is sent privately by an old holder to a new holder.
+*/
+type ResharedShare struct {
+	Dealer   int
+	Receiver int
+	// Value is g_Dealer(Receiver).
+	Value []byte
+}
+
+/*
+ReshareDealer is the role of an old holder in a resharing.
+*/
+type ReshareDealer struct {
+	context      *gost3410.Context
+	index        int
+	n            int
+	coefficients []*big.Int
+	dealt        bool
+}
+
+/*
+NewReshareDealer prepares the old holder of share to reshare to a threshold t
+out of n sharing. dealers are the indices of the old holders taking part,
+including share.Index. The polynomial is drawn from rand.
+*/
+func NewReshareDealer(context *gost3410.Context, share *aggsig.Share, dealers []int, t, n int, rand io.Reader) (dealer *ReshareDealer, err error) {
+	if t < 1 || t > n {
+		err = errors.New("threshold must be between 1 and n")
+		return
+	}
+	privateKey, err := aggsig.NewPrivateKey(context, share.PrivateKey)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPrivateKey")
+		return
+	}
+	lambda, err := aggsig.LagrangeCoefficient(context, share.Index, dealers)
+	if err != nil {
+		err = errors.Wrap(err, "cannot LagrangeCoefficient")
+		return
+	}
+
+	// g(0) = lambda*x
+	q := context.Curve.Params().N
+	coefficients := make([]*big.Int, t)
+	coefficients[0] = lambda.Mul(lambda, privateKey.Int)
+	coefficients[0].Mod(coefficients[0], q)
+	for k := 1; k < t; k++ {
+		if coefficients[k], err = randomScalar(context, rand); err != nil {
+			return nil, errors.Wrap(err, "cannot randomScalar")
+		}
+	}
+
+	return &ReshareDealer{
+		context:      context,
+		index:        share.Index,
+		n:            n,
+		coefficients: coefficients,
+	}, nil
+}
+
+/*
+Deal returns the coefficients to broadcast and one share for every new holder.
+The secret polynomial is erased afterwards, so Deal can only be called once.
+*/
+func (d *ReshareDealer) Deal() (publicCoefficients *PublicCoefficients, shares []*ResharedShare, err error) {
+	if d.dealt {
+		err = errors.New("Deal called twice")
+		return
+	}
+
+	mode := d.context.Curve.Params().BitSize / 8
+	points := make([]*curve.Point, len(d.coefficients))
+	for k := range d.coefficients {
+		points[k] = new(curve.Point).ScalarBaseMultSecret(d.context.Curve, d.coefficients[k])
+	}
+	shares = make([]*ResharedShare, d.n)
+	for j := 1; j <= d.n; j++ {
+		value := aggsig.EvaluatePolynomial(d.context, d.coefficients, big.NewInt(int64(j)))
+		shares[j-1] = &ResharedShare{Dealer: d.index, Receiver: j, Value: utils.Pad(value.Bytes(), mode)}
+	}
+
+	for k := range d.coefficients {
+		d.coefficients[k].SetInt64(0)
+	}
+	d.dealt = true
+	return &PublicCoefficients{Dealer: d.index, Points: points}, shares, nil
+}
+
+/*
+ReshareReceiver is the role of a new holder in a resharing.
+*/
+type ReshareReceiver struct {
+	context             *gost3410.Context
+	index               int
+	t, n                int
+	dealers             []int
+	publicKey           *aggsig.PublicKey
+	oldVerificationKeys []*aggsig.PublicKey
+
+	publicCoefficients map[int]*PublicCoefficients
+	values             map[int]*big.Int
+}
+
+/*
+NewReshareReceiver prepares new holder index of a threshold t out of n sharing
+of the group key publicKey. dealers are the indices of the old holders taking
+part and oldVerificationKeys[i-1] is the verification key of the old share i.
+*/
+func NewReshareReceiver(
+	context *gost3410.Context,
+	index, t, n int,
+	dealers []int,
+	publicKey *aggsig.PublicKey,
+	oldVerificationKeys []*aggsig.PublicKey,
+) (
+	receiver *ReshareReceiver,
+	err error,
+) {
+	if t < 1 || t > n {
+		err = errors.New("threshold must be between 1 and n")
+		return
+	}
+	if index < 1 || index > n {
+		err = errors.New("index must be between 1 and n")
+		return
+	}
+	for _, dealer := range dealers {
+		if dealer < 1 || dealer > len(oldVerificationKeys) {
+			err = errors.Errorf("no verification key for dealer %d", dealer)
+			return
+		}
+	}
+
+	return &ReshareReceiver{
+		context:             context,
+		index:               index,
+		t:                   t,
+		n:                   n,
+		dealers:             append([]int{}, dealers...),
+		publicKey:           publicKey,
+		oldVerificationKeys: oldVerificationKeys,
+		publicCoefficients:  make(map[int]*PublicCoefficients),
+		values:              make(map[int]*big.Int),
+	}, nil
+}
+
+/*
+ReceivePublicCoefficients checks and records the broadcast coefficients of an
+old holder. They are rejected unless they deal exactly the weighted old share of
+the dealer.
+*/
+func (r *ReshareReceiver) ReceivePublicCoefficients(publicCoefficients *PublicCoefficients) error {
+	if _, ok := r.publicCoefficients[publicCoefficients.Dealer]; ok {
+		return errors.Errorf("coefficients of dealer %d are already known", publicCoefficients.Dealer)
+	}
+	if err := checkResharingCoefficients(r.context, r.t, r.dealers, r.oldVerificationKeys, publicCoefficients); err != nil {
+		return err
+	}
+
+	r.publicCoefficients[publicCoefficients.Dealer] = publicCoefficients
+	return nil
+}
+
+/*
+ReceiveShare checks the private share from an old holder against its
+coefficients, which must have been received first. An error means the dealer
+misbehaved; the resharing must then be restarted without it.
+*/
+func (r *ReshareReceiver) ReceiveShare(share *ResharedShare) error {
+	if share.Receiver != r.index {
+		return errors.New("share is addressed to another participant")
+	}
+	publicCoefficients, ok := r.publicCoefficients[share.Dealer]
+	if !ok {
+		return errors.Errorf("coefficients of dealer %d are not known", share.Dealer)
+	}
+	if _, ok := r.values[share.Dealer]; ok {
+		return errors.Errorf("share of dealer %d is already known", share.Dealer)
+	}
+	if len(share.Value) != r.context.Curve.Params().BitSize/8 {
+		return errors.Errorf("share of dealer %d has a wrong length", share.Dealer)
+	}
+
+	// g(j)*G == sum(A_k * j^k)
+	value := utils.BytesToBigInt(share.Value)
+	expected := evaluatePoints(r.context, publicCoefficients.Points, big.NewInt(int64(r.index)))
+	if !expected.Equal(new(curve.Point).ScalarBaseMultSecret(r.context.Curve, value)) {
+		return errors.Errorf("share of dealer %d does not match its coefficients", share.Dealer)
+	}
+
+	r.values[share.Dealer] = value
+	return nil
+}
+
+/*
+Finalize returns the new share once the coefficients and shares of all dealers
+have been received. Result.Qualified lists the dealers.
+*/
+func (r *ReshareReceiver) Finalize() (result *Result, err error) {
+	q := r.context.Curve.Params().N
+	mode := r.context.Curve.Params().BitSize / 8
+
+	value := new(big.Int)
+	for _, dealer := range r.dealers {
+		v, ok := r.values[dealer]
+		if !ok {
+			err = errors.Errorf("share of dealer %d is missing", dealer)
+			return
+		}
+		value.Add(value, v)
+	}
+	value.Mod(value, q)
+	if value.Cmp(big.NewInt(0)) == 0 {
+		err = errors.New("new share is zero")
+		return
+	}
+
+	publicCoefficients := make([]*PublicCoefficients, 0, len(r.dealers))
+	for _, dealer := range r.dealers {
+		publicCoefficients = append(publicCoefficients, r.publicCoefficients[dealer])
+	}
+	verificationKeys, err := VerifyResharing(r.context, r.publicKey, r.t, r.n, r.dealers, r.oldVerificationKeys, publicCoefficients)
+	if err != nil {
+		err = errors.Wrap(err, "cannot VerifyResharing")
+		return
+	}
+
+	return &Result{
+		Share:            &aggsig.Share{Index: r.index, PrivateKey: utils.Pad(value.Bytes(), mode)},
+		PublicKey:        r.publicKey,
+		VerificationKeys: verificationKeys,
+		Qualified:        append([]int{}, r.dealers...),
+	}, nil
+}
+
+/*
+VerifyResharing is the public check that a resharing to a threshold t out of n
+sharing kept the group key publicKey. publicCoefficients are the broadcasts of
+the old holders dealers, in any order, and oldVerificationKeys[i-1] is the
+verification key of the old share i. It returns the verification keys of the new
+shares.
+*/
+func VerifyResharing(
+	context *gost3410.Context,
+	publicKey *aggsig.PublicKey,
+	t, n int,
+	dealers []int,
+	oldVerificationKeys []*aggsig.PublicKey,
+	publicCoefficients []*PublicCoefficients,
+) (
+	verificationKeys []*aggsig.PublicKey,
+	err error,
+) {
+	if len(publicCoefficients) != len(dealers) {
+		err = errors.New("coefficients of every dealer are required")
+		return
+	}
+
+	seen := make(map[int]bool, len(dealers))
+	sum := make([]*curve.Point, t)
+	for k := range sum {
+		sum[k] = new(curve.Point).SetInfinity()
+	}
+	for _, c := range publicCoefficients {
+		if seen[c.Dealer] {
+			return nil, errors.Errorf("duplicate coefficients of dealer %d", c.Dealer)
+		}
+		seen[c.Dealer] = true
+		if err = checkResharingCoefficients(context, t, dealers, oldVerificationKeys, c); err != nil {
+			return
+		}
+		for k := range sum {
+			sum[k].Add(context.Curve, sum[k], c.Points[k])
+		}
+	}
+
+	// sum(A_i0) = sum(lambda_i*Y_i) must be the group public key
+	if !sum[0].Equal(publicKey.Point) {
+		err = errors.New("resharing changes the group public key")
+		return
+	}
+
+	verificationKeys = make([]*aggsig.PublicKey, n)
+	for j := 1; j <= n; j++ {
+		verificationKeys[j-1] = &aggsig.PublicKey{Point: evaluatePoints(context, sum, big.NewInt(int64(j)))}
+	}
+	return
+}
+
+// checkResharingCoefficients checks that publicCoefficients has the degree of
+// the new sharing and deals lambda_i*Y_i.
+func checkResharingCoefficients(
+	context *gost3410.Context,
+	t int,
+	dealers []int,
+	oldVerificationKeys []*aggsig.PublicKey,
+	publicCoefficients *PublicCoefficients,
+) error {
+	dealer := publicCoefficients.Dealer
+	if len(publicCoefficients.Points) != t {
+		return errors.Errorf("dealer %d sent %d coefficients, want %d", dealer, len(publicCoefficients.Points), t)
+	}
+	for _, point := range publicCoefficients.Points {
+		if !validPoint(context, point) {
+			return errors.Errorf("invalid coefficient of dealer %d", dealer)
+		}
+	}
+
+	lambda, err := aggsig.LagrangeCoefficient(context, dealer, dealers)
+	if err != nil {
+		return errors.Wrap(err, "cannot LagrangeCoefficient")
+	}
+	if dealer > len(oldVerificationKeys) {
+		return errors.Errorf("no verification key for dealer %d", dealer)
+	}
+	expected := new(curve.Point).ScalarMult(context.Curve, oldVerificationKeys[dealer-1].Point, lambda)
+	if !expected.Equal(publicCoefficients.Points[0]) {
+		return errors.Errorf("dealer %d does not deal its old share", dealer)
+	}
+	return nil
+}
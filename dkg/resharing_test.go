@@ -0,0 +1,165 @@
+package dkg
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// splitKey shares a random key with a trusted dealer and returns the shares as
+// results, so that they look like the outcome of a DKG run.
+func splitKey(t *testing.T, threshold, n int) []*Result {
+	mode := testContext.Curve.Params().BitSize / 8
+	privateKey := utils.RandomBytes(mode)
+	publicKey, err := aggsig.NewPublicKey(testContext, privateKey)
+	require.NoError(t, err)
+	shares, err := aggsig.SplitPrivateKey(testContext, privateKey, threshold, n, rand.Reader)
+	require.NoError(t, err)
+
+	verificationKeys := make([]*aggsig.PublicKey, n)
+	for i, share := range shares {
+		verificationKeys[i], err = share.PublicKey(testContext)
+		require.NoError(t, err)
+	}
+	results := make([]*Result, n)
+	for i, share := range shares {
+		results[i] = &Result{Share: share, PublicKey: publicKey, VerificationKeys: verificationKeys}
+	}
+	return results
+}
+
+func reshare(t *testing.T, old []*Result, dealers []int, threshold, n int) ([]*Result, []*PublicCoefficients) {
+	publicKey := old[0].PublicKey
+	oldVerificationKeys := old[0].VerificationKeys
+
+	receivers := make([]*ReshareReceiver, n)
+	for j := 1; j <= n; j++ {
+		receiver, err := NewReshareReceiver(testContext, j, threshold, n, dealers, publicKey, oldVerificationKeys)
+		require.NoError(t, err)
+		receivers[j-1] = receiver
+	}
+
+	var published []*PublicCoefficients
+	for _, i := range dealers {
+		dealer, err := NewReshareDealer(testContext, old[i-1].Share, dealers, threshold, n, rand.Reader)
+		require.NoError(t, err)
+		publicCoefficients, shares, err := dealer.Deal()
+		require.NoError(t, err)
+		published = append(published, publicCoefficients)
+
+		for _, receiver := range receivers {
+			require.NoError(t, receiver.ReceivePublicCoefficients(publicCoefficients))
+		}
+		for _, share := range shares {
+			require.NoError(t, receivers[share.Receiver-1].ReceiveShare(share))
+		}
+	}
+
+	results := make([]*Result, n)
+	for j, receiver := range receivers {
+		result, err := receiver.Finalize()
+		require.NoError(t, err)
+		results[j] = result
+	}
+	return results, published
+}
+
+func TestResharing(t *testing.T) {
+	old := splitKey(t, 2, 3)
+
+	// 2-of-3 becomes 3-of-5
+	results, published := reshare(t, old, []int{1, 3}, 3, 5)
+	checkResults(t, 3, results, []int{1, 3})
+	assert.Equal(t, old[0].PublicKey.Hex(testContext.Curve), results[0].PublicKey.Hex(testContext.Curve))
+
+	// the broadcasts prove to anybody that the group key is unchanged
+	verificationKeys, err := VerifyResharing(testContext, old[0].PublicKey, 3, 5, []int{1, 3}, old[0].VerificationKeys, published)
+	require.NoError(t, err)
+	for j, result := range results {
+		assert.Equal(t, result.VerificationKeys[j].Hex(testContext.Curve), verificationKeys[j].Hex(testContext.Curve))
+	}
+
+	other := splitKey(t, 2, 3)
+	_, err = VerifyResharing(testContext, other[0].PublicKey, 3, 5, []int{1, 3}, old[0].VerificationKeys, published)
+	assert.Error(t, err)
+	_, err = VerifyResharing(testContext, old[0].PublicKey, 3, 5, []int{1, 3}, old[0].VerificationKeys, published[:1])
+	assert.Error(t, err)
+}
+
+func TestRefresh(t *testing.T) {
+	r := newRun(t, 2, 3)
+	r.share(nil)
+	old := r.extract(nil)
+
+	results, _ := reshare(t, old, []int{1, 2, 3}, 2, 3)
+	checkResults(t, 2, results, []int{1, 2, 3})
+	assert.Equal(t, old[0].PublicKey.Hex(testContext.Curve), results[0].PublicKey.Hex(testContext.Curve))
+
+	// the shares changed, and old shares do not combine with new ones
+	for i := range results {
+		assert.NotEqual(t, old[i].Share.PrivateKey, results[i].Share.PrivateKey)
+	}
+	mixed, err := aggsig.InterpolatePublicKey(testContext, []int{1, 2}, []*aggsig.PublicKey{old[0].VerificationKeys[0], results[0].VerificationKeys[1]})
+	require.NoError(t, err)
+	assert.NotEqual(t, old[0].PublicKey.Hex(testContext.Curve), mixed.Hex(testContext.Curve))
+}
+
+func TestResharingCheatingDealer(t *testing.T) {
+	old := splitKey(t, 2, 3)
+	dealers := []int{1, 2}
+
+	receiver, err := NewReshareReceiver(testContext, 1, 2, 3, dealers, old[0].PublicKey, old[0].VerificationKeys)
+	require.NoError(t, err)
+
+	// a dealer that does not deal its own share is rejected
+	forged := &aggsig.Share{Index: 1, PrivateKey: utils.RandomBytes(len(old[0].Share.PrivateKey))}
+	dealer, err := NewReshareDealer(testContext, forged, dealers, 2, 3, rand.Reader)
+	require.NoError(t, err)
+	publicCoefficients, _, err := dealer.Deal()
+	require.NoError(t, err)
+	assert.Error(t, receiver.ReceivePublicCoefficients(publicCoefficients))
+
+	// a share that does not match the coefficients is rejected
+	dealer, err = NewReshareDealer(testContext, old[1].Share, dealers, 2, 3, rand.Reader)
+	require.NoError(t, err)
+	publicCoefficients, shares, err := dealer.Deal()
+	require.NoError(t, err)
+	require.NoError(t, receiver.ReceivePublicCoefficients(publicCoefficients))
+	shares[0].Value = utils.Pad(new(big.Int).Add(utils.BytesToBigInt(shares[0].Value), big.NewInt(1)).Bytes(), len(shares[0].Value))
+	assert.Error(t, receiver.ReceiveShare(shares[0]))
+
+	_, _, err = dealer.Deal()
+	assert.Error(t, err)
+	_, err = receiver.Finalize()
+	assert.Error(t, err)
+
+	// a tampered degree is rejected as well
+	publicCoefficients.Points = append(publicCoefficients.Points, new(curve.Point).ScalarBaseMult(testContext.Curve, big.NewInt(1)))
+	_, err = VerifyResharing(testContext, old[0].PublicKey, 2, 3, dealers, old[0].VerificationKeys, []*PublicCoefficients{publicCoefficients})
+	assert.Error(t, err)
+}
+
+func TestResharingSmallOrderCoefficient(t *testing.T) {
+	old := splitKey(t, 2, 3)
+	dealers := []int{1, 2}
+
+	receiver, err := NewReshareReceiver(testContext, 1, 2, 3, dealers, old[0].PublicKey, old[0].VerificationKeys)
+	require.NoError(t, err)
+	dealer, err := NewReshareDealer(testContext, old[0].Share, dealers, 2, 3, rand.Reader)
+	require.NoError(t, err)
+	publicCoefficients, _, err := dealer.Deal()
+	require.NoError(t, err)
+
+	// a small-order component in a higher coefficient leaves the dealt share
+	// untouched but must still be refused
+	publicCoefficients.Points[1] = new(curve.Point).Add(testContext.Curve, publicCoefficients.Points[1], smallOrder(t))
+	assert.Error(t, receiver.ReceivePublicCoefficients(publicCoefficients))
+	_, err = VerifyResharing(testContext, old[0].PublicKey, 2, 3, dealers, old[0].VerificationKeys, []*PublicCoefficients{publicCoefficients})
+	assert.Error(t, err)
+}
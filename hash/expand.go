@@ -0,0 +1,84 @@
+package hash
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/pkg/errors"
+)
+
+/*
+ExpandMessageXMD is expand_message_xmd of RFC 9380 over the given hash
+algorithm: it returns length uniformly random bytes derived from msg and the
+domain separation tag dst. A dst longer than 255 bytes is hashed first as the
+RFC requires. It returns an error for an empty dst or if more than 255 hash
+blocks of output are requested.
+*/
+func ExpandMessageXMD(ha gost3410.HashAlgorithm, msg, dst []byte, length int) ([]byte, error) {
+	if len(dst) == 0 {
+		return nil, errors.New("empty domain separation tag")
+	}
+	h := ha.New()
+	if len(dst) > 255 {
+		h.Write([]byte("H2C-OVERSIZE-DST-"))
+		h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+	size := h.Size()
+	ell := (length + size - 1) / size
+	if length < 0 || ell > 255 || length > 65535 {
+		return nil, errors.New("invalid expand_message_xmd output length")
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	// b_0 = H(Z_pad || msg || I2OSP(length, 2) || 0x00 || DST_prime)
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime), b_1 = H(b_0 || 1 || DST_prime)
+	uniform := make([]byte, 0, ell*size)
+	block := make([]byte, size)
+	for i := 1; i <= ell; i++ {
+		for j := range block {
+			block[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(block)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		block = h.Sum(nil)
+		uniform = append(uniform, block...)
+	}
+	return uniform[:length], nil
+}
+
+/*
+HashToScalar hashes the parts under the domain separation tag dst to a scalar of
+[0, N), N being the order of the curve of the context. Each part is preceded by
+its length as 8 bytes big-endian, so that two different lists of parts never
+hash the same. Like hash_to_field of RFC 9380, expand_message_xmd stretches them
+to 1.5 times the bit length of N before the reduction, which makes its bias
+negligible. Unlike HashToInt it does not replace zero by one: zero is a
+possible, if negligibly likely, result.
+*/
+func HashToScalar(context *gost3410.Context, dst []byte, parts ...[]byte) (*big.Int, error) {
+	N := context.Curve.Params().N
+	var msg []byte
+	for _, part := range parts {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(part)))
+		msg = append(append(msg, length[:]...), part...)
+	}
+
+	uniform, err := ExpandMessageXMD(context.HashAlgorithm, msg, dst, (N.BitLen()+N.BitLen()/2+7)/8)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot ExpandMessageXMD")
+	}
+	e := new(big.Int).SetBytes(uniform)
+	return e.Mod(e, N), nil
+}
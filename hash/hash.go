@@ -9,9 +9,11 @@ import (
 	"github.com/AllFi/go-gost3410"
 	"github.com/AllFi/go-gost3410/utils"
 	"github.com/martinlindhe/gogost/gost34112012256"
+	"github.com/martinlindhe/gogost/gost34112012512"
 )
 
 var GOST34112012256 = &gost34112012256Alg{}
+var GOST34112012512 = &gost34112012512Alg{}
 var SHA256 = &sha256Alg{}
 
 type gost34112012256Alg struct{}
@@ -20,12 +22,22 @@ func (h *gost34112012256Alg) New() hash.Hash {
 	return gost34112012256.New()
 }
 
+type gost34112012512Alg struct{}
+
+func (h *gost34112012512Alg) New() hash.Hash {
+	return gost34112012512.New()
+}
+
 type sha256Alg struct{}
 
 func (h *sha256Alg) New() hash.Hash {
 	return sha256.New()
 }
 
+/*
+HashToInt computes e = H(msg) mod N of GOST R 34.10, with e = 1 for a zero
+remainder. It is kept for the signatures, challenges use HashToScalar.
+*/
 func HashToInt(msg []byte, ha gost3410.HashAlgorithm, ec elliptic.Curve) *big.Int {
 	h := ha.New()
 	h.Write(msg)
@@ -0,0 +1,93 @@
+package hash
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Example M1 from GOST R 34.11-2012.
+var m1 = []byte("012345678901234567890123456789012345678901234567890123456789012")
+
+func TestGOST34112012(t *testing.T) {
+	h := GOST34112012256.New()
+	h.Write(m1)
+	assert.Equal(t, "9d151eefd8590b89daa6ba6cb74af9275dd051026bb149a452fd84e5e57b5500", hex.EncodeToString(h.Sum(nil)))
+
+	h = GOST34112012512.New()
+	h.Write(m1)
+	assert.Equal(t, "1b54d01a4af5b9d5cc3d86d68d285462b19abc2475222f35c085122be4ba1ffa00ad30f8767b3a82384c6574f024c311e2a481332b08ef7f41797891c1646f48", hex.EncodeToString(h.Sum(nil)))
+}
+
+// Test vectors from R 50.1.113-2016.
+var (
+	kdfKey   = unhex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	kdfLabel = unhex("26bdb878")
+	kdfSeed  = unhex("af21434145656378")
+	kdfData  = unhex("0126bdb87800af214341456563780100")
+)
+
+func TestHMACGOST34112012(t *testing.T) {
+	assert.Equal(t,
+		"a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9",
+		hex.EncodeToString(MAC(HMACGOST34112012256, kdfKey, kdfData)),
+	)
+	assert.Equal(t,
+		"a59bab22ecae19c65fbde6e5f4e9f5d8549d31f037f9df9b905500e171923a773d5f1530f2ed7e964cb2eedc29e9ad2f3afe93b2814f79f5000ffc0366c251e6",
+		hex.EncodeToString(MAC(HMACGOST34112012512, kdfKey, kdfData)),
+	)
+}
+
+func TestKDFGOST34112012256(t *testing.T) {
+	assert.Equal(t,
+		"a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9",
+		hex.EncodeToString(KDFGOST34112012256(kdfKey, kdfLabel, kdfSeed)),
+	)
+}
+
+// Test case 1 from RFC 5869.
+func TestHKDF(t *testing.T) {
+	ikm := unhex("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt := unhex("000102030405060708090a0b0c")
+	info := unhex("f0f1f2f3f4f5f6f7f8f9")
+
+	prk := HKDFExtract(SHA256, ikm, salt)
+	assert.Equal(t, "077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5", hex.EncodeToString(prk))
+
+	okm, err := HKDF(SHA256, ikm, salt, info, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(okm))
+
+	okm, err = HKDF(GOST34112012512, ikm, salt, info, 100)
+	assert.NoError(t, err)
+	assert.Len(t, okm, 100)
+
+	_, err = HKDF(GOST34112012256, ikm, salt, info, 255*32+1)
+	assert.Error(t, err)
+}
+
+// RFC 9380, appendix K.1, and the limits of expand_message_xmd.
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	uniform, err := ExpandMessageXMD(SHA256, []byte("abc"), dst, 0x20)
+	assert.NoError(t, err)
+	assert.Equal(t, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615", hex.EncodeToString(uniform))
+
+	uniform, err = ExpandMessageXMD(GOST34112012512, []byte("abc"), dst, 255*64)
+	assert.NoError(t, err)
+	assert.Len(t, uniform, 255*64)
+
+	_, err = ExpandMessageXMD(GOST34112012256, []byte("abc"), dst, 255*32+1)
+	assert.Error(t, err)
+	_, err = ExpandMessageXMD(GOST34112012256, []byte("abc"), nil, 32)
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"hash"
+
+	"github.com/AllFi/go-gost3410"
+)
+
+// HMAC-Streebog as defined in R 50.1.113-2016.
+var HMACGOST34112012256 = NewHMAC(GOST34112012256)
+var HMACGOST34112012512 = NewHMAC(GOST34112012512)
+
+type hmacAlg struct {
+	ha gost3410.HashAlgorithm
+}
+
+/*
+NewHMAC returns the HMAC construction over the given hash algorithm.
+*/
+func NewHMAC(ha gost3410.HashAlgorithm) gost3410.MACAlgorithm {
+	return &hmacAlg{ha: ha}
+}
+
+func (h *hmacAlg) New(key []byte) hash.Hash {
+	return hmac.New(h.ha.New, key)
+}
+
+/*
+MAC computes the MAC of msg under key in one call.
+*/
+func MAC(ma gost3410.MACAlgorithm, key, msg []byte) []byte {
+	h := ma.New(key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
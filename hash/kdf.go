@@ -0,0 +1,66 @@
+package hash
+
+import (
+	"github.com/AllFi/go-gost3410"
+	"github.com/pkg/errors"
+)
+
+/*
+KDFGOST34112012256 is KDF_GOSTR3411_2012_256 from R 50.1.113-2016:
+
+	KDF(K, label, seed) = HMAC_GOSTR3411_2012_256(K, 0x01 | label | 0x00 | seed | 0x01 | 0x00)
+
+It returns 32 bytes of key material.
+*/
+func KDFGOST34112012256(key, label, seed []byte) []byte {
+	msg := make([]byte, 0, len(label)+len(seed)+4)
+	msg = append(msg, 0x01)
+	msg = append(msg, label...)
+	msg = append(msg, 0x00)
+	msg = append(msg, seed...)
+	msg = append(msg, 0x01, 0x00)
+	return MAC(HMACGOST34112012256, key, msg)
+}
+
+/*
+HKDFExtract is the extract step of HKDF (RFC 5869) over the given hash algorithm.
+An empty salt is replaced by a string of zeros of the hash length.
+*/
+func HKDFExtract(ha gost3410.HashAlgorithm, secret, salt []byte) []byte {
+	if len(salt) == 0 {
+		salt = make([]byte, ha.New().Size())
+	}
+	return MAC(NewHMAC(ha), salt, secret)
+}
+
+/*
+HKDFExpand is the expand step of HKDF (RFC 5869) over the given hash algorithm.
+It returns an error if more than 255 hash blocks of output are requested.
+*/
+func HKDFExpand(ha gost3410.HashAlgorithm, prk, info []byte, length int) ([]byte, error) {
+	mac := NewHMAC(ha)
+	size := ha.New().Size()
+	if length < 0 || length > 255*size {
+		return nil, errors.New("invalid HKDF output length")
+	}
+
+	okm := make([]byte, 0, length+size)
+	var block []byte
+	for i := 1; len(okm) < length; i++ {
+		h := mac.New(prk)
+		h.Write(block)
+		h.Write(info)
+		h.Write([]byte{byte(i)})
+		block = h.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length], nil
+}
+
+/*
+HKDF derives length bytes of key material from secret (RFC 5869). With the
+Streebog hash algorithms it gives a standard KDF for blinds and nonces.
+*/
+func HKDF(ha gost3410.HashAlgorithm, secret, salt, info []byte, length int) ([]byte, error) {
+	return HKDFExpand(ha, HKDFExtract(ha, secret, salt), info, length)
+}
@@ -18,28 +18,50 @@ func NewCommitment(context *gost3410.Context, value uint64, blind []byte, h *cur
 	v := new(big.Int).SetUint64(value)
 	b := new(big.Int).SetBytes(blind)
 
-	x1, y1 := c.ScalarMult(h.X, h.Y, v.Bytes())
-	x2, y2 := c.ScalarMult(g.X, g.Y, b.Bytes())
-	x, y := c.Add(x1, y1, x2, y2)
-	return &Commitment{&curve.Point{x, y}}
+	// both the value and the blind are secret
+	point := new(curve.Point).Add(c, scalarMult(context, h.Point, v), scalarMult(context, g.Point, b))
+	return &Commitment{point}
 }
 
+// scalarMult returns k*p in constant time, using the cached tables of G and
+// GeneratorH for these points.
+func scalarMult(context *gost3410.Context, p *curve.Point, k *big.Int) *curve.Point {
+	for _, table := range []*curve.FixedBase{curve.FixedBaseG(context), curve.FixedBaseH(context)} {
+		if table.Point().Equal(p) {
+			return table.ScalarMultSecret(k)
+		}
+	}
+	return new(curve.Point).ScalarMultSecret(context.Curve, p, k)
+}
+
+/*
+CommitSum returns the sum of the positive commitments minus the negative ones,
+which may be the point at infinity.
+*/
 func CommitSum(context *gost3410.Context, positive []*Commitment, negative []*Commitment) (commit *Commitment) {
 	c := context.Curve
-	x, y := new(big.Int), new(big.Int)
+	point := new(curve.Point).SetInfinity()
 
 	for _, commit := range positive {
-		x, y = c.Add(x, y, commit.X, commit.Y)
+		point.Add(c, point, commit.Point)
 	}
 
 	for _, commit := range negative {
-		x, y = c.Add(x, y, commit.X, new(big.Int).Neg(commit.Y))
+		point.Sub(c, point, commit.Point)
 	}
-	return &Commitment{&curve.Point{x, y}}
+	return &Commitment{point}
 }
 
+/*
+CommitFromString decodes a commitment written by String or any other encoding of
+curve.PointFromBytes. Unlike curve.PointFromHex it accepts the point at
+infinity, which String writes for a balanced CommitSum.
+*/
 func CommitFromString(context *gost3410.Context, s string) (c *Commitment, err error) {
 	p, err := curve.PointFromHex(context.Curve, s)
+	if errors.Cause(err) == curve.ErrIdentity {
+		return &Commitment{new(curve.Point).SetInfinity()}, nil
+	}
 	if err != nil {
 		err = errors.Wrap(err, "cannot PointFromHex")
 		return
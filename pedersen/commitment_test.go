@@ -1 +1,81 @@
 package pedersen
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCommitment(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	ec := context.Curve
+	blind := utils.RandomBytes(32)
+	other := curve.NewGenerator(context, []byte("other"))
+
+	for _, h := range []*curve.Generator{curve.GeneratorH(context), other} {
+		commitment := NewCommitment(context, 42, blind, h, curve.GeneratorG(context))
+
+		// 42*h + blind*G
+		expected := new(curve.Point).Add(ec,
+			new(curve.Point).ScalarMult(ec, h.Point, big.NewInt(42)),
+			new(curve.Point).ScalarBaseMult(ec, new(big.Int).SetBytes(blind)),
+		)
+		assert.Equal(t, expected.X.String(), commitment.X.String())
+		assert.Equal(t, expected.Y.String(), commitment.Y.String())
+	}
+}
+
+func TestCommitFromString(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	commitment := NewCommitment(context, 7, utils.RandomBytes(32), curve.GeneratorH(context), curve.GeneratorG(context))
+
+	decoded, err := CommitFromString(context, commitment.String(context))
+	assert.NoError(t, err)
+	assert.Equal(t, commitment.String(context), decoded.String(context))
+
+	compressed := hex.EncodeToString(commitment.BytesCompressed(context.Curve))
+	decoded, err = CommitFromString(context, compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, commitment.String(context), decoded.String(context))
+
+	// off the curve
+	tampered := &curve.Point{X: commitment.X, Y: new(big.Int).Add(commitment.Y, big.NewInt(1))}
+	_, err = CommitFromString(context, tampered.Hex(context.Curve))
+	assert.True(t, errors.Is(err, curve.ErrNotOnCurve), "%v", err)
+}
+
+func TestCommitSum(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	h, g := curve.GeneratorH(context), curve.GeneratorG(context)
+	blind := func(b int64) []byte {
+		return big.NewInt(b).Bytes()
+	}
+
+	// (5, 3) + (7, 4) - (2, 1) = (10, 6)
+	a := NewCommitment(context, 5, blind(3), h, g)
+	b := NewCommitment(context, 7, blind(4), h, g)
+	c := NewCommitment(context, 2, blind(1), h, g)
+	sum := CommitSum(context, []*Commitment{a, b}, []*Commitment{c})
+	assert.True(t, sum.Equal(NewCommitment(context, 10, blind(6), h, g).Point))
+
+	// balanced sums are the point at infinity
+	assert.True(t, CommitSum(context, []*Commitment{a, b}, []*Commitment{b, a}).IsZero())
+	assert.True(t, CommitSum(context, nil, nil).IsZero())
+	assert.True(t, CommitSum(context, nil, []*Commitment{a}).Equal(new(curve.Point).Neg(context.Curve, a.Point)))
+
+	// and survive a round trip through String and CommitFromString
+	zero := CommitSum(context, []*Commitment{a}, []*Commitment{a})
+	for _, s := range []string{zero.String(context), hex.EncodeToString(zero.BytesCompressed(context.Curve))} {
+		decoded, err := CommitFromString(context, s)
+		assert.NoError(t, err)
+		assert.True(t, decoded.IsZero())
+		assert.Equal(t, zero.String(context), decoded.String(context))
+	}
+}
@@ -0,0 +1,201 @@
+package sign
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/utils"
+	"github.com/pkg/errors"
+)
+
+var (
+	zero = big.NewInt(0)
+)
+
+// Order defines how the r and s halves are laid out in a signature.
+type Order int
+
+const (
+	// OrderSR is s || r, the layout of GOST R 34.10-2012, gogost and OpenSSL-gost.
+	OrderSR Order = iota
+	// OrderRS is r || s.
+	OrderRS
+)
+
+/*
+Options controls the encoding of digests and signatures. The zero value matches
+the test vectors of GOST R 34.10-2012: the digest is a big-endian integer and the
+signature is s || r.
+*/
+type Options struct {
+	Order Order
+	// LittleEndianDigest interprets the digest as a little-endian integer. Set it
+	// to interoperate with implementations (OpenSSL-gost, CryptoPro, newer
+	// gogost) that reverse the Streebog output before signing.
+	LittleEndianDigest bool
+}
+
+/*
+Sign produces a single-signer GOST R 34.10-2012 signature of digest. The nonce k
+is read from rand, drawing again until it is in [1, q) so that it is uniform. With a nil rand it is derived from the private key and digest
+as in RFC 6979 with aggsig.DeterministicNonce, so the same key and digest always
+give the same signature.
+*/
+func Sign(context *gost3410.Context, rawPrivateKey []byte, digest []byte, rand io.Reader, opts *Options) (signature []byte, err error) {
+	privateKey, err := aggsig.NewPrivateKey(context, rawPrivateKey)
+	if err != nil {
+		err = errors.Wrap(err, "cannot NewPrivateKey")
+		return
+	}
+
+	q := context.Curve.Params().N
+	mode := context.Curve.Params().BitSize / 8
+	d := new(big.Int).Mod(privateKey.Int, q)
+	if d.Sign() == 0 {
+		err = errors.New("private key is zero mod q")
+		return
+	}
+	e := digestToInt(context, digest, opts)
+
+	if rand == nil {
+		// RFC 6979 takes the digest as a big-endian integer, like e
+		if opts != nil && opts.LittleEndianDigest {
+			digest = reverse(digest)
+		}
+		var kRaw []byte
+		if kRaw, err = aggsig.DeterministicNonce(context, rawPrivateKey, digest, nil); err != nil {
+			err = errors.Wrap(err, "cannot DeterministicNonce")
+			return
+		}
+		r, s, ok := sign(context, d, e, utils.BytesToBigInt(kRaw))
+		if !ok {
+			err = errors.New("deterministic nonce gives a zero r or s")
+			return
+		}
+		return encode(context, r, s, opts), nil
+	}
+
+	// k is drawn again until it is below q rather than reduced mod q, which
+	// would bias it where q is much smaller than 2^(8*mode), e.g. CryptoPro-C
+	kRaw := make([]byte, mode)
+	for {
+		if _, err = io.ReadFull(rand, kRaw); err != nil {
+			err = errors.Wrap(err, "cannot read nonce")
+			return
+		}
+		k := utils.BytesToBigInt(kRaw)
+		if k.Cmp(zero) == 0 || k.Cmp(q) >= 0 {
+			continue
+		}
+		if r, s, ok := sign(context, d, e, k); ok {
+			return encode(context, r, s, opts), nil
+		}
+	}
+}
+
+// sign computes r and s with the nonce k, ok is false if either of them is zero.
+func sign(context *gost3410.Context, d, e, k *big.Int) (r, s *big.Int, ok bool) {
+	q := context.Curve.Params().N
+
+	// r = x(k*P) mod q
+	C := new(curve.Point).ScalarBaseMultSecret(context.Curve, k)
+	r = new(big.Int).Mod(C.X, q)
+	if r.Cmp(zero) == 0 {
+		return
+	}
+
+	// s = r*d + k*e mod q
+	s = new(big.Int).Mul(r, d)
+	s.Add(s, new(big.Int).Mul(k, e))
+	s.Mod(s, q)
+	if s.Cmp(zero) == 0 {
+		return
+	}
+	return r, s, true
+}
+
+/*
+Verify checks a single-signer GOST R 34.10-2012 signature of digest.
+*/
+func Verify(context *gost3410.Context, publicKey *aggsig.PublicKey, digest []byte, signature []byte, opts *Options) (correct bool, err error) {
+	mode := context.Curve.Params().BitSize / 8
+	q := context.Curve.Params().N
+
+	if len(signature) != 2*mode {
+		err = errors.New("wrong signature length")
+		return
+	}
+
+	// r > 0, r < q, s > 0, s < q
+	r, s := decode(context, signature, opts)
+	if r.Cmp(zero) <= 0 || r.Cmp(q) >= 0 || s.Cmp(zero) <= 0 || s.Cmp(q) >= 0 {
+		return false, nil
+	}
+
+	e := digestToInt(context, digest, opts)
+	v := new(big.Int).ModInverse(e, q)
+
+	// z1 = s * v mod q
+	z1 := new(big.Int).Mul(s, v)
+	z1.Mod(z1, q)
+
+	// z2 = -( r * v ) mod q
+	z2 := new(big.Int).Mul(r, v)
+	z2.Sub(q, z2.Mod(z2, q))
+
+	// C = z1 * P + z2 * Q
+	C := new(curve.Point).ScalarBaseMult(context.Curve, z1)
+	C.Add(context.Curve, C, new(curve.Point).ScalarMult(context.Curve, publicKey.Point, z2))
+	if C.IsZero() {
+		return false, nil
+	}
+
+	// R must be equal to r
+	R := new(big.Int).Mod(C.X, q)
+	return R.Cmp(r) == 0, nil
+}
+
+func digestToInt(context *gost3410.Context, digest []byte, opts *Options) *big.Int {
+	if opts != nil && opts.LittleEndianDigest {
+		digest = reverse(digest)
+	}
+	e := utils.BytesToBigInt(digest)
+	e.Mod(e, context.Curve.Params().N)
+	if e.Cmp(zero) == 0 {
+		e.SetInt64(1)
+	}
+	return e
+}
+
+func encode(context *gost3410.Context, r, s *big.Int, opts *Options) []byte {
+	mode := context.Curve.Params().BitSize / 8
+	first, second := s, r
+	if opts != nil && opts.Order == OrderRS {
+		first, second = r, s
+	}
+	return append(
+		utils.Pad(first.Bytes(), mode),
+		utils.Pad(second.Bytes(), mode)...,
+	)
+}
+
+func decode(context *gost3410.Context, signature []byte, opts *Options) (r, s *big.Int) {
+	mode := context.Curve.Params().BitSize / 8
+	first := utils.BytesToBigInt(signature[:mode])
+	second := utils.BytesToBigInt(signature[mode:])
+	if opts != nil && opts.Order == OrderRS {
+		return first, second
+	}
+	return second, first
+}
+
+func reverse(d []byte) []byte {
+	result := make([]byte, len(d))
+	for i := range d {
+		result[i] = d[len(d)-1-i]
+	}
+	return result
+}
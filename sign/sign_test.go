@@ -0,0 +1,197 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+	gogost "github.com/martinlindhe/gogost/gost3410"
+	"github.com/stretchr/testify/assert"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Examples 1 and 2 from appendix A of GOST R 34.10-2012.
+var vectors = []struct {
+	curve  *curve.CurveParams
+	d      string
+	qx, qy string
+	e      string
+	k      string
+	r, s   string
+}{
+	{
+		curve: curve.GOST34102001Test,
+		d:     "7a929ade789bb9be10ed359dd39a72c11b60961f49397eee1d19ce9891ec3b28",
+		qx:    "7f2b49e270db6d90d8595bec458b50c58585ba1d4e9b788f6689dbd8e56fd80b",
+		qy:    "26f1b489d6701dd185c8413a977b3cbbaf64d1c593d26627dffb101a87ff77da",
+		e:     "2dfbc1b372d89a1188c09c52e0eec61fce52032ab1022e8e67ece6672b043ee5",
+		k:     "77105c9b20bcd3122823c8cf6fcc7b956de33814e95b7fe64fed924594dceab3",
+		r:     "41aa28d2f1ab148280cd9ed56feda41974053554a42767b83ad043fd39dc0493",
+		s:     "01456c64ba4642a1653c235a98a60249bcd6d3f746b631df928014f6c5bf9c40",
+	},
+	{
+		curve: curve.GOST34102012512Test,
+		d:     "0ba6048aadae241ba40936d47756d7c93091a0e8514669700ee7508e508b102072e8123b2200a0563322dad2827e2714a2636b7bfd18aadfc62967821fa18dd4",
+		qx:    "115dc5bc96760c7b48598d8ab9e740d4c4a85a65be33c1815b5c320c854621dd5a515856d13314af69bc5b924c8b4ddff75c45415c1d9dd9dd33612cd530efe1",
+		qy:    "37c7c90cd40b0f5621dc3ac1b751cfa0e2634fa0503b3d52639f5d7fb72afd61ea199441d943ffe7f0c70a2759a3cdb84c114e1f9339fdf27f35eca93677beec",
+		e:     "3754f3cfacc9e0615c4f4a7c4d8dab531b09b6f9c170c533a71d147035b0c5917184ee536593f4414339976c647c5d5a407adedb1d560c4fc6777d2972075b8c",
+		k:     "0359e7f4b1410feacc570456c6801496946312120b39d019d455986e364f365886748ed7a44b3e794434006011842286212273a6d14cf70ea3af71bb1ae679f1",
+		r:     "2f86fa60a081091a23dd795e1e3c689ee512a3c82ee0dcc2643c78eea8fcacd35492558486b20f1c9ec197c90699850260c93bcbcd9c5c3317e19344e173ae36",
+		s:     "1081b394696ffe8e6585e7a9362d26b6325f56778aadbc081c0bfbe933d52ff5823ce288e8c4f362526080df7f70ce406a6eeb1f56919cb92a9853bde73e5b4a",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		context := gost3410.NewContext(v.curve, hash.GOST34112012256)
+
+		publicKey, err := aggsig.NewPublicKey(context, unhex(v.d))
+		assert.NoError(t, err)
+		assert.Equal(t, v.qx, hex.EncodeToString(publicKey.X.Bytes()))
+		assert.Equal(t, v.qy, hex.EncodeToString(publicKey.Y.Bytes()))
+
+		signature, err := Sign(context, unhex(v.d), unhex(v.e), bytes.NewReader(unhex(v.k)), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, v.s+v.r, hex.EncodeToString(signature))
+
+		correct, err := Verify(context, publicKey, unhex(v.e), signature, nil)
+		assert.NoError(t, err)
+		assert.True(t, correct)
+
+		// r || s with a little-endian digest
+		opts := &Options{Order: OrderRS, LittleEndianDigest: true}
+		signature, err = Sign(context, unhex(v.d), reverse(unhex(v.e)), bytes.NewReader(unhex(v.k)), opts)
+		assert.NoError(t, err)
+		assert.Equal(t, v.r+v.s, hex.EncodeToString(signature))
+
+		correct, err = Verify(context, publicKey, reverse(unhex(v.e)), signature, opts)
+		assert.NoError(t, err)
+		assert.True(t, correct)
+
+		correct, err = Verify(context, publicKey, unhex(v.e), signature, opts)
+		assert.NoError(t, err)
+		assert.False(t, correct)
+	}
+}
+
+func TestGogostInterop(t *testing.T) {
+	for _, c := range []*curve.CurveParams{curve.GOST34102012256A, curve.GOST34102012512A, curve.GOST34102012512C} {
+		context := gost3410.NewContext(c, hash.GOST34112012512)
+		mode := c.BitSize / 8
+
+		ref, err := gogost.NewCurve(
+			utils.Pad(c.P.Bytes(), mode),
+			utils.Pad(c.N.Bytes(), mode),
+			utils.Pad(c.A.Bytes(), mode),
+			utils.Pad(c.B.Bytes(), mode),
+			utils.Pad(c.Gx.Bytes(), mode),
+			utils.Pad(c.Gy.Bytes(), mode),
+		)
+		assert.NoError(t, err)
+
+		// gogost expects the private key in little-endian form
+		rawPrivateKey := utils.RandomBytes(mode)
+		refPrivateKey, err := gogost.NewPrivateKey(ref, gogost.Mode(mode), reverse(rawPrivateKey))
+		assert.NoError(t, err)
+		refPublicKey, err := refPrivateKey.PublicKey()
+		assert.NoError(t, err)
+		publicKey, err := aggsig.NewPublicKey(context, rawPrivateKey)
+		assert.NoError(t, err)
+
+		h := context.HashAlgorithm.New()
+		h.Write([]byte("Hello world!"))
+		digest := h.Sum(nil)
+
+		signature, err := Sign(context, rawPrivateKey, digest, rand.Reader, nil)
+		assert.NoError(t, err)
+		correct, err := refPublicKey.VerifyDigest(digest, signature)
+		assert.NoError(t, err)
+		assert.True(t, correct, "gogost must accept our signature on %s", c.Name)
+
+		refSignature, err := refPrivateKey.SignDigest(digest, rand.Reader)
+		assert.NoError(t, err)
+		correct, err = Verify(context, publicKey, digest, refSignature, nil)
+		assert.NoError(t, err)
+		assert.True(t, correct, "gogost signature must verify on %s", c.Name)
+	}
+}
+
+func TestSignZeroPrivateKey(t *testing.T) {
+	c := curve.GOST34102012256A
+	context := gost3410.NewContext(c, hash.GOST34112012256)
+	mode := c.BitSize / 8
+
+	// d = q is not zero as bytes, but zero mod q
+	_, err := Sign(context, utils.Pad(c.N.Bytes(), mode), make([]byte, 32), rand.Reader, nil)
+	assert.Error(t, err)
+}
+
+func TestSignDeterministic(t *testing.T) {
+	for _, c := range []*curve.CurveParams{curve.GOST34102012256A, curve.GOST34102012512A} {
+		context := gost3410.NewContext(c, hash.GOST34112012512)
+		mode := c.BitSize / 8
+		privateKey := utils.RandomBytes(mode)
+		publicKey, err := aggsig.NewPublicKey(context, privateKey)
+		assert.NoError(t, err)
+
+		h := context.HashAlgorithm.New()
+		h.Write([]byte("Hello world!"))
+		digest := h.Sum(nil)
+
+		for _, opts := range []*Options{nil, {LittleEndianDigest: true}} {
+			signature, err := Sign(context, privateKey, digest, nil, opts)
+			assert.NoError(t, err)
+			again, err := Sign(context, privateKey, digest, nil, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, signature, again)
+
+			correct, err := Verify(context, publicKey, digest, signature, opts)
+			assert.NoError(t, err)
+			assert.True(t, correct, c.Name)
+		}
+
+		// the nonce is the one of aggsig.DeterministicNonce for the digest
+		k, err := aggsig.DeterministicNonce(context, privateKey, digest, nil)
+		assert.NoError(t, err)
+		signature, err := Sign(context, privateKey, digest, nil, nil)
+		assert.NoError(t, err)
+		expected, err := Sign(context, privateKey, digest, bytes.NewReader(k), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, signature)
+	}
+}
+
+func TestSignUniformNonce(t *testing.T) {
+	// 2^256/q is about 1.6 on CryptoPro-C, so reducing mod q would bias k
+	c := curve.GOST34102001CryptoProC
+	context := gost3410.NewContext(c, hash.GOST34112012256)
+	mode := c.BitSize / 8
+	privateKey := utils.RandomBytes(mode)
+	digest := utils.RandomBytes(32)
+
+	// q + 5 is drawn again instead of being taken as 5
+	k := utils.Pad(big.NewInt(7).Bytes(), mode)
+	rejected := utils.Pad(new(big.Int).Add(c.N, big.NewInt(5)).Bytes(), mode)
+	signature, err := Sign(context, privateKey, digest, bytes.NewReader(append(rejected, k...)), nil)
+	assert.NoError(t, err)
+	expected, err := Sign(context, privateKey, digest, bytes.NewReader(k), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, signature)
+	reduced, err := Sign(context, privateKey, digest, bytes.NewReader(utils.Pad(big.NewInt(5).Bytes(), mode)), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, reduced, signature)
+}
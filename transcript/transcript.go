@@ -0,0 +1,108 @@
+/*
+Package transcript implements a Merlin-style Fiat-Shamir transcript over the
+hash algorithm of a gost3410.Context. The prover and the verifier append the
+same labeled messages in the same order, and every challenge they draw depends
+on the protocol label and on everything appended before it, including the
+earlier challenges.
+*/
+package transcript
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/utils"
+)
+
+// ChallengeDST is the domain separation tag under which challenges are hashed
+// to scalars.
+const ChallengeDST = "GOST3410-V01-TRANSCRIPT-CHALLENGE"
+
+/*
+Transcript is the running state of a Fiat-Shamir transcript: a digest that
+absorbs every message and every challenge. The zero value is not usable, use
+New. A Transcript is not safe for concurrent use.
+*/
+type Transcript struct {
+	context *gost3410.Context
+	state   []byte
+}
+
+/*
+New starts a transcript for the protocol named by label. Transcripts with
+different labels never produce the same challenges.
+*/
+func New(context *gost3410.Context, label string) *Transcript {
+	t := &Transcript{context: context}
+	t.AppendMessage("dom-sep", []byte(label))
+	return t
+}
+
+/*
+AppendMessage absorbs message under label:
+
+	state = H(state || len(label) || label || len(message) || message)
+
+with the lengths as 8 bytes big-endian, so that no two different sequences of
+messages lead to the same state.
+*/
+func (t *Transcript) AppendMessage(label string, message []byte) {
+	h := t.context.HashAlgorithm.New()
+	h.Write(t.state)
+	for _, part := range [][]byte{[]byte(label), message} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(part)))
+		h.Write(length[:])
+		h.Write(part)
+	}
+	t.state = h.Sum(nil)
+}
+
+// AppendPoint absorbs the encoding of p, the point at infinity being zeros.
+func (t *Transcript) AppendPoint(label string, p *curve.Point) {
+	t.AppendMessage(label, p.Bytes(t.context.Curve))
+}
+
+// AppendScalar absorbs k mod N as a big-endian integer of the byte length of N.
+func (t *Transcript) AppendScalar(label string, k *big.Int) {
+	N := t.context.Curve.Params().N
+	t.AppendMessage(label, utils.Pad(new(big.Int).Mod(k, N).Bytes(), (N.BitLen()+7)/8))
+}
+
+// AppendUint64 absorbs v as 8 bytes big-endian.
+func (t *Transcript) AppendUint64(label string, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	t.AppendMessage(label, b[:])
+}
+
+/*
+ChallengeScalar derives a challenge of [1, N) from the state with
+hash.HashToScalar and absorbs it under label, so that the next challenges
+depend on it. Zero, which would break most protocols, is skipped by drawing
+again.
+*/
+func (t *Transcript) ChallengeScalar(label string) *big.Int {
+	for {
+		// the tag is not empty and the output length is fixed, so this cannot fail
+		e, _ := hash.HashToScalar(t.context, []byte(ChallengeDST), t.state, []byte(label))
+		t.AppendScalar(label, e)
+		if e.Sign() != 0 {
+			return e
+		}
+	}
+}
+
+/*
+Fork returns a copy of the transcript that continues with label, t itself is
+left as it is. It lets sub-protocols that share the history of t run
+independently.
+*/
+func (t *Transcript) Fork(label string) *Transcript {
+	f := &Transcript{context: t.context, state: append([]byte{}, t.state...)}
+	f.AppendMessage("fork", []byte(label))
+	return f
+}
@@ -0,0 +1,74 @@
+package transcript
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscript(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102012256A, hash.GOST34112012256)
+	N := context.Curve.Params().N
+	p := new(curve.Point).ScalarBaseMult(context.Curve, big.NewInt(7))
+
+	build := func(label string) *Transcript {
+		tr := New(context, label)
+		tr.AppendUint64("n", 8)
+		tr.AppendPoint("P", p)
+		tr.AppendScalar("k", big.NewInt(5))
+		return tr
+	}
+
+	// the prover and the verifier agree
+	e1 := build("test").ChallengeScalar("e")
+	e2 := build("test").ChallengeScalar("e")
+	assert.Equal(t, e1, e2)
+	assert.True(t, e1.Sign() > 0 && e1.Cmp(N) < 0)
+
+	// the protocol label, the challenge label and the messages matter
+	assert.NotEqual(t, e1, build("other").ChallengeScalar("e"))
+	assert.NotEqual(t, e1, build("test").ChallengeScalar("f"))
+	tr := build("test")
+	tr.AppendMessage("m", nil)
+	assert.NotEqual(t, e1, tr.ChallengeScalar("e"))
+
+	// scalars are reduced mod N
+	a, b := New(context, "test"), New(context, "test")
+	a.AppendScalar("k", big.NewInt(5))
+	b.AppendScalar("k", new(big.Int).Add(N, big.NewInt(5)))
+	assert.Equal(t, a.ChallengeScalar("e"), b.ChallengeScalar("e"))
+
+	// the messages are framed
+	a, b = New(context, "test"), New(context, "test")
+	a.AppendMessage("ab", []byte("c"))
+	b.AppendMessage("a", []byte("bc"))
+	assert.NotEqual(t, a.ChallengeScalar("e"), b.ChallengeScalar("e"))
+
+	// every challenge depends on the previous ones
+	tr = build("test")
+	first := tr.ChallengeScalar("e")
+	assert.NotEqual(t, first, tr.ChallengeScalar("e"))
+}
+
+func TestFork(t *testing.T) {
+	context := gost3410.NewContext(curve.GOST34102001, hash.GOST34112012512)
+
+	parent := New(context, "test")
+	parent.AppendMessage("m", []byte("message"))
+	reference := New(context, "test")
+	reference.AppendMessage("m", []byte("message"))
+
+	left, right := parent.Fork("left"), parent.Fork("right")
+	left.AppendMessage("m", []byte("left"))
+
+	// forks diverge from each other and leave the parent alone
+	assert.NotEqual(t, left.ChallengeScalar("e"), right.ChallengeScalar("e"))
+	assert.Equal(t, reference.ChallengeScalar("e"), parent.ChallengeScalar("e"))
+
+	// forking is deterministic
+	assert.Equal(t, parent.Fork("left").ChallengeScalar("e"), parent.Fork("left").ChallengeScalar("e"))
+}
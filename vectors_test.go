@@ -0,0 +1,292 @@
+package gost3410_test
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/AllFi/go-gost3410"
+	"github.com/AllFi/go-gost3410/aggsig"
+	"github.com/AllFi/go-gost3410/curve"
+	"github.com/AllFi/go-gost3410/hash"
+	"github.com/AllFi/go-gost3410/sign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+This file runs the known-answer vectors from testdata against every package, so
+that a regression in byte ordering or in curve constants fails loudly.
+*/
+
+// hexBytes is a byte slice that is encoded as a hex string in JSON.
+type hexBytes []byte
+
+func (b *hexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	raw, err := hex.DecodeString(s)
+	*b = raw
+	return err
+}
+
+type hashVectors struct {
+	Digests []struct {
+		Algorithm string
+		Message   hexBytes
+		Digest    hexBytes
+	}
+	Macs []struct {
+		Algorithm string
+		Key       hexBytes
+		Data      hexBytes
+		Mac       hexBytes
+	}
+	Kdfs []struct {
+		Algorithm string
+		Key       hexBytes
+		Label     hexBytes
+		Seed      hexBytes
+		Output    hexBytes
+	}
+}
+
+type signatureVectors struct {
+	Signatures []struct {
+		Curve      string
+		PrivateKey hexBytes
+		PublicKeyX hexBytes
+		PublicKeyY hexBytes
+		Digest     hexBytes
+		Nonce      hexBytes
+		Signature  hexBytes
+	}
+	Messages []struct {
+		Curve      string
+		Hash       string
+		PrivateKey hexBytes
+		Message    hexBytes
+		Nonce      hexBytes
+		Signature  hexBytes
+	}
+	Points []struct {
+		Curve  string
+		Scalar hexBytes
+		X      hexBytes
+		Y      hexBytes
+	}
+}
+
+type hashToCurveVectors struct {
+	Expands []struct {
+		Hash         string
+		Dst          string
+		Msg          string
+		Length       int
+		UniformBytes hexBytes
+	}
+	Points []struct {
+		Curve string
+		Hash  string
+		Dst   string
+		Msg   hexBytes
+		U0    hexBytes
+		U1    hexBytes
+		X     hexBytes
+		Y     hexBytes
+	}
+}
+
+var hashAlgorithms = map[string]gost3410.HashAlgorithm{
+	"GOST34112012256": hash.GOST34112012256,
+	"GOST34112012512": hash.GOST34112012512,
+	"SHA256":          hash.SHA256,
+}
+
+var macAlgorithms = map[string]gost3410.MACAlgorithm{
+	"HMACGOST34112012256": hash.HMACGOST34112012256,
+	"HMACGOST34112012512": hash.HMACGOST34112012512,
+}
+
+func loadVectors(t *testing.T, name string, v interface{}) {
+	raw, err := ioutil.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, v))
+}
+
+func loadCurve(t *testing.T, name string) *curve.CurveParams {
+	c, err := curve.ByName(name)
+	require.NoError(t, err)
+	return c
+}
+
+func TestHashToCurveVectors(t *testing.T) {
+	var vectors hashToCurveVectors
+	loadVectors(t, "hash_to_curve.json", &vectors)
+	require.NotEmpty(t, vectors.Expands)
+	require.NotEmpty(t, vectors.Points)
+
+	for _, v := range vectors.Expands {
+		uniform, err := hash.ExpandMessageXMD(hashAlgorithms[v.Hash], []byte(v.Msg), []byte(v.Dst), v.Length)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(v.UniformBytes), hex.EncodeToString(uniform), v.Msg)
+	}
+
+	for _, v := range vectors.Points {
+		var c elliptic.Curve = elliptic.P256()
+		if v.Curve != c.Params().Name {
+			c = loadCurve(t, v.Curve)
+		}
+		ha := hashAlgorithms[v.Hash]
+
+		u, err := curve.HashToField(c, ha, v.Msg, []byte(v.Dst), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, new(big.Int).SetBytes(v.U0), u[0], v.Curve)
+		assert.Equal(t, new(big.Int).SetBytes(v.U1), u[1], v.Curve)
+
+		p, err := curve.HashToCurve(c, ha, v.Msg, []byte(v.Dst))
+		assert.NoError(t, err)
+		assert.Equal(t, new(big.Int).SetBytes(v.X), p.X, v.Curve)
+		assert.Equal(t, new(big.Int).SetBytes(v.Y), p.Y, v.Curve)
+
+		if v.Dst == curve.GeneratorDST {
+			h := curve.GeneratorH(gost3410.NewContext(c, ha))
+			assert.Equal(t, new(big.Int).SetBytes(v.X), h.X, v.Curve)
+			assert.Equal(t, new(big.Int).SetBytes(v.Y), h.Y, v.Curve)
+		}
+	}
+}
+
+func TestHashVectors(t *testing.T) {
+	var vectors hashVectors
+	loadVectors(t, "hash.json", &vectors)
+	require.NotEmpty(t, vectors.Digests)
+
+	for _, v := range vectors.Digests {
+		h := hashAlgorithms[v.Algorithm].New()
+		h.Write(v.Message)
+		assert.Equal(t, hex.EncodeToString(v.Digest), hex.EncodeToString(h.Sum(nil)), v.Algorithm)
+	}
+
+	for _, v := range vectors.Macs {
+		mac := hash.MAC(macAlgorithms[v.Algorithm], v.Key, v.Data)
+		assert.Equal(t, hex.EncodeToString(v.Mac), hex.EncodeToString(mac), v.Algorithm)
+	}
+
+	for _, v := range vectors.Kdfs {
+		var output []byte
+		switch v.Algorithm {
+		case "KDFGOST34112012256":
+			output = hash.KDFGOST34112012256(v.Key, v.Label, v.Seed)
+		case "HKDFSHA256":
+			var err error
+			output, err = hash.HKDF(hash.SHA256, v.Key, v.Seed, v.Label, len(v.Output))
+			assert.NoError(t, err)
+		default:
+			t.Fatalf("unknown KDF %s", v.Algorithm)
+		}
+		assert.Equal(t, hex.EncodeToString(v.Output), hex.EncodeToString(output), v.Algorithm)
+	}
+}
+
+func TestScalarMultVectors(t *testing.T) {
+	var vectors signatureVectors
+	loadVectors(t, "gost34102012.json", &vectors)
+	require.NotEmpty(t, vectors.Points)
+
+	for _, v := range vectors.Points {
+		c := loadCurve(t, v.Curve)
+		k := new(big.Int).SetBytes(v.Scalar)
+
+		p := new(curve.Point).ScalarBaseMult(c, k)
+		assert.Equal(t, new(big.Int).SetBytes(v.X), p.X, v.Curve)
+		assert.Equal(t, new(big.Int).SetBytes(v.Y), p.Y, v.Curve)
+
+		g := &curve.Point{X: c.Gx, Y: c.Gy}
+		p = new(curve.Point).ScalarMult(c, g, k)
+		assert.Equal(t, new(big.Int).SetBytes(v.X), p.X, v.Curve)
+		assert.Equal(t, new(big.Int).SetBytes(v.Y), p.Y, v.Curve)
+	}
+
+	for _, v := range vectors.Signatures {
+		c := loadCurve(t, v.Curve)
+		context := gost3410.NewContext(c, hash.GOST34112012256)
+
+		publicKey, err := aggsig.NewPublicKey(context, v.PrivateKey)
+		assert.NoError(t, err)
+		assert.Equal(t, new(big.Int).SetBytes(v.PublicKeyX), publicKey.X, v.Curve)
+		assert.Equal(t, new(big.Int).SetBytes(v.PublicKeyY), publicKey.Y, v.Curve)
+	}
+}
+
+func TestSignatureVectors(t *testing.T) {
+	var vectors signatureVectors
+	loadVectors(t, "gost34102012.json", &vectors)
+	require.NotEmpty(t, vectors.Signatures)
+
+	for _, v := range vectors.Signatures {
+		c := loadCurve(t, v.Curve)
+		context := gost3410.NewContext(c, hash.GOST34112012256)
+		publicKey := &aggsig.PublicKey{Point: &curve.Point{
+			X: new(big.Int).SetBytes(v.PublicKeyX),
+			Y: new(big.Int).SetBytes(v.PublicKeyY),
+		}}
+
+		correct, err := aggsig.VerifyDigest(context, v.Signature, publicKey, v.Digest)
+		assert.NoError(t, err)
+		assert.True(t, correct, v.Curve)
+
+		correct, err = sign.Verify(context, publicKey, v.Digest, v.Signature, nil)
+		assert.NoError(t, err)
+		assert.True(t, correct, v.Curve)
+
+		signature, err := sign.Sign(context, v.PrivateKey, v.Digest, bytes.NewReader(v.Nonce), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(v.Signature), hex.EncodeToString(signature), v.Curve)
+
+		// a single flipped bit must be rejected
+		for _, i := range []int{0, len(v.Signature) - 1} {
+			tampered := append([]byte{}, v.Signature...)
+			tampered[i] ^= 0x01
+			correct, err = aggsig.VerifyDigest(context, tampered, publicKey, v.Digest)
+			assert.NoError(t, err)
+			assert.False(t, correct, v.Curve)
+		}
+	}
+}
+
+func TestAggsigVectors(t *testing.T) {
+	var vectors signatureVectors
+	loadVectors(t, "gost34102012.json", &vectors)
+	require.NotEmpty(t, vectors.Messages)
+
+	for _, v := range vectors.Messages {
+		c := loadCurve(t, v.Curve)
+		context := gost3410.NewContext(c, hashAlgorithms[v.Hash])
+
+		publicKey, err := aggsig.NewPublicKey(context, v.PrivateKey)
+		assert.NoError(t, err)
+		publicNonce, err := aggsig.NewPublicKey(context, v.Nonce)
+		assert.NoError(t, err)
+
+		partialSignature, err := aggsig.SignPartial(context, v.PrivateKey, v.Nonce, publicNonce, v.Message)
+		assert.NoError(t, err)
+		signature, err := aggsig.AggregatePartialSignatures(context, [][]byte{partialSignature}, publicNonce)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(v.Signature), hex.EncodeToString(signature), v.Curve)
+
+		correct, err := aggsig.Verify(context, v.Signature, publicKey, v.Message)
+		assert.NoError(t, err)
+		assert.True(t, correct, v.Curve)
+
+		correct, err = aggsig.Verify(context, v.Signature, publicKey, append(v.Message, 0))
+		assert.NoError(t, err)
+		assert.False(t, correct, v.Curve)
+	}
+}